@@ -0,0 +1,198 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bgp provides gNOI BGP operations.
+package bgp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"sort"
+	"sync"
+
+	bpb "github.com/openconfig/gnoi/bgp"
+	"github.com/openconfig/gnoigo/internal"
+	"google.golang.org/protobuf/proto"
+)
+
+// defaultConcurrency is the number of neighbors cleared at the same time,
+// unless specified otherwise.
+const defaultConcurrency = 10
+
+func validateAddress(address string) error {
+	_, err := parseAddress(address)
+	return err
+}
+
+func parseAddress(address string) (netip.Addr, error) {
+	addr, err := netip.ParseAddr(address)
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("invalid BGP neighbor address %q: %w", address, err)
+	}
+	return addr, nil
+}
+
+// modeRisk returns the risk class of clearing a session in the given mode,
+// which is only disruptive if the session is torn down.
+func modeRisk(mode bpb.ClearBGPNeighborRequest_Mode) internal.Risk {
+	switch mode {
+	case bpb.ClearBGPNeighborRequest_SOFT, bpb.ClearBGPNeighborRequest_SOFTIN:
+		return internal.RiskLow
+	default:
+		return internal.RiskDisruptive
+	}
+}
+
+// ClearNeighborOperation represents the parameters of a ClearBGPNeighbor operation.
+type ClearNeighborOperation struct {
+	req *bpb.ClearBGPNeighborRequest
+}
+
+// NewClearNeighborOperation creates an empty ClearNeighborOperation.
+func NewClearNeighborOperation() *ClearNeighborOperation {
+	return &ClearNeighborOperation{req: &bpb.ClearBGPNeighborRequest{}}
+}
+
+// Address specifies the IPv4 or IPv6 address of the neighbor to clear.
+func (c *ClearNeighborOperation) Address(address string) *ClearNeighborOperation {
+	c.req.Address = address
+	return c
+}
+
+// RoutingInstance specifies the routing instance of the neighbor.
+// If unset, the global routing table is used.
+func (c *ClearNeighborOperation) RoutingInstance(instance string) *ClearNeighborOperation {
+	c.req.RoutingInstance = instance
+	return c
+}
+
+// Mode specifies how to clear the session. The default is SOFT.
+func (c *ClearNeighborOperation) Mode(mode bpb.ClearBGPNeighborRequest_Mode) *ClearNeighborOperation {
+	c.req.Mode = mode
+	return c
+}
+
+// Execute performs the ClearBGPNeighbor operation. It returns an error
+// without calling the target if the address is malformed.
+func (c *ClearNeighborOperation) Execute(ctx context.Context, cl *internal.Clients) (*bpb.ClearBGPNeighborResponse, error) {
+	if err := validateAddress(c.req.GetAddress()); err != nil {
+		return nil, err
+	}
+	return cl.BGP().ClearBGPNeighbor(ctx, c.req)
+}
+
+// Request returns the request proto of the ClearNeighbor operation.
+func (c *ClearNeighborOperation) Request() proto.Message {
+	return c.req
+}
+
+// Risk returns the risk class of the ClearNeighbor operation, which is only
+// disruptive if the session is reset.
+func (c *ClearNeighborOperation) Risk() internal.Risk {
+	return modeRisk(c.req.GetMode())
+}
+
+// ClearNeighborsOperation represents the parameters of concurrent
+// ClearBGPNeighbor operations on a list of neighbors.
+type ClearNeighborsOperation struct {
+	addresses       []string
+	routingInstance string
+	mode            bpb.ClearBGPNeighborRequest_Mode
+	concurrency     int
+}
+
+// NewClearNeighborsOperation creates an empty ClearNeighborsOperation.
+func NewClearNeighborsOperation() *ClearNeighborsOperation {
+	return &ClearNeighborsOperation{concurrency: defaultConcurrency}
+}
+
+// Addresses adds the addresses of neighbors to clear.
+func (c *ClearNeighborsOperation) Addresses(addresses ...string) *ClearNeighborsOperation {
+	c.addresses = append(c.addresses, addresses...)
+	return c
+}
+
+// RoutingInstance specifies the routing instance of the neighbors.
+// If unset, the global routing table is used.
+func (c *ClearNeighborsOperation) RoutingInstance(instance string) *ClearNeighborsOperation {
+	c.routingInstance = instance
+	return c
+}
+
+// Mode specifies how to clear the sessions. The default is SOFT.
+func (c *ClearNeighborsOperation) Mode(mode bpb.ClearBGPNeighborRequest_Mode) *ClearNeighborsOperation {
+	c.mode = mode
+	return c
+}
+
+// Concurrency specifies the maximum number of neighbors cleared at the same
+// time.
+func (c *ClearNeighborsOperation) Concurrency(n int) *ClearNeighborsOperation {
+	c.concurrency = n
+	return c
+}
+
+// Execute clears the neighbors concurrently and returns the error of each
+// neighbor address, nil for the neighbors cleared successfully. Addresses of
+// the same neighbor are cleared once, under the first of them. The returned
+// error joins the errors of the neighbors that failed. If any address is
+// malformed, no neighbor is cleared.
+func (c *ClearNeighborsOperation) Execute(ctx context.Context, cl *internal.Clients) (map[string]error, error) {
+	var errs []error
+	var addresses []string
+	seen := map[netip.Addr]bool{}
+	for _, a := range c.addresses {
+		addr, err := parseAddress(a)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !seen[addr] {
+			seen[addr] = true
+			addresses = append(addresses, a)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	results := make(map[string]error, len(addresses))
+	var mu sync.Mutex
+	notCleared := internal.ForEach(ctx, addresses, c.concurrency, func(a string) {
+		_, err := NewClearNeighborOperation().Address(a).RoutingInstance(c.routingInstance).Mode(c.mode).Execute(ctx, cl)
+		mu.Lock()
+		defer mu.Unlock()
+		results[a] = err
+	})
+	for _, a := range notCleared {
+		results[a] = ctx.Err()
+	}
+
+	sort.Strings(addresses)
+	errs = nil
+	for _, a := range addresses {
+		if err := results[a]; err != nil {
+			errs = append(errs, fmt.Errorf("neighbor %s: %w", a, err))
+		}
+	}
+	return results, errors.Join(errs...)
+}
+
+// Risk returns the risk class of the ClearNeighbors operation, which is only
+// disruptive if the sessions are reset.
+func (c *ClearNeighborsOperation) Risk() internal.Risk {
+	return modeRisk(c.mode)
+}
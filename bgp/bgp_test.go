@@ -0,0 +1,170 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bgp_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	bpb "github.com/openconfig/gnoi/bgp"
+	"github.com/openconfig/gnoigo/bgp"
+	"github.com/openconfig/gnoigo/internal"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+type fakeBGPClient struct {
+	bpb.BGPClient
+	mu          sync.Mutex
+	gotReqs     []*bpb.ClearBGPNeighborRequest
+	fail        map[string]bool
+	delay       time.Duration
+	inFlight    int
+	maxInFlight int
+}
+
+func (fc *fakeBGPClient) ClearBGPNeighbor(_ context.Context, in *bpb.ClearBGPNeighborRequest, _ ...grpc.CallOption) (*bpb.ClearBGPNeighborResponse, error) {
+	fc.mu.Lock()
+	fc.gotReqs = append(fc.gotReqs, in)
+	fc.inFlight++
+	fc.maxInFlight = max(fc.maxInFlight, fc.inFlight)
+	fc.mu.Unlock()
+	time.Sleep(fc.delay)
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	fc.inFlight--
+	if fc.fail[in.GetAddress()] {
+		return nil, status.Error(codes.FailedPrecondition, "graceful restart not enabled")
+	}
+	return &bpb.ClearBGPNeighborResponse{}, nil
+}
+
+func TestClearNeighbor(t *testing.T) {
+	tests := []struct {
+		desc    string
+		op      *bgp.ClearNeighborOperation
+		wantReq *bpb.ClearBGPNeighborRequest
+		wantErr string
+	}{
+		{
+			desc:    "IPv4 neighbor",
+			op:      bgp.NewClearNeighborOperation().Address("192.0.2.1").RoutingInstance("VRF-A").Mode(bpb.ClearBGPNeighborRequest_SOFTIN),
+			wantReq: &bpb.ClearBGPNeighborRequest{Address: "192.0.2.1", RoutingInstance: "VRF-A", Mode: bpb.ClearBGPNeighborRequest_SOFTIN},
+		},
+		{
+			desc:    "IPv6 neighbor",
+			op:      bgp.NewClearNeighborOperation().Address("2001:db8::1").Mode(bpb.ClearBGPNeighborRequest_HARD_RESET),
+			wantReq: &bpb.ClearBGPNeighborRequest{Address: "2001:db8::1", Mode: bpb.ClearBGPNeighborRequest_HARD_RESET},
+		},
+		{
+			desc:    "malformed address",
+			op:      bgp.NewClearNeighborOperation().Address("192.0.2.256"),
+			wantErr: "invalid BGP neighbor address",
+		},
+		{
+			desc:    "missing address",
+			op:      bgp.NewClearNeighborOperation(),
+			wantErr: "invalid BGP neighbor address",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			fake := &fakeBGPClient{}
+			_, err := tt.op.Execute(context.Background(), &internal.Clients{BGPClient: fake})
+			if (err == nil) != (tt.wantErr == "") || (err != nil && !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("Execute() got error %v, want %q", err, tt.wantErr)
+			}
+			var wantReqs []*bpb.ClearBGPNeighborRequest
+			if tt.wantReq != nil {
+				wantReqs = append(wantReqs, tt.wantReq)
+			}
+			if diff := cmp.Diff(wantReqs, fake.gotReqs, protocmp.Transform()); diff != "" {
+				t.Errorf("Execute() sent unexpected requests diff (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestClearNeighbors(t *testing.T) {
+	fake := &fakeBGPClient{fail: map[string]bool{"192.0.2.2": true}}
+	got, err := bgp.NewClearNeighborsOperation().Addresses("192.0.2.1", "192.0.2.2", "2001:db8::1").Mode(bpb.ClearBGPNeighborRequest_SOFT).Execute(context.Background(), &internal.Clients{BGPClient: fake})
+	if err == nil || !strings.Contains(err.Error(), "neighbor 192.0.2.2") {
+		t.Errorf("Execute() got error %v, want error for neighbor 192.0.2.2", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("Execute() got %d results, want 3", len(got))
+	}
+	for addr, err := range got {
+		if gotFailed := err != nil; gotFailed != fake.fail[addr] {
+			t.Errorf("Execute() neighbor %s got error %v, want failure %v", addr, err, fake.fail[addr])
+		}
+	}
+	if status.Code(got["192.0.2.2"]) != codes.FailedPrecondition {
+		t.Errorf("Execute() neighbor 192.0.2.2 got error %v, want code %v", got["192.0.2.2"], codes.FailedPrecondition)
+	}
+	if len(fake.gotReqs) != 3 {
+		t.Errorf("Execute() sent %d requests, want 3", len(fake.gotReqs))
+	}
+}
+
+func TestClearNeighborsDuplicates(t *testing.T) {
+	fake := &fakeBGPClient{}
+	got, err := bgp.NewClearNeighborsOperation().Addresses("192.0.2.1", "2001:db8::1", "192.0.2.1", "2001:DB8::1").Execute(context.Background(), &internal.Clients{BGPClient: fake})
+	if err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+	want := map[string]error{"192.0.2.1": nil, "2001:db8::1": nil}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Execute() got unexpected results diff (-want +got): %s", diff)
+	}
+	if len(fake.gotReqs) != 2 {
+		t.Errorf("Execute() sent %d requests, want 2", len(fake.gotReqs))
+	}
+}
+
+func TestClearNeighborsConcurrency(t *testing.T) {
+	fake := &fakeBGPClient{delay: 10 * time.Millisecond}
+	op := bgp.NewClearNeighborsOperation().Concurrency(2)
+	for i := 1; i <= 6; i++ {
+		op.Addresses(fmt.Sprintf("192.0.2.%d", i))
+	}
+	if _, err := op.Execute(context.Background(), &internal.Clients{BGPClient: fake}); err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+	if len(fake.gotReqs) != 6 {
+		t.Errorf("Execute() sent %d requests, want 6", len(fake.gotReqs))
+	}
+	if fake.maxInFlight > 2 {
+		t.Errorf("Execute() cleared %d neighbors at the same time, want at most 2", fake.maxInFlight)
+	}
+}
+
+func TestClearNeighborsMalformed(t *testing.T) {
+	fake := &fakeBGPClient{}
+	_, err := bgp.NewClearNeighborsOperation().Addresses("192.0.2.1", "not-an-address").Execute(context.Background(), &internal.Clients{BGPClient: fake})
+	if err == nil || !strings.Contains(err.Error(), "not-an-address") {
+		t.Errorf("Execute() got error %v, want error for malformed address", err)
+	}
+	if len(fake.gotReqs) != 0 {
+		t.Errorf("Execute() sent %d requests, want none", len(fake.gotReqs))
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cert
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"sort"
+	"time"
+
+	cmpb "github.com/openconfig/gnoi/cert"
+	"github.com/openconfig/gnoigo/internal"
+)
+
+// CertificateReport describes a certificate installed on the target.
+type CertificateReport struct {
+	ID               string
+	Subject          string
+	SANs             []string
+	NotAfter         time.Time
+	ModificationTime time.Time
+	Endpoints        []*cmpb.Endpoint
+	Certificate      *x509.Certificate
+	// Err is the error parsing the certificate. If set, only ID,
+	// ModificationTime and Endpoints are set.
+	Err error
+}
+
+// ExpiresWithin reports whether the certificate expires within d of now.
+func (r *CertificateReport) ExpiresWithin(now time.Time, d time.Duration) bool {
+	return r.NotAfter.Before(now.Add(d))
+}
+
+// Expired reports whether the certificate has expired at now.
+func (r *CertificateReport) Expired(now time.Time) bool {
+	return r.NotAfter.Before(now)
+}
+
+// AuditOperation represents the parameters of a certificate expiry audit.
+// It gets the certificates on the target and reports them ordered by expiry.
+// Certificates that cannot be parsed are always reported, first, with Err set.
+type AuditOperation struct {
+	within time.Duration
+}
+
+// NewAuditOperation creates an AuditOperation that reports all certificates.
+func NewAuditOperation() *AuditOperation {
+	return &AuditOperation{}
+}
+
+// ExpiringWithin limits the report to certificates that expire within d,
+// including already expired certificates.
+func (a *AuditOperation) ExpiringWithin(d time.Duration) *AuditOperation {
+	a.within = d
+	return a
+}
+
+// Execute performs the Audit operation.
+func (a *AuditOperation) Execute(ctx context.Context, c *internal.Clients) ([]*CertificateReport, error) {
+	resp, err := NewGetCertificatesOperation().Execute(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	var reports []*CertificateReport
+	for _, info := range resp.GetCertificateInfo() {
+		r := newCertificateReport(info)
+		if r.Err == nil && a.within > 0 && !r.ExpiresWithin(now, a.within) {
+			continue
+		}
+		reports = append(reports, r)
+	}
+	sort.SliceStable(reports, func(i, j int) bool {
+		return reports[i].NotAfter.Before(reports[j].NotAfter)
+	})
+	return reports, nil
+}
+
+func newCertificateReport(info *cmpb.CertificateInfo) *CertificateReport {
+	r := &CertificateReport{
+		ID:        info.GetCertificateId(),
+		Endpoints: info.GetEndpoints(),
+	}
+	if mt := info.GetModificationTime(); mt != 0 {
+		r.ModificationTime = time.Unix(0, mt)
+	}
+	cert, err := ParseCertificate(info.GetCertificate())
+	if err != nil {
+		r.Err = fmt.Errorf("error parsing certificate %q: %w", info.GetCertificateId(), err)
+		return r
+	}
+	r.Subject = cert.Subject.String()
+	r.NotAfter = cert.NotAfter
+	r.Certificate = cert
+	r.SANs = append(r.SANs, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		r.SANs = append(r.SANs, ip.String())
+	}
+	r.SANs = append(r.SANs, cert.EmailAddresses...)
+	for _, u := range cert.URIs {
+		r.SANs = append(r.SANs, u.String())
+	}
+	return r
+}
@@ -0,0 +1,152 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cert_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	cmpb "github.com/openconfig/gnoi/cert"
+	"github.com/openconfig/gnoigo/cert"
+	"github.com/openconfig/gnoigo/internal"
+	"google.golang.org/grpc"
+)
+
+// newLeaf returns a certificate for cn that expires after d.
+func newLeaf(t *testing.T, cn string, d time.Duration) *x509.Certificate {
+	t.Helper()
+	ca, caKey := newCA(t)
+	key := newKey(t)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: cn},
+		DNSNames:     []string{cn + ".example.com"},
+		IPAddresses:  []net.IP{net.ParseIP("192.0.2.1")},
+		NotBefore:    time.Now().Add(-48 * time.Hour),
+		NotAfter:     time.Now().Add(d),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca, key.Public(), caKey)
+	if err != nil {
+		t.Fatalf("CreateCertificate() failed: %v", err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("ParseCertificate() failed: %v", err)
+	}
+	return leaf
+}
+
+func TestAudit(t *testing.T) {
+	const day = 24 * time.Hour
+	infos := []*cmpb.CertificateInfo{
+		{CertificateId: "later", Certificate: pemCert(newLeaf(t, "later", 90*day))},
+		{
+			CertificateId:    "soon",
+			Certificate:      pemCert(newLeaf(t, "soon", 10*day)),
+			Endpoints:        []*cmpb.Endpoint{{Type: cmpb.Endpoint_EP_DAEMON, Endpoint: "gnmi"}},
+			ModificationTime: 1e18,
+		},
+		{CertificateId: "expired", Certificate: pemCert(newLeaf(t, "expired", -day))},
+		{CertificateId: "bad", Certificate: &cmpb.Certificate{Certificate: []byte("bad")}},
+	}
+
+	tests := []struct {
+		desc    string
+		op      *cert.AuditOperation
+		infos   []*cmpb.CertificateInfo
+		wantIDs []string
+	}{
+		{
+			desc:    "all certificates ordered by expiry",
+			op:      cert.NewAuditOperation(),
+			infos:   infos,
+			wantIDs: []string{"bad", "expired", "soon", "later"},
+		},
+		{
+			desc:    "expiring within 30 days",
+			op:      cert.NewAuditOperation().ExpiringWithin(30 * day),
+			infos:   infos,
+			wantIDs: []string{"bad", "expired", "soon"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var fakeClient internal.Clients
+			fakeClient.CertMgmtClient = &fakeCertClient{GetCertificatesFn: func(context.Context, *cmpb.GetCertificatesRequest, ...grpc.CallOption) (*cmpb.GetCertificatesResponse, error) {
+				return &cmpb.GetCertificatesResponse{CertificateInfo: tt.infos}, nil
+			}}
+
+			got, err := tt.op.Execute(context.Background(), &fakeClient)
+			if err != nil {
+				t.Fatalf("Execute() failed: %v", err)
+			}
+			var gotIDs []string
+			for _, r := range got {
+				gotIDs = append(gotIDs, r.ID)
+				if wantErr := r.ID == "bad"; (r.Err != nil) != wantErr || (wantErr && !strings.Contains(r.Err.Error(), `"bad"`)) {
+					t.Errorf("Execute() got report %q with error %v, want error %v", r.ID, r.Err, wantErr)
+				}
+			}
+			if diff := cmp.Diff(tt.wantIDs, gotIDs); diff != "" {
+				t.Errorf("Execute() got unexpected certificates diff (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestCertificateReport(t *testing.T) {
+	var fakeClient internal.Clients
+	fakeClient.CertMgmtClient = &fakeCertClient{GetCertificatesFn: func(context.Context, *cmpb.GetCertificatesRequest, ...grpc.CallOption) (*cmpb.GetCertificatesResponse, error) {
+		return &cmpb.GetCertificatesResponse{CertificateInfo: []*cmpb.CertificateInfo{{
+			CertificateId:    "gnmi",
+			Certificate:      pemCert(newLeaf(t, "device", time.Hour)),
+			Endpoints:        []*cmpb.Endpoint{{Type: cmpb.Endpoint_EP_DAEMON, Endpoint: "gnmi"}},
+			ModificationTime: 1e18,
+		}}}, nil
+	}}
+
+	got, err := cert.NewAuditOperation().Execute(context.Background(), &fakeClient)
+	if err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("Execute() got %d reports, want 1", len(got))
+	}
+	r := got[0]
+	if r.Subject != "CN=device" {
+		t.Errorf("report subject got %q, want %q", r.Subject, "CN=device")
+	}
+	if diff := cmp.Diff([]string{"device.example.com", "192.0.2.1"}, r.SANs); diff != "" {
+		t.Errorf("report SANs diff (-want +got): %s", diff)
+	}
+	if !r.ModificationTime.Equal(time.Unix(0, 1e18)) {
+		t.Errorf("report modification time got %v, want %v", r.ModificationTime, time.Unix(0, 1e18))
+	}
+	if len(r.Endpoints) != 1 || r.Endpoints[0].GetEndpoint() != "gnmi" {
+		t.Errorf("report endpoints got %v, want gnmi", r.Endpoints)
+	}
+	now := time.Now()
+	if r.Expired(now) || !r.ExpiresWithin(now, 2*time.Hour) || r.ExpiresWithin(now, time.Minute) {
+		t.Errorf("report expiry checks inconsistent with NotAfter %v", r.NotAfter)
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cert
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+)
+
+const (
+	// defaultValidity is the default lifetime of certificates signed by a LocalCA.
+	defaultValidity = 365 * 24 * time.Hour
+	// clockSkew is subtracted from the start of the validity period to tolerate
+	// targets whose clock is behind.
+	clockSkew = 5 * time.Minute
+)
+
+// LocalCA is a Signer backed by an in-process CA, intended for tests and labs
+// that have no external PKI.
+type LocalCA struct {
+	cert     *x509.Certificate
+	key      crypto.Signer
+	validity time.Duration
+}
+
+// NewLocalCA creates a LocalCA from a PEM encoded CA certificate and private key.
+func NewLocalCA(certPEM, keyPEM []byte) (*LocalCA, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return nil, fmt.Errorf("CA certificate is not PEM encoded")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	if !cert.IsCA {
+		return nil, fmt.Errorf("certificate %q is not a CA certificate", cert.Subject)
+	}
+	key, err := parsePrivateKey(keyPEM)
+	if err != nil {
+		return nil, err
+	}
+	if pub, ok := key.Public().(interface{ Equal(crypto.PublicKey) bool }); !ok || !pub.Equal(cert.PublicKey) {
+		return nil, fmt.Errorf("private key does not match CA certificate %q", cert.Subject)
+	}
+	return &LocalCA{cert: cert, key: key, validity: defaultValidity}, nil
+}
+
+// LoadLocalCA creates a LocalCA from PEM files containing the CA certificate
+// and private key.
+func LoadLocalCA(certFile, keyFile string) (*LocalCA, error) {
+	certPEM, err := os.ReadFile(certFile)
+	if err != nil {
+		return nil, err
+	}
+	keyPEM, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	return NewLocalCA(certPEM, keyPEM)
+}
+
+// Validity specifies the lifetime of the signed certificates.
+func (ca *LocalCA) Validity(d time.Duration) *LocalCA {
+	ca.validity = d
+	return ca
+}
+
+// Certificate returns the CA certificate, e.g. to use as a trust bundle.
+func (ca *LocalCA) Certificate() *x509.Certificate {
+	return ca.cert
+}
+
+// Sign signs the CSR with the CA key. The returned chain contains the signed
+// certificate followed by the CA certificate.
+func (ca *LocalCA) Sign(_ context.Context, csr *x509.CertificateRequest) ([]*x509.Certificate, error) {
+	if err := csr.CheckSignature(); err != nil {
+		return nil, fmt.Errorf("invalid CSR signature: %w", err)
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	tmpl := &x509.Certificate{
+		SerialNumber:   serial,
+		Subject:        csr.Subject,
+		DNSNames:       csr.DNSNames,
+		IPAddresses:    csr.IPAddresses,
+		EmailAddresses: csr.EmailAddresses,
+		URIs:           csr.URIs,
+		NotBefore:      now.Add(-clockSkew),
+		NotAfter:       now.Add(ca.validity),
+		KeyUsage:       x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:    []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, csr.PublicKey, ca.key)
+	if err != nil {
+		return nil, err
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	return []*x509.Certificate{leaf, ca.cert}, nil
+}
+
+// parsePrivateKey parses a PEM encoded PKCS #8, PKCS #1 or SEC 1 private key.
+func parsePrivateKey(keyPEM []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return nil, fmt.Errorf("private key is not PEM encoded")
+	}
+	var key any
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+	return signer, nil
+}
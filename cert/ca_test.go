@@ -0,0 +1,173 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cert_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	cmpb "github.com/openconfig/gnoi/cert"
+	"github.com/openconfig/gnoigo/cert"
+	"github.com/openconfig/gnoigo/internal"
+	"google.golang.org/grpc"
+)
+
+// writeCA writes a new CA certificate and key as PEM files and returns their paths.
+func writeCA(t *testing.T) (string, string) {
+	t.Helper()
+	ca, key := newCA(t)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalPKCS8PrivateKey() failed: %v", err)
+	}
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "ca.pem"), filepath.Join(dir, "ca.key")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.Raw}), 0644); err != nil {
+		t.Fatalf("unable to write CA certificate: %v", err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		t.Fatalf("unable to write CA key: %v", err)
+	}
+	return certFile, keyFile
+}
+
+func TestNewLocalCA(t *testing.T) {
+	certFile, keyFile := writeCA(t)
+	certPEM, err := os.ReadFile(certFile)
+	if err != nil {
+		t.Fatalf("unable to read CA certificate: %v", err)
+	}
+	otherCert, otherKey := writeCA(t)
+	otherKeyPEM, err := os.ReadFile(otherKey)
+	if err != nil {
+		t.Fatalf("unable to read CA key: %v", err)
+	}
+
+	tests := []struct {
+		desc     string
+		certFile string
+		keyFile  string
+		wantErr  string
+	}{
+		{
+			desc:     "valid CA",
+			certFile: certFile,
+			keyFile:  keyFile,
+		},
+		{
+			desc:     "mismatched key",
+			certFile: otherCert,
+			keyFile:  keyFile,
+			wantErr:  "does not match",
+		},
+		{
+			desc:     "missing certificate file",
+			certFile: keyFile + ".missing",
+			keyFile:  keyFile,
+			wantErr:  "no such file",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			_, gotErr := cert.LoadLocalCA(tt.certFile, tt.keyFile)
+			if (gotErr == nil) != (tt.wantErr == "") || (gotErr != nil && !strings.Contains(gotErr.Error(), tt.wantErr)) {
+				t.Errorf("LoadLocalCA() got unexpected error %v want %s", gotErr, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := cert.NewLocalCA(otherKeyPEM, otherKeyPEM); err == nil {
+		t.Errorf("NewLocalCA() with a key as certificate got no error")
+	}
+	if _, err := cert.NewLocalCA(certPEM, []byte("garbage")); err == nil {
+		t.Errorf("NewLocalCA() with garbage key got no error")
+	}
+}
+
+func TestLocalCASign(t *testing.T) {
+	ca, err := cert.LoadLocalCA(writeCA(t))
+	if err != nil {
+		t.Fatalf("LoadLocalCA() failed: %v", err)
+	}
+	ca.Validity(24 * time.Hour)
+
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:     pkix.Name{CommonName: "device"},
+		DNSNames:    []string{"device.example.com"},
+		IPAddresses: []net.IP{net.ParseIP("192.0.2.1")},
+	}, newKey(t))
+	if err != nil {
+		t.Fatalf("CreateCertificateRequest() failed: %v", err)
+	}
+	csr, err := x509.ParseCertificateRequest(der)
+	if err != nil {
+		t.Fatalf("ParseCertificateRequest() failed: %v", err)
+	}
+
+	chain, err := ca.Sign(context.Background(), csr)
+	if err != nil {
+		t.Fatalf("Sign() failed: %v", err)
+	}
+	if len(chain) != 2 || !chain[1].Equal(ca.Certificate()) {
+		t.Fatalf("Sign() got chain of %d certificates, want leaf followed by the CA", len(chain))
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Certificate())
+	if _, err := chain[0].Verify(x509.VerifyOptions{Roots: roots, DNSName: "device.example.com"}); err != nil {
+		t.Errorf("signed certificate does not verify: %v", err)
+	}
+	if got, want := chain[0].NotAfter.Sub(chain[0].NotBefore), 24*time.Hour; got < want || got > want+10*time.Minute {
+		t.Errorf("signed certificate validity got %v, want about %v", got, want)
+	}
+}
+
+func TestRotateWithLocalCA(t *testing.T) {
+	ca, err := cert.LoadLocalCA(writeCA(t))
+	if err != nil {
+		t.Fatalf("LoadLocalCA() failed: %v", err)
+	}
+	target := newFakeTarget(t)
+	var fakeClient internal.Clients
+	fakeClient.CertMgmtClient = &fakeCertClient{RotateFn: func(context.Context, ...grpc.CallOption) (cmpb.CertificateManagement_RotateClient, error) {
+		return &fakeRotateClient{fakeTarget: target}, nil
+	}}
+
+	op := cert.NewRotateOperation().CertificateID("id").CSRParams(&cmpb.CSRParams{CommonName: "device"}).Signer(ca)
+	if _, err := op.Execute(context.Background(), &fakeClient); err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+	if !target.finalized {
+		t.Errorf("Execute() did not finalize the rotation")
+	}
+	leaf, err := cert.ParseCertificate(target.gotLoad.GetCertificate())
+	if err != nil {
+		t.Fatalf("ParseCertificate() failed: %v", err)
+	}
+	if err := leaf.CheckSignatureFrom(ca.Certificate()); err != nil {
+		t.Errorf("loaded certificate is not signed by the local CA: %v", err)
+	}
+	if leaf.Subject.CommonName != "device" {
+		t.Errorf("loaded certificate has common name %q, want %q", leaf.Subject.CommonName, "device")
+	}
+}
@@ -0,0 +1,610 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cert provides gNOI certificate management operations.
+package cert
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+
+	cmpb "github.com/openconfig/gnoi/cert"
+	"github.com/openconfig/gnoigo/internal"
+	"google.golang.org/protobuf/proto"
+)
+
+// Signer signs the CSRs generated by the target.
+type Signer interface {
+	// Sign signs the CSR and returns the certificate chain, starting with the
+	// signed leaf certificate followed by any intermediate and root CA
+	// certificates.
+	Sign(context.Context, *x509.CertificateRequest) ([]*x509.Certificate, error)
+}
+
+// SignFunc is an adapter to allow the use of an ordinary function as a Signer.
+type SignFunc func(context.Context, *x509.CertificateRequest) ([]*x509.Certificate, error)
+
+// Sign calls f(ctx, csr).
+func (f SignFunc) Sign(ctx context.Context, csr *x509.CertificateRequest) ([]*x509.Certificate, error) {
+	return f(ctx, csr)
+}
+
+// credentials holds the parameters shared by the Rotate and Install operations.
+type credentials struct {
+	certID  string
+	params  *cmpb.CSRParams
+	signer  Signer
+	cert    *x509.Certificate
+	key     crypto.Signer
+	caCerts []*x509.Certificate
+}
+
+// loadRequest returns the LoadCertificateRequest that installs the leaf
+// certificate and the CA certificates on the target.
+func (cr *credentials) loadRequest(leaf *x509.Certificate, key crypto.Signer, chain []*x509.Certificate) (*cmpb.LoadCertificateRequest, error) {
+	req := &cmpb.LoadCertificateRequest{
+		CertificateId: cr.certID,
+		Certificate:   toCertificate(leaf),
+	}
+	if key != nil {
+		kp, err := toKeyPair(key)
+		if err != nil {
+			return nil, err
+		}
+		req.KeyPair = kp
+	}
+	for _, ca := range chain {
+		req.CaCertificates = append(req.CaCertificates, toCertificate(ca))
+	}
+	for _, ca := range cr.caCerts {
+		req.CaCertificates = append(req.CaCertificates, toCertificate(ca))
+	}
+	return req, nil
+}
+
+// certStream abstracts the CSR and load steps of the Rotate and Install streams.
+type certStream interface {
+	generateCSR(*cmpb.GenerateCSRRequest) (*cmpb.GenerateCSRResponse, error)
+	loadCertificate(*cmpb.LoadCertificateRequest) (*cmpb.LoadCertificateResponse, error)
+}
+
+// load either loads the client provided certificate or has the target
+// generate a CSR, signs it and loads the resulting certificate.
+func (cr *credentials) load(ctx context.Context, s certStream) (*cmpb.LoadCertificateResponse, error) {
+	if cr.cert != nil {
+		req, err := cr.loadRequest(cr.cert, cr.key, nil)
+		if err != nil {
+			return nil, err
+		}
+		return s.loadCertificate(req)
+	}
+	if cr.signer == nil {
+		return nil, fmt.Errorf("either a certificate or a signer must be specified")
+	}
+	csrResp, err := s.generateCSR(&cmpb.GenerateCSRRequest{CertificateId: cr.certID, CsrParams: cr.params})
+	if err != nil {
+		return nil, err
+	}
+	csr, err := ParseCSR(csrResp.GetCsr())
+	if err != nil {
+		return nil, err
+	}
+	chain, err := cr.signer.Sign(ctx, csr)
+	if err != nil {
+		return nil, fmt.Errorf("error signing CSR: %w", err)
+	}
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("signer returned no certificates")
+	}
+	if pub, ok := chain[0].PublicKey.(interface{ Equal(crypto.PublicKey) bool }); !ok || !pub.Equal(csr.PublicKey) {
+		return nil, fmt.Errorf("signed certificate does not match the public key of the CSR")
+	}
+	req, err := cr.loadRequest(chain[0], nil, chain[1:])
+	if err != nil {
+		return nil, err
+	}
+	return s.loadCertificate(req)
+}
+
+// ValidateFunc validates the rotated certificate before the rotation is
+// finalized, typically by connecting to the target with the new trust bundle.
+type ValidateFunc func(context.Context) error
+
+// RotateOperation represents the parameters of a Rotate operation.
+type RotateOperation struct {
+	credentials
+	validate ValidateFunc
+}
+
+// NewRotateOperation creates an empty RotateOperation.
+func NewRotateOperation() *RotateOperation {
+	return &RotateOperation{credentials: credentials{params: &cmpb.CSRParams{}}}
+}
+
+// CertificateID specifies the ID of the certificate to rotate.
+func (r *RotateOperation) CertificateID(id string) *RotateOperation {
+	r.certID = id
+	return r
+}
+
+// CSRParams specifies the parameters of the CSR generated by the target.
+func (r *RotateOperation) CSRParams(params *cmpb.CSRParams) *RotateOperation {
+	r.params = params
+	return r
+}
+
+// CSRTemplate specifies the CSR parameters from a CSR template.
+func (r *RotateOperation) CSRTemplate(tmpl *x509.CertificateRequest) *RotateOperation {
+	r.params = csrParamsFromTemplate(tmpl, r.params.GetMinKeySize())
+	return r
+}
+
+// MinKeySize specifies the minimum size of the key generated by the target.
+func (r *RotateOperation) MinKeySize(size uint32) *RotateOperation {
+	r.params.MinKeySize = size
+	return r
+}
+
+// Signer specifies the signer of the CSR generated by the target.
+func (r *RotateOperation) Signer(s Signer) *RotateOperation {
+	r.signer = s
+	return r
+}
+
+// Certificate specifies a client generated certificate and its private key,
+// in which case the target does not generate a CSR.
+func (r *RotateOperation) Certificate(cert *x509.Certificate, key crypto.Signer) *RotateOperation {
+	r.cert = cert
+	r.key = key
+	return r
+}
+
+// CACertificates specifies CA certificates to load along with the certificate.
+func (r *RotateOperation) CACertificates(certs ...*x509.Certificate) *RotateOperation {
+	r.caCerts = certs
+	return r
+}
+
+// Validate specifies a function that validates the new certificate after it is
+// loaded. The rotation is only finalized if validation succeeds.
+func (r *RotateOperation) Validate(validate ValidateFunc) *RotateOperation {
+	r.validate = validate
+	return r
+}
+
+// Execute performs the Rotate operation.
+// If any step fails, including validation, the stream is cancelled and the
+// target rolls back to the original certificate.
+func (r *RotateOperation) Execute(ctx context.Context, c *internal.Clients) (*cmpb.LoadCertificateResponse, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	rc, err := c.CertificateManagement().Rotate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.load(ctx, &rotateStream{rc})
+	if err != nil {
+		return nil, err
+	}
+	if r.validate != nil {
+		if err := r.validate(ctx); err != nil {
+			return nil, fmt.Errorf("certificate validation failed, rotation aborted: %w", err)
+		}
+	}
+	if err := rc.Send(&cmpb.RotateCertificateRequest{
+		RotateRequest: &cmpb.RotateCertificateRequest_FinalizeRotation{FinalizeRotation: &cmpb.FinalizeRequest{}},
+	}); err != nil {
+		return nil, err
+	}
+	if err := rc.CloseSend(); err != nil {
+		return nil, err
+	}
+	// Wait for the target to end the stream, which confirms the rotation.
+	if rresp, err := rc.Recv(); err != io.EOF {
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("unexpected rotate response after finalize: %v", rresp)
+	}
+	return resp, nil
+}
+
+// Risk returns the risk class of the Rotate operation, which replaces a
+// certificate in use by the services of the target.
+func (r *RotateOperation) Risk() internal.Risk {
+	return internal.RiskDisruptive
+}
+
+type rotateStream struct {
+	rc cmpb.CertificateManagement_RotateClient
+}
+
+func (s *rotateStream) generateCSR(req *cmpb.GenerateCSRRequest) (*cmpb.GenerateCSRResponse, error) {
+	if err := s.rc.Send(&cmpb.RotateCertificateRequest{
+		RotateRequest: &cmpb.RotateCertificateRequest_GenerateCsr{GenerateCsr: req},
+	}); err != nil {
+		return nil, err
+	}
+	resp, err := s.rc.Recv()
+	if err != nil {
+		return nil, err
+	}
+	if resp.GetGeneratedCsr() == nil {
+		return nil, fmt.Errorf("expected generated CSR, got %v", resp)
+	}
+	return resp.GetGeneratedCsr(), nil
+}
+
+func (s *rotateStream) loadCertificate(req *cmpb.LoadCertificateRequest) (*cmpb.LoadCertificateResponse, error) {
+	if err := s.rc.Send(&cmpb.RotateCertificateRequest{
+		RotateRequest: &cmpb.RotateCertificateRequest_LoadCertificate{LoadCertificate: req},
+	}); err != nil {
+		return nil, err
+	}
+	resp, err := s.rc.Recv()
+	if err != nil {
+		return nil, err
+	}
+	if resp.GetLoadCertificate() == nil {
+		return nil, fmt.Errorf("expected load certificate response, got %v", resp)
+	}
+	return resp.GetLoadCertificate(), nil
+}
+
+// InstallOperation represents the parameters of an Install operation.
+type InstallOperation struct {
+	credentials
+}
+
+// NewInstallOperation creates an empty InstallOperation.
+func NewInstallOperation() *InstallOperation {
+	return &InstallOperation{credentials{params: &cmpb.CSRParams{}}}
+}
+
+// CertificateID specifies the ID of the new certificate.
+func (i *InstallOperation) CertificateID(id string) *InstallOperation {
+	i.certID = id
+	return i
+}
+
+// CSRParams specifies the parameters of the CSR generated by the target.
+func (i *InstallOperation) CSRParams(params *cmpb.CSRParams) *InstallOperation {
+	i.params = params
+	return i
+}
+
+// CSRTemplate specifies the CSR parameters from a CSR template.
+func (i *InstallOperation) CSRTemplate(tmpl *x509.CertificateRequest) *InstallOperation {
+	i.params = csrParamsFromTemplate(tmpl, i.params.GetMinKeySize())
+	return i
+}
+
+// MinKeySize specifies the minimum size of the key generated by the target.
+func (i *InstallOperation) MinKeySize(size uint32) *InstallOperation {
+	i.params.MinKeySize = size
+	return i
+}
+
+// Signer specifies the signer of the CSR generated by the target.
+func (i *InstallOperation) Signer(s Signer) *InstallOperation {
+	i.signer = s
+	return i
+}
+
+// Certificate specifies a client generated certificate and its private key,
+// in which case the target does not generate a CSR.
+func (i *InstallOperation) Certificate(cert *x509.Certificate, key crypto.Signer) *InstallOperation {
+	i.cert = cert
+	i.key = key
+	return i
+}
+
+// CACertificates specifies CA certificates to load along with the certificate.
+func (i *InstallOperation) CACertificates(certs ...*x509.Certificate) *InstallOperation {
+	i.caCerts = certs
+	return i
+}
+
+// Execute performs the Install operation. It returns once the target ends
+// the stream, so the install is not reverted by a broken stream.
+func (i *InstallOperation) Execute(ctx context.Context, c *internal.Clients) (*cmpb.LoadCertificateResponse, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	ic, err := c.CertificateManagement().Install(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := i.load(ctx, &installStream{ic})
+	if err != nil {
+		return nil, err
+	}
+	if err := ic.CloseSend(); err != nil {
+		return nil, err
+	}
+	// Wait for the target to end the stream, which confirms the install.
+	if iresp, err := ic.Recv(); err != io.EOF {
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("unexpected install response after load: %v", iresp)
+	}
+	return resp, nil
+}
+
+// Risk returns the risk class of the Install operation, which changes the
+// certificates and trust bundle available to the services of the target.
+func (i *InstallOperation) Risk() internal.Risk {
+	return internal.RiskDisruptive
+}
+
+type installStream struct {
+	ic cmpb.CertificateManagement_InstallClient
+}
+
+func (s *installStream) generateCSR(req *cmpb.GenerateCSRRequest) (*cmpb.GenerateCSRResponse, error) {
+	if err := s.ic.Send(&cmpb.InstallCertificateRequest{
+		InstallRequest: &cmpb.InstallCertificateRequest_GenerateCsr{GenerateCsr: req},
+	}); err != nil {
+		return nil, err
+	}
+	resp, err := s.ic.Recv()
+	if err != nil {
+		return nil, err
+	}
+	if resp.GetGeneratedCsr() == nil {
+		return nil, fmt.Errorf("expected generated CSR, got %v", resp)
+	}
+	return resp.GetGeneratedCsr(), nil
+}
+
+func (s *installStream) loadCertificate(req *cmpb.LoadCertificateRequest) (*cmpb.LoadCertificateResponse, error) {
+	if err := s.ic.Send(&cmpb.InstallCertificateRequest{
+		InstallRequest: &cmpb.InstallCertificateRequest_LoadCertificate{LoadCertificate: req},
+	}); err != nil {
+		return nil, err
+	}
+	resp, err := s.ic.Recv()
+	if err != nil {
+		return nil, err
+	}
+	if resp.GetLoadCertificate() == nil {
+		return nil, fmt.Errorf("expected load certificate response, got %v", resp)
+	}
+	return resp.GetLoadCertificate(), nil
+}
+
+// GenerateCSROperation represents the parameters of a GenerateCSR operation.
+type GenerateCSROperation struct {
+	req *cmpb.GenerateCSRRequest
+}
+
+// NewGenerateCSROperation creates an empty GenerateCSROperation.
+func NewGenerateCSROperation() *GenerateCSROperation {
+	return &GenerateCSROperation{req: &cmpb.GenerateCSRRequest{CsrParams: &cmpb.CSRParams{}}}
+}
+
+// CertificateID specifies the ID of the certificate the CSR is generated for.
+func (g *GenerateCSROperation) CertificateID(id string) *GenerateCSROperation {
+	g.req.CertificateId = id
+	return g
+}
+
+// CSRParams specifies the parameters of the CSR.
+func (g *GenerateCSROperation) CSRParams(params *cmpb.CSRParams) *GenerateCSROperation {
+	g.req.CsrParams = params
+	return g
+}
+
+// CSRTemplate specifies the CSR parameters from a CSR template.
+func (g *GenerateCSROperation) CSRTemplate(tmpl *x509.CertificateRequest) *GenerateCSROperation {
+	g.req.CsrParams = csrParamsFromTemplate(tmpl, g.req.GetCsrParams().GetMinKeySize())
+	return g
+}
+
+// MinKeySize specifies the minimum size of the generated key.
+func (g *GenerateCSROperation) MinKeySize(size uint32) *GenerateCSROperation {
+	g.req.CsrParams.MinKeySize = size
+	return g
+}
+
+// Execute performs the GenerateCSR operation.
+func (g *GenerateCSROperation) Execute(ctx context.Context, c *internal.Clients) (*cmpb.GenerateCSRResponse, error) {
+	return c.CertificateManagement().GenerateCSR(ctx, g.req)
+}
+
+// Request returns the request proto of the GenerateCSR operation.
+func (g *GenerateCSROperation) Request() proto.Message {
+	return g.req
+}
+
+// GetCertificatesOperation represents the parameters of a GetCertificates operation.
+type GetCertificatesOperation struct {
+	req *cmpb.GetCertificatesRequest
+}
+
+// NewGetCertificatesOperation creates an empty GetCertificatesOperation.
+func NewGetCertificatesOperation() *GetCertificatesOperation {
+	return &GetCertificatesOperation{req: &cmpb.GetCertificatesRequest{}}
+}
+
+// Execute performs the GetCertificates operation.
+func (g *GetCertificatesOperation) Execute(ctx context.Context, c *internal.Clients) (*cmpb.GetCertificatesResponse, error) {
+	return c.CertificateManagement().GetCertificates(ctx, g.req)
+}
+
+// Request returns the request proto of the GetCertificates operation.
+func (g *GetCertificatesOperation) Request() proto.Message {
+	return g.req
+}
+
+// Idempotent reports that the GetCertificates operation can safely be retried.
+func (g *GetCertificatesOperation) Idempotent() bool {
+	return true
+}
+
+// RevokeCertificatesOperation represents the parameters of a RevokeCertificates operation.
+type RevokeCertificatesOperation struct {
+	req *cmpb.RevokeCertificatesRequest
+}
+
+// NewRevokeCertificatesOperation creates an empty RevokeCertificatesOperation.
+func NewRevokeCertificatesOperation() *RevokeCertificatesOperation {
+	return &RevokeCertificatesOperation{req: &cmpb.RevokeCertificatesRequest{}}
+}
+
+// CertificateIDs specifies the IDs of the certificates to revoke.
+func (r *RevokeCertificatesOperation) CertificateIDs(ids ...string) *RevokeCertificatesOperation {
+	r.req.CertificateId = ids
+	return r
+}
+
+// Execute performs the RevokeCertificates operation.
+func (r *RevokeCertificatesOperation) Execute(ctx context.Context, c *internal.Clients) (*cmpb.RevokeCertificatesResponse, error) {
+	return c.CertificateManagement().RevokeCertificates(ctx, r.req)
+}
+
+// Request returns the request proto of the RevokeCertificates operation.
+func (r *RevokeCertificatesOperation) Request() proto.Message {
+	return r.req
+}
+
+// Risk returns the risk class of the RevokeCertificates operation.
+func (r *RevokeCertificatesOperation) Risk() internal.Risk {
+	return internal.RiskDestructive
+}
+
+// CanGenerateCSROperation represents the parameters of a CanGenerateCSR operation.
+type CanGenerateCSROperation struct {
+	req *cmpb.CanGenerateCSRRequest
+}
+
+// NewCanGenerateCSROperation creates a CanGenerateCSROperation for X.509
+// certificates with RSA keys.
+func NewCanGenerateCSROperation() *CanGenerateCSROperation {
+	return &CanGenerateCSROperation{req: &cmpb.CanGenerateCSRRequest{
+		CertificateType: cmpb.CertificateType_CT_X509,
+		KeyType:         cmpb.KeyType_KT_RSA,
+	}}
+}
+
+// KeyType specifies the type of key to generate.
+func (cg *CanGenerateCSROperation) KeyType(kt cmpb.KeyType) *CanGenerateCSROperation {
+	cg.req.KeyType = kt
+	return cg
+}
+
+// CertificateType specifies the type of certificate to generate.
+func (cg *CanGenerateCSROperation) CertificateType(ct cmpb.CertificateType) *CanGenerateCSROperation {
+	cg.req.CertificateType = ct
+	return cg
+}
+
+// KeySize specifies the size of the key to generate.
+func (cg *CanGenerateCSROperation) KeySize(size uint32) *CanGenerateCSROperation {
+	cg.req.KeySize = size
+	return cg
+}
+
+// Execute performs the CanGenerateCSR operation.
+func (cg *CanGenerateCSROperation) Execute(ctx context.Context, c *internal.Clients) (*cmpb.CanGenerateCSRResponse, error) {
+	return c.CertificateManagement().CanGenerateCSR(ctx, cg.req)
+}
+
+// Request returns the request proto of the CanGenerateCSR operation.
+func (cg *CanGenerateCSROperation) Request() proto.Message {
+	return cg.req
+}
+
+// Idempotent reports that the CanGenerateCSR operation can safely be retried.
+func (cg *CanGenerateCSROperation) Idempotent() bool {
+	return true
+}
+
+// ParseCSR parses a PEM encoded CSR returned by the target and checks its signature.
+func ParseCSR(csr *cmpb.CSR) (*x509.CertificateRequest, error) {
+	block, _ := pem.Decode(csr.GetCsr())
+	if block == nil {
+		return nil, fmt.Errorf("CSR is not PEM encoded")
+	}
+	req, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	if err := req.CheckSignature(); err != nil {
+		return nil, fmt.Errorf("invalid CSR signature: %w", err)
+	}
+	return req, nil
+}
+
+// ParseCertificate parses a PEM encoded certificate returned by the target.
+func ParseCertificate(cert *cmpb.Certificate) (*x509.Certificate, error) {
+	block, _ := pem.Decode(cert.GetCertificate())
+	if block == nil {
+		return nil, fmt.Errorf("certificate is not PEM encoded")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+func toCertificate(cert *x509.Certificate) *cmpb.Certificate {
+	return &cmpb.Certificate{
+		Type:        cmpb.CertificateType_CT_X509,
+		Certificate: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}),
+	}
+}
+
+func toKeyPair(key crypto.Signer) (*cmpb.KeyPair, error) {
+	priv, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	pub, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		return nil, err
+	}
+	return &cmpb.KeyPair{
+		PrivateKey: pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: priv}),
+		PublicKey:  pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pub}),
+	}, nil
+}
+
+func csrParamsFromTemplate(tmpl *x509.CertificateRequest, minKeySize uint32) *cmpb.CSRParams {
+	p := &cmpb.CSRParams{
+		Type:       cmpb.CertificateType_CT_X509,
+		KeyType:    cmpb.KeyType_KT_RSA,
+		MinKeySize: minKeySize,
+		CommonName: tmpl.Subject.CommonName,
+	}
+	first := func(s []string) string {
+		if len(s) == 0 {
+			return ""
+		}
+		return s[0]
+	}
+	p.Country = first(tmpl.Subject.Country)
+	p.State = first(tmpl.Subject.Province)
+	p.City = first(tmpl.Subject.Locality)
+	p.Organization = first(tmpl.Subject.Organization)
+	p.OrganizationalUnit = first(tmpl.Subject.OrganizationalUnit)
+	p.EmailId = first(tmpl.EmailAddresses)
+	if len(tmpl.IPAddresses) > 0 {
+		p.IpAddress = tmpl.IPAddresses[0].String()
+	}
+	return p
+}
@@ -0,0 +1,484 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cert_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	cmpb "github.com/openconfig/gnoi/cert"
+	"github.com/openconfig/gnoigo/cert"
+	"github.com/openconfig/gnoigo/internal"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+type fakeCertClient struct {
+	cmpb.CertificateManagementClient
+	RotateFn             func(context.Context, ...grpc.CallOption) (cmpb.CertificateManagement_RotateClient, error)
+	InstallFn            func(context.Context, ...grpc.CallOption) (cmpb.CertificateManagement_InstallClient, error)
+	GenerateCSRFn        func(context.Context, *cmpb.GenerateCSRRequest, ...grpc.CallOption) (*cmpb.GenerateCSRResponse, error)
+	GetCertificatesFn    func(context.Context, *cmpb.GetCertificatesRequest, ...grpc.CallOption) (*cmpb.GetCertificatesResponse, error)
+	RevokeCertificatesFn func(context.Context, *cmpb.RevokeCertificatesRequest, ...grpc.CallOption) (*cmpb.RevokeCertificatesResponse, error)
+	CanGenerateCSRFn     func(context.Context, *cmpb.CanGenerateCSRRequest, ...grpc.CallOption) (*cmpb.CanGenerateCSRResponse, error)
+}
+
+func (fc *fakeCertClient) Rotate(ctx context.Context, opts ...grpc.CallOption) (cmpb.CertificateManagement_RotateClient, error) {
+	return fc.RotateFn(ctx, opts...)
+}
+
+func (fc *fakeCertClient) Install(ctx context.Context, opts ...grpc.CallOption) (cmpb.CertificateManagement_InstallClient, error) {
+	return fc.InstallFn(ctx, opts...)
+}
+
+func (fc *fakeCertClient) GenerateCSR(ctx context.Context, in *cmpb.GenerateCSRRequest, opts ...grpc.CallOption) (*cmpb.GenerateCSRResponse, error) {
+	return fc.GenerateCSRFn(ctx, in, opts...)
+}
+
+func (fc *fakeCertClient) GetCertificates(ctx context.Context, in *cmpb.GetCertificatesRequest, opts ...grpc.CallOption) (*cmpb.GetCertificatesResponse, error) {
+	return fc.GetCertificatesFn(ctx, in, opts...)
+}
+
+func (fc *fakeCertClient) RevokeCertificates(ctx context.Context, in *cmpb.RevokeCertificatesRequest, opts ...grpc.CallOption) (*cmpb.RevokeCertificatesResponse, error) {
+	return fc.RevokeCertificatesFn(ctx, in, opts...)
+}
+
+func (fc *fakeCertClient) CanGenerateCSR(ctx context.Context, in *cmpb.CanGenerateCSRRequest, opts ...grpc.CallOption) (*cmpb.CanGenerateCSRResponse, error) {
+	return fc.CanGenerateCSRFn(ctx, in, opts...)
+}
+
+// fakeTarget emulates the target side of the Rotate and Install streams.
+type fakeTarget struct {
+	t         *testing.T
+	key       crypto.Signer
+	gotCSR    *cmpb.GenerateCSRRequest
+	gotLoad   *cmpb.LoadCertificateRequest
+	finalized bool
+	closed    bool
+	queue     []any
+}
+
+func newFakeTarget(t *testing.T) *fakeTarget {
+	t.Helper()
+	return &fakeTarget{t: t, key: newKey(t)}
+}
+
+func (ft *fakeTarget) handleCSR(req *cmpb.GenerateCSRRequest) *cmpb.GenerateCSRResponse {
+	ft.gotCSR = req
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: req.GetCsrParams().GetCommonName()},
+	}, ft.key)
+	if err != nil {
+		ft.t.Fatalf("CreateCertificateRequest() failed: %v", err)
+	}
+	return &cmpb.GenerateCSRResponse{Csr: &cmpb.CSR{
+		Type: cmpb.CertificateType_CT_X509,
+		Csr:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der}),
+	}}
+}
+
+func (ft *fakeTarget) next() (any, error) {
+	if len(ft.queue) == 0 {
+		if ft.closed {
+			return nil, io.EOF
+		}
+		return nil, errors.New("no response queued")
+	}
+	resp := ft.queue[0]
+	ft.queue = ft.queue[1:]
+	return resp, nil
+}
+
+type fakeRotateClient struct {
+	cmpb.CertificateManagement_RotateClient
+	*fakeTarget
+}
+
+func (rc *fakeRotateClient) Send(req *cmpb.RotateCertificateRequest) error {
+	switch {
+	case req.GetGenerateCsr() != nil:
+		rc.queue = append(rc.queue, &cmpb.RotateCertificateResponse{RotateResponse: &cmpb.RotateCertificateResponse_GeneratedCsr{
+			GeneratedCsr: rc.handleCSR(req.GetGenerateCsr()),
+		}})
+	case req.GetLoadCertificate() != nil:
+		rc.gotLoad = req.GetLoadCertificate()
+		rc.queue = append(rc.queue, &cmpb.RotateCertificateResponse{RotateResponse: &cmpb.RotateCertificateResponse_LoadCertificate{
+			LoadCertificate: &cmpb.LoadCertificateResponse{},
+		}})
+	case req.GetFinalizeRotation() != nil:
+		rc.finalized = true
+	}
+	return nil
+}
+
+func (rc *fakeRotateClient) Recv() (*cmpb.RotateCertificateResponse, error) {
+	resp, err := rc.next()
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*cmpb.RotateCertificateResponse), nil
+}
+
+func (rc *fakeRotateClient) CloseSend() error {
+	rc.closed = true
+	return nil
+}
+
+type fakeInstallClient struct {
+	cmpb.CertificateManagement_InstallClient
+	*fakeTarget
+	// trailing is queued after the load certificate response.
+	trailing *cmpb.InstallCertificateResponse
+}
+
+func (ic *fakeInstallClient) Send(req *cmpb.InstallCertificateRequest) error {
+	switch {
+	case req.GetGenerateCsr() != nil:
+		ic.queue = append(ic.queue, &cmpb.InstallCertificateResponse{InstallResponse: &cmpb.InstallCertificateResponse_GeneratedCsr{
+			GeneratedCsr: ic.handleCSR(req.GetGenerateCsr()),
+		}})
+	case req.GetLoadCertificate() != nil:
+		ic.gotLoad = req.GetLoadCertificate()
+		ic.queue = append(ic.queue, &cmpb.InstallCertificateResponse{InstallResponse: &cmpb.InstallCertificateResponse_LoadCertificate{
+			LoadCertificate: &cmpb.LoadCertificateResponse{},
+		}})
+		if ic.trailing != nil {
+			ic.queue = append(ic.queue, ic.trailing)
+		}
+	}
+	return nil
+}
+
+func (ic *fakeInstallClient) Recv() (*cmpb.InstallCertificateResponse, error) {
+	resp, err := ic.next()
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*cmpb.InstallCertificateResponse), nil
+}
+
+func (ic *fakeInstallClient) CloseSend() error {
+	ic.closed = true
+	return nil
+}
+
+func newKey(t *testing.T) crypto.Signer {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() failed: %v", err)
+	}
+	return key
+}
+
+// newCA returns a self-signed CA certificate and its key.
+func newCA(t *testing.T) (*x509.Certificate, crypto.Signer) {
+	t.Helper()
+	key := newKey(t)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, key.Public(), key)
+	if err != nil {
+		t.Fatalf("CreateCertificate() failed: %v", err)
+	}
+	ca, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("ParseCertificate() failed: %v", err)
+	}
+	return ca, key
+}
+
+// signWith returns a SignFunc that signs CSRs with the given CA.
+func signWith(t *testing.T, ca *x509.Certificate, caKey crypto.Signer) cert.SignFunc {
+	return func(_ context.Context, csr *x509.CertificateRequest) ([]*x509.Certificate, error) {
+		tmpl := &x509.Certificate{
+			SerialNumber: big.NewInt(2),
+			Subject:      csr.Subject,
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+		}
+		der, err := x509.CreateCertificate(rand.Reader, tmpl, ca, csr.PublicKey, caKey)
+		if err != nil {
+			return nil, err
+		}
+		leaf, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, err
+		}
+		return []*x509.Certificate{leaf, ca}, nil
+	}
+}
+
+func pemCert(c *x509.Certificate) *cmpb.Certificate {
+	return &cmpb.Certificate{
+		Type:        cmpb.CertificateType_CT_X509,
+		Certificate: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.Raw}),
+	}
+}
+
+func TestRotate(t *testing.T) {
+	ca, caKey := newCA(t)
+	otherKey := newKey(t)
+	wrongSigner := cert.SignFunc(func(ctx context.Context, _ *x509.CertificateRequest) ([]*x509.Certificate, error) {
+		return signWith(t, ca, caKey)(ctx, &x509.CertificateRequest{PublicKey: otherKey.Public()})
+	})
+
+	tests := []struct {
+		desc          string
+		op            *cert.RotateOperation
+		wantFinalized bool
+		wantCSR       bool
+		wantCAs       int
+		wantErr       string
+	}{
+		{
+			desc: "target generated CSR",
+			op: cert.NewRotateOperation().CertificateID("id").
+				CSRTemplate(&x509.CertificateRequest{Subject: pkix.Name{CommonName: "device"}}).
+				Signer(signWith(t, ca, caKey)),
+			wantFinalized: true,
+			wantCSR:       true,
+			wantCAs:       1,
+		},
+		{
+			desc:          "client generated certificate",
+			op:            cert.NewRotateOperation().CertificateID("id").Certificate(ca, caKey).CACertificates(ca),
+			wantFinalized: true,
+			wantCAs:       1,
+		},
+		{
+			desc:    "no signer",
+			op:      cert.NewRotateOperation().CertificateID("id"),
+			wantErr: "signer",
+		},
+		{
+			desc:    "signed key mismatch",
+			op:      cert.NewRotateOperation().CertificateID("id").Signer(wrongSigner),
+			wantCSR: true,
+			wantErr: "public key",
+		},
+		{
+			desc: "signer error",
+			op: cert.NewRotateOperation().CertificateID("id").Signer(cert.SignFunc(func(context.Context, *x509.CertificateRequest) ([]*x509.Certificate, error) {
+				return nil, errors.New("CA unavailable")
+			})),
+			wantCSR: true,
+			wantErr: "CA unavailable",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			target := newFakeTarget(t)
+			var fakeClient internal.Clients
+			fakeClient.CertMgmtClient = &fakeCertClient{RotateFn: func(context.Context, ...grpc.CallOption) (cmpb.CertificateManagement_RotateClient, error) {
+				return &fakeRotateClient{fakeTarget: target}, nil
+			}}
+
+			_, gotErr := tt.op.Execute(context.Background(), &fakeClient)
+			if (gotErr == nil) != (tt.wantErr == "") || (gotErr != nil && !strings.Contains(gotErr.Error(), tt.wantErr)) {
+				t.Errorf("Execute() got unexpected error %v want %s", gotErr, tt.wantErr)
+			}
+			if target.finalized != tt.wantFinalized {
+				t.Errorf("Execute() got finalized %v, want %v", target.finalized, tt.wantFinalized)
+			}
+			if gotCSR := target.gotCSR != nil; gotCSR != tt.wantCSR {
+				t.Errorf("Execute() got CSR generated %v, want %v", gotCSR, tt.wantCSR)
+			}
+			if tt.wantErr != "" {
+				return
+			}
+			if got := target.gotLoad.GetCertificateId(); got != "id" {
+				t.Errorf("Execute() loaded certificate ID %q, want %q", got, "id")
+			}
+			if got := len(target.gotLoad.GetCaCertificates()); got != tt.wantCAs {
+				t.Errorf("Execute() loaded %d CA certificates, want %d", got, tt.wantCAs)
+			}
+			leaf, err := cert.ParseCertificate(target.gotLoad.GetCertificate())
+			if err != nil {
+				t.Fatalf("ParseCertificate() failed: %v", err)
+			}
+			if err := leaf.CheckSignatureFrom(ca); err != nil && !leaf.Equal(ca) {
+				t.Errorf("loaded certificate is not signed by the CA: %v", err)
+			}
+		})
+	}
+}
+
+func TestInstall(t *testing.T) {
+	ca, caKey := newCA(t)
+	target := newFakeTarget(t)
+	var fakeClient internal.Clients
+	fakeClient.CertMgmtClient = &fakeCertClient{InstallFn: func(context.Context, ...grpc.CallOption) (cmpb.CertificateManagement_InstallClient, error) {
+		return &fakeInstallClient{fakeTarget: target}, nil
+	}}
+
+	op := cert.NewInstallOperation().CertificateID("new").MinKeySize(2048).Signer(signWith(t, ca, caKey))
+	if _, err := op.Execute(context.Background(), &fakeClient); err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+	if !target.closed {
+		t.Errorf("Execute() did not close the install stream")
+	}
+	wantCSR := &cmpb.GenerateCSRRequest{CertificateId: "new", CsrParams: &cmpb.CSRParams{MinKeySize: 2048}}
+	if diff := cmp.Diff(wantCSR, target.gotCSR, protocmp.Transform()); diff != "" {
+		t.Errorf("Execute() sent unexpected CSR request diff (-want +got): %s", diff)
+	}
+	wantCAs := []*cmpb.Certificate{pemCert(ca)}
+	if diff := cmp.Diff(wantCAs, target.gotLoad.GetCaCertificates(), protocmp.Transform()); diff != "" {
+		t.Errorf("Execute() loaded unexpected CA certificates diff (-want +got): %s", diff)
+	}
+}
+
+func TestInstallUnexpectedResponse(t *testing.T) {
+	ca, caKey := newCA(t)
+	target := newFakeTarget(t)
+	var fakeClient internal.Clients
+	fakeClient.CertMgmtClient = &fakeCertClient{InstallFn: func(context.Context, ...grpc.CallOption) (cmpb.CertificateManagement_InstallClient, error) {
+		return &fakeInstallClient{fakeTarget: target, trailing: &cmpb.InstallCertificateResponse{InstallResponse: &cmpb.InstallCertificateResponse_LoadCertificate{
+			LoadCertificate: &cmpb.LoadCertificateResponse{},
+		}}}, nil
+	}}
+
+	op := cert.NewInstallOperation().CertificateID("new").Signer(signWith(t, ca, caKey))
+	if _, err := op.Execute(context.Background(), &fakeClient); err == nil || !strings.Contains(err.Error(), "unexpected install response") {
+		t.Errorf("Execute() got error %v, want unexpected install response", err)
+	}
+}
+
+func TestGenerateCSR(t *testing.T) {
+	var got *cmpb.GenerateCSRRequest
+	var fakeClient internal.Clients
+	fakeClient.CertMgmtClient = &fakeCertClient{GenerateCSRFn: func(_ context.Context, in *cmpb.GenerateCSRRequest, _ ...grpc.CallOption) (*cmpb.GenerateCSRResponse, error) {
+		got = in
+		return &cmpb.GenerateCSRResponse{}, nil
+	}}
+
+	op := cert.NewGenerateCSROperation().CertificateID("id").CSRTemplate(&x509.CertificateRequest{
+		Subject:        pkix.Name{CommonName: "device", Country: []string{"US"}, Organization: []string{"org"}},
+		EmailAddresses: []string{"ops@example.com"},
+	}).MinKeySize(4096)
+	if _, err := op.Execute(context.Background(), &fakeClient); err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+	want := &cmpb.GenerateCSRRequest{
+		CertificateId: "id",
+		CsrParams: &cmpb.CSRParams{
+			Type:         cmpb.CertificateType_CT_X509,
+			KeyType:      cmpb.KeyType_KT_RSA,
+			MinKeySize:   4096,
+			CommonName:   "device",
+			Country:      "US",
+			Organization: "org",
+			EmailId:      "ops@example.com",
+		},
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("Execute() sent unexpected request diff (-want +got): %s", diff)
+	}
+}
+
+func TestGetCertificates(t *testing.T) {
+	tests := []struct {
+		desc    string
+		want    *cmpb.GetCertificatesResponse
+		wantErr string
+	}{
+		{
+			desc: "Test GetCertificates",
+			want: &cmpb.GetCertificatesResponse{CertificateInfo: []*cmpb.CertificateInfo{{CertificateId: "id"}}},
+		},
+		{
+			desc:    "GetCertificates returns error",
+			wantErr: "GetCertificates operation error",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var fakeClient internal.Clients
+			fakeClient.CertMgmtClient = &fakeCertClient{GetCertificatesFn: func(context.Context, *cmpb.GetCertificatesRequest, ...grpc.CallOption) (*cmpb.GetCertificatesResponse, error) {
+				if tt.wantErr != "" {
+					return nil, errors.New(tt.wantErr)
+				}
+				return tt.want, nil
+			}}
+
+			got, gotErr := cert.NewGetCertificatesOperation().Execute(context.Background(), &fakeClient)
+			if (gotErr == nil) != (tt.wantErr == "") || (gotErr != nil && !strings.Contains(gotErr.Error(), tt.wantErr)) {
+				t.Errorf("Execute() got unexpected error %v want %s", gotErr, tt.wantErr)
+			}
+			if tt.want != got {
+				t.Errorf("Execute() got unexpected response want %v got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRevokeCertificates(t *testing.T) {
+	var got *cmpb.RevokeCertificatesRequest
+	var fakeClient internal.Clients
+	fakeClient.CertMgmtClient = &fakeCertClient{RevokeCertificatesFn: func(_ context.Context, in *cmpb.RevokeCertificatesRequest, _ ...grpc.CallOption) (*cmpb.RevokeCertificatesResponse, error) {
+		got = in
+		return &cmpb.RevokeCertificatesResponse{RevokedCertificateId: in.GetCertificateId()}, nil
+	}}
+
+	if _, err := cert.NewRevokeCertificatesOperation().CertificateIDs("a", "b").Execute(context.Background(), &fakeClient); err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+	want := &cmpb.RevokeCertificatesRequest{CertificateId: []string{"a", "b"}}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("Execute() sent unexpected request diff (-want +got): %s", diff)
+	}
+}
+
+func TestCanGenerateCSR(t *testing.T) {
+	var got *cmpb.CanGenerateCSRRequest
+	var fakeClient internal.Clients
+	fakeClient.CertMgmtClient = &fakeCertClient{CanGenerateCSRFn: func(_ context.Context, in *cmpb.CanGenerateCSRRequest, _ ...grpc.CallOption) (*cmpb.CanGenerateCSRResponse, error) {
+		got = in
+		return &cmpb.CanGenerateCSRResponse{CanGenerate: true}, nil
+	}}
+
+	resp, err := cert.NewCanGenerateCSROperation().KeySize(2048).Execute(context.Background(), &fakeClient)
+	if err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+	if !resp.GetCanGenerate() {
+		t.Errorf("Execute() got CanGenerate false, want true")
+	}
+	want := &cmpb.CanGenerateCSRRequest{CertificateType: cmpb.CertificateType_CT_X509, KeyType: cmpb.KeyType_KT_RSA, KeySize: 2048}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("Execute() sent unexpected request diff (-want +got): %s", diff)
+	}
+}
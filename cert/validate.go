@@ -0,0 +1,42 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cert
+
+import (
+	"context"
+	"crypto/tls"
+
+	spb "github.com/openconfig/gnoi/system"
+	"github.com/openconfig/gnoigo/internal"
+	"github.com/openconfig/gnoigo/system"
+	"google.golang.org/grpc"
+	grpccreds "google.golang.org/grpc/credentials"
+)
+
+// TimeProbe returns a ValidateFunc that opens a new gRPC connection to target
+// with tlsConfig, whose RootCAs should hold the new trust bundle, and calls
+// System.Time over it. Any handshake or RPC failure fails the validation.
+func TimeProbe(target string, tlsConfig *tls.Config, opts ...grpc.DialOption) ValidateFunc {
+	return func(ctx context.Context) error {
+		opts := append([]grpc.DialOption{grpc.WithTransportCredentials(grpccreds.NewTLS(tlsConfig))}, opts...)
+		conn, err := grpc.NewClient(target, opts...)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		_, err = system.NewTimeOperation().Execute(ctx, &internal.Clients{SystemClient: spb.NewSystemClient(conn)})
+		return err
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cert_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+
+	cmpb "github.com/openconfig/gnoi/cert"
+	spb "github.com/openconfig/gnoi/system"
+	"github.com/openconfig/gnoigo/cert"
+	"github.com/openconfig/gnoigo/internal"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestRotateValidation(t *testing.T) {
+	ca, caKey := newCA(t)
+
+	tests := []struct {
+		desc          string
+		validateErr   error
+		wantFinalized bool
+		wantErr       string
+	}{
+		{
+			desc:          "validation succeeds",
+			wantFinalized: true,
+		},
+		{
+			desc:        "validation fails",
+			validateErr: errors.New("handshake failed"),
+			wantErr:     "rotation aborted: handshake failed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			target := newFakeTarget(t)
+			var streamCtx context.Context
+			var fakeClient internal.Clients
+			fakeClient.CertMgmtClient = &fakeCertClient{RotateFn: func(ctx context.Context, _ ...grpc.CallOption) (cmpb.CertificateManagement_RotateClient, error) {
+				streamCtx = ctx
+				return &fakeRotateClient{fakeTarget: target}, nil
+			}}
+
+			var loadedBeforeValidate bool
+			op := cert.NewRotateOperation().CertificateID("id").Signer(signWith(t, ca, caKey)).Validate(func(context.Context) error {
+				loadedBeforeValidate = target.gotLoad != nil && !target.finalized
+				return tt.validateErr
+			})
+			_, gotErr := op.Execute(context.Background(), &fakeClient)
+			if (gotErr == nil) != (tt.wantErr == "") || (gotErr != nil && !strings.Contains(gotErr.Error(), tt.wantErr)) {
+				t.Errorf("Execute() got unexpected error %v want %s", gotErr, tt.wantErr)
+			}
+			if !loadedBeforeValidate {
+				t.Errorf("Execute() did not validate between loading and finalizing the certificate")
+			}
+			if target.finalized != tt.wantFinalized {
+				t.Errorf("Execute() got finalized %v, want %v", target.finalized, tt.wantFinalized)
+			}
+			if streamCtx.Err() == nil {
+				t.Errorf("Execute() did not cancel the rotate stream")
+			}
+		})
+	}
+}
+
+type fakeSystemServer struct {
+	spb.UnimplementedSystemServer
+}
+
+func (*fakeSystemServer) Time(context.Context, *spb.TimeRequest) (*spb.TimeResponse, error) {
+	return &spb.TimeResponse{Time: 1}, nil
+}
+
+// startTLSServer starts a System server whose certificate is signed by ca.
+func startTLSServer(t *testing.T, ca *cert.LocalCA) *bufconn.Listener {
+	t.Helper()
+	key := newKey(t)
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: "device"},
+		DNSNames: []string{"device"},
+	}, key)
+	if err != nil {
+		t.Fatalf("CreateCertificateRequest() failed: %v", err)
+	}
+	csr, err := x509.ParseCertificateRequest(der)
+	if err != nil {
+		t.Fatalf("ParseCertificateRequest() failed: %v", err)
+	}
+	chain, err := ca.Sign(context.Background(), csr)
+	if err != nil {
+		t.Fatalf("Sign() failed: %v", err)
+	}
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(grpc.Creds(credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{chain[0].Raw}, PrivateKey: key}},
+	})))
+	spb.RegisterSystemServer(srv, &fakeSystemServer{})
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+	return lis
+}
+
+func TestTimeProbe(t *testing.T) {
+	deviceCA, err := cert.LoadLocalCA(writeCA(t))
+	if err != nil {
+		t.Fatalf("LoadLocalCA() failed: %v", err)
+	}
+	otherCA, err := cert.LoadLocalCA(writeCA(t))
+	if err != nil {
+		t.Fatalf("LoadLocalCA() failed: %v", err)
+	}
+	lis := startTLSServer(t, deviceCA)
+	dialer := grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return lis.DialContext(ctx)
+	})
+
+	tests := []struct {
+		desc    string
+		roots   *cert.LocalCA
+		wantErr bool
+	}{
+		{
+			desc:  "trusted certificate",
+			roots: deviceCA,
+		},
+		{
+			desc:    "untrusted certificate",
+			roots:   otherCA,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			pool := x509.NewCertPool()
+			pool.AddCert(tt.roots.Certificate())
+			probe := cert.TimeProbe("passthrough:///device", &tls.Config{RootCAs: pool, ServerName: "device"}, dialer)
+			if err := probe(context.Background()); (err != nil) != tt.wantErr {
+				t.Errorf("TimeProbe() got error %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
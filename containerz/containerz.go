@@ -0,0 +1,628 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package containerz provides gNOI containerz operations.
+package containerz
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	log "github.com/golang/glog"
+	cmpb "github.com/openconfig/gnoi/common"
+	cpb "github.com/openconfig/gnoi/containerz"
+	"github.com/openconfig/gnoigo/internal"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// defaultChunkSize is used if the target does not specify a chunk size.
+	defaultChunkSize = 64 * 1024
+	// maxChunkSize is the default maximal gRPC message size.
+	maxChunkSize = 4 * 1024 * 1024
+)
+
+// DeployOperation represents the parameters of a Deploy operation.
+type DeployOperation struct {
+	req        *cpb.ImageTransfer
+	reader     io.Reader
+	sourceFile string
+}
+
+// NewDeployOperation creates an empty DeployOperation.
+func NewDeployOperation() *DeployOperation {
+	return &DeployOperation{req: &cpb.ImageTransfer{}}
+}
+
+// Name specifies the name of the image or plugin on the target.
+func (d *DeployOperation) Name(name string) *DeployOperation {
+	d.req.Name = name
+	return d
+}
+
+// Tag specifies the tag applied to the image.
+func (d *DeployOperation) Tag(tag string) *DeployOperation {
+	d.req.Tag = tag
+	return d
+}
+
+// ImageSize specifies the size of the image in bytes.
+// It is not needed if the image is read from a source file.
+func (d *DeployOperation) ImageSize(size uint64) *DeployOperation {
+	d.req.ImageSize = size
+	return d
+}
+
+// Plugin specifies whether the image is a plugin.
+func (d *DeployOperation) Plugin(plugin bool) *DeployOperation {
+	d.req.IsPlugin = plugin
+	return d
+}
+
+// RemoteDownload instructs the target to fetch the image from a remote location
+// instead of receiving its content from the client.
+func (d *DeployOperation) RemoteDownload(rd *cmpb.RemoteDownload) *DeployOperation {
+	d.req.RemoteDownload = rd
+	return d
+}
+
+// Reader specifies the reader for the image content.
+func (d *DeployOperation) Reader(reader io.Reader) *DeployOperation {
+	d.reader = reader
+	return d
+}
+
+// SourceFile specifies the local image tarball to transfer.
+func (d *DeployOperation) SourceFile(file string) *DeployOperation {
+	d.sourceFile = file
+	return d
+}
+
+// Execute performs the Deploy operation.
+func (d *DeployOperation) Execute(ctx context.Context, c *internal.Clients) (*cpb.DeployResponse, error) {
+	req := d.req
+	reader := d.reader
+	if d.sourceFile != "" {
+		f, err := os.Open(d.sourceFile)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		if req.GetImageSize() == 0 {
+			fi, err := f.Stat()
+			if err != nil {
+				return nil, err
+			}
+			req = proto.Clone(d.req).(*cpb.ImageTransfer)
+			req.ImageSize = uint64(fi.Size())
+		}
+		reader = f
+	}
+	if reader == nil && req.GetRemoteDownload() == nil {
+		return nil, fmt.Errorf("no reader, source file or remote download specified for deploy operation")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	dc, err := c.Containerz().Deploy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer dc.CloseSend()
+
+	if err := dc.Send(&cpb.DeployRequest{Request: &cpb.DeployRequest_ImageTransfer{ImageTransfer: req}}); err != nil {
+		return nil, err
+	}
+	if req.GetRemoteDownload() != nil {
+		return awaitDeploy(dc)
+	}
+
+	resp, err := dc.Recv()
+	if err != nil {
+		return nil, err
+	}
+	if err := deployError(resp); err != nil {
+		return nil, err
+	}
+	ready := resp.GetImageTransferReady()
+	if ready == nil {
+		return nil, fmt.Errorf("expected image transfer ready, got %v", resp)
+	}
+	chunkSize := int(ready.GetChunkSize())
+	if chunkSize <= 0 {
+		chunkSize = defaultChunkSize
+	}
+	if chunkSize > maxChunkSize {
+		chunkSize = maxChunkSize
+	}
+
+	type result struct {
+		resp *cpb.DeployResponse
+		err  error
+	}
+	awaitChan := make(chan result, 1)
+	go func() {
+		resp, err := awaitDeploy(dc)
+		if err != nil {
+			cancel()
+		}
+		awaitChan <- result{resp: resp, err: err}
+	}()
+	sendErr := transferImage(ctx, dc, reader, chunkSize)
+	if sendErr != nil {
+		cancel()
+	} else if err := dc.CloseSend(); err != nil {
+		cancel()
+		sendErr = err
+	}
+	res := <-awaitChan
+	if err := internal.MergeStreamErrors(sendErr, res.err); err != nil {
+		return nil, err
+	}
+	return res.resp, nil
+}
+
+// Request returns the request proto of the Deploy operation.
+func (d *DeployOperation) Request() proto.Message {
+	return d.req
+}
+
+// awaitDeploy receives messages from the client until the image transfer
+// either succeeds or fails.
+func awaitDeploy(dc cpb.Containerz_DeployClient) (*cpb.DeployResponse, error) {
+	for {
+		resp, err := dc.Recv()
+		if err != nil {
+			return nil, err
+		}
+		if err := deployError(resp); err != nil {
+			return nil, err
+		}
+		switch v := resp.GetResponse().(type) {
+		case *cpb.DeployResponse_ImageTransferSuccess:
+			return resp, nil
+		case *cpb.DeployResponse_ImageTransferProgress:
+			log.Infof("deploy progress: %v bytes received by target", v.ImageTransferProgress.GetBytesReceived())
+		default:
+			return nil, fmt.Errorf("unexpected deploy response: %v (%T)", v, v)
+		}
+	}
+}
+
+func deployError(resp *cpb.DeployResponse) error {
+	if st := resp.GetImageTransferError(); st != nil {
+		return fmt.Errorf("image transfer error: %w", status.ErrorProto(st))
+	}
+	return nil
+}
+
+func transferImage(ctx context.Context, dc cpb.Containerz_DeployClient, reader io.Reader, chunkSize int) error {
+	buf := make([]byte, chunkSize)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		n, err := io.ReadFull(reader, buf)
+		if n > 0 {
+			content := make([]byte, n)
+			copy(content, buf[:n])
+			if sendErr := dc.Send(&cpb.DeployRequest{Request: &cpb.DeployRequest_Content{Content: content}}); sendErr != nil {
+				return sendErr
+			}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return dc.Send(&cpb.DeployRequest{Request: &cpb.DeployRequest_ImageTransferEnd{ImageTransferEnd: &cpb.ImageTransferEnd{}}})
+}
+
+// ListImageOperation represents the parameters of a ListImage operation.
+type ListImageOperation struct {
+	req *cpb.ListImageRequest
+}
+
+// NewListImageOperation creates an empty ListImageOperation.
+func NewListImageOperation() *ListImageOperation {
+	return &ListImageOperation{req: &cpb.ListImageRequest{}}
+}
+
+// Limit specifies the maximum number of images to return.
+func (l *ListImageOperation) Limit(limit int32) *ListImageOperation {
+	l.req.Limit = limit
+	return l
+}
+
+// Filter adds a filter on the images to return.
+func (l *ListImageOperation) Filter(key string, values ...string) *ListImageOperation {
+	l.req.Filter = append(l.req.Filter, &cpb.ListImageRequest_Filter{Key: key, Value: values})
+	return l
+}
+
+// Execute performs the ListImage operation.
+func (l *ListImageOperation) Execute(ctx context.Context, c *internal.Clients) ([]*cpb.ListImageResponse, error) {
+	list, err := c.Containerz().ListImage(ctx, l.req)
+	if err != nil {
+		return nil, err
+	}
+
+	var listResp []*cpb.ListImageResponse
+
+	for {
+		resp, err := list.Recv()
+		switch {
+		case err == io.EOF:
+			return listResp, nil
+		case err != nil:
+			return nil, err
+		default:
+			listResp = append(listResp, resp)
+		}
+	}
+}
+
+// Request returns the request proto of the ListImage operation.
+func (l *ListImageOperation) Request() proto.Message {
+	return l.req
+}
+
+// Idempotent reports that the ListImage operation can safely be retried.
+func (l *ListImageOperation) Idempotent() bool {
+	return true
+}
+
+// RemoveImageOperation represents the parameters of a RemoveImage operation.
+type RemoveImageOperation struct {
+	req *cpb.RemoveImageRequest
+}
+
+// NewRemoveImageOperation creates an empty RemoveImageOperation.
+func NewRemoveImageOperation() *RemoveImageOperation {
+	return &RemoveImageOperation{req: &cpb.RemoveImageRequest{}}
+}
+
+// Name specifies the name of the image to remove.
+func (r *RemoveImageOperation) Name(name string) *RemoveImageOperation {
+	r.req.Name = name
+	return r
+}
+
+// Tag specifies the tag of the image to remove.
+func (r *RemoveImageOperation) Tag(tag string) *RemoveImageOperation {
+	r.req.Tag = tag
+	return r
+}
+
+// Force specifies whether to remove the image even if containers use it.
+func (r *RemoveImageOperation) Force(force bool) *RemoveImageOperation {
+	r.req.Force = force
+	return r
+}
+
+// Execute performs the RemoveImage operation.
+func (r *RemoveImageOperation) Execute(ctx context.Context, c *internal.Clients) (*cpb.RemoveImageResponse, error) {
+	return c.Containerz().RemoveImage(ctx, r.req)
+}
+
+// Request returns the request proto of the RemoveImage operation.
+func (r *RemoveImageOperation) Request() proto.Message {
+	return r.req
+}
+
+// Risk returns the risk class of the RemoveImage operation.
+func (r *RemoveImageOperation) Risk() internal.Risk {
+	return internal.RiskDestructive
+}
+
+// StartContainerOperation represents the parameters of a StartContainer operation.
+type StartContainerOperation struct {
+	req *cpb.StartContainerRequest
+}
+
+// NewStartContainerOperation creates an empty StartContainerOperation.
+func NewStartContainerOperation() *StartContainerOperation {
+	return &StartContainerOperation{req: &cpb.StartContainerRequest{}}
+}
+
+// ImageName specifies the name of the image to start.
+func (s *StartContainerOperation) ImageName(name string) *StartContainerOperation {
+	s.req.ImageName = name
+	return s
+}
+
+// Tag specifies the tag of the image to start.
+func (s *StartContainerOperation) Tag(tag string) *StartContainerOperation {
+	s.req.Tag = tag
+	return s
+}
+
+// Cmd specifies the command to run in the container.
+func (s *StartContainerOperation) Cmd(cmd string) *StartContainerOperation {
+	s.req.Cmd = cmd
+	return s
+}
+
+// InstanceName specifies the name of the container instance.
+func (s *StartContainerOperation) InstanceName(name string) *StartContainerOperation {
+	s.req.InstanceName = name
+	return s
+}
+
+// Port adds a mapping from an internal container port to an external port.
+func (s *StartContainerOperation) Port(internalPort, externalPort uint32) *StartContainerOperation {
+	s.req.Ports = append(s.req.Ports, &cpb.StartContainerRequest_Port{Internal: internalPort, External: externalPort})
+	return s
+}
+
+// Environment specifies the environment variables of the container.
+func (s *StartContainerOperation) Environment(env map[string]string) *StartContainerOperation {
+	s.req.Environment = env
+	return s
+}
+
+// Volume adds a volume mounted into the container.
+func (s *StartContainerOperation) Volume(name, mountPoint string, readOnly bool) *StartContainerOperation {
+	s.req.Volumes = append(s.req.Volumes, &cpb.Volume{Name: name, MountPoint: mountPoint, ReadOnly: readOnly})
+	return s
+}
+
+// Network specifies the network the container is attached to.
+func (s *StartContainerOperation) Network(network string) *StartContainerOperation {
+	s.req.Network = network
+	return s
+}
+
+// Capabilities specifies the capabilities to add to and remove from the container.
+func (s *StartContainerOperation) Capabilities(add, remove []string) *StartContainerOperation {
+	s.req.Cap = &cpb.StartContainerRequest_Capabilities{Add: add, Remove: remove}
+	return s
+}
+
+// Restart specifies the restart policy of the container.
+func (s *StartContainerOperation) Restart(policy cpb.StartContainerRequest_Restart_Policy, attempts uint32) *StartContainerOperation {
+	s.req.Restart = &cpb.StartContainerRequest_Restart{Policy: policy, Attempts: attempts}
+	return s
+}
+
+// RunAs specifies the user and group the container runs as.
+func (s *StartContainerOperation) RunAs(user, group string) *StartContainerOperation {
+	s.req.RunAs = &cpb.StartContainerRequest_RunAs{User: user, Group: group}
+	return s
+}
+
+// Labels specifies the labels of the container.
+func (s *StartContainerOperation) Labels(labels map[string]string) *StartContainerOperation {
+	s.req.Labels = labels
+	return s
+}
+
+// Limits specifies the resource limits of the container.
+func (s *StartContainerOperation) Limits(maxCPU float64, softMemBytes, hardMemBytes int64) *StartContainerOperation {
+	s.req.Limits = &cpb.StartContainerRequest_Limits{MaxCpu: maxCPU, SoftMemBytes: softMemBytes, HardMemBytes: hardMemBytes}
+	return s
+}
+
+// Execute performs the StartContainer operation.
+func (s *StartContainerOperation) Execute(ctx context.Context, c *internal.Clients) (*cpb.StartContainerResponse, error) {
+	return c.Containerz().StartContainer(ctx, s.req)
+}
+
+// Request returns the request proto of the StartContainer operation.
+func (s *StartContainerOperation) Request() proto.Message {
+	return s.req
+}
+
+// StopContainerOperation represents the parameters of a StopContainer operation.
+type StopContainerOperation struct {
+	req *cpb.StopContainerRequest
+}
+
+// NewStopContainerOperation creates an empty StopContainerOperation.
+func NewStopContainerOperation() *StopContainerOperation {
+	return &StopContainerOperation{req: &cpb.StopContainerRequest{}}
+}
+
+// InstanceName specifies the name of the container instance to stop.
+func (s *StopContainerOperation) InstanceName(name string) *StopContainerOperation {
+	s.req.InstanceName = name
+	return s
+}
+
+// Force specifies whether to forcefully stop the container.
+func (s *StopContainerOperation) Force(force bool) *StopContainerOperation {
+	s.req.Force = force
+	return s
+}
+
+// Restart specifies whether to restart the container after stopping it.
+func (s *StopContainerOperation) Restart(restart bool) *StopContainerOperation {
+	s.req.Restart = restart
+	return s
+}
+
+// Execute performs the StopContainer operation.
+func (s *StopContainerOperation) Execute(ctx context.Context, c *internal.Clients) (*cpb.StopContainerResponse, error) {
+	return c.Containerz().StopContainer(ctx, s.req)
+}
+
+// Request returns the request proto of the StopContainer operation.
+func (s *StopContainerOperation) Request() proto.Message {
+	return s.req
+}
+
+// Risk returns the risk class of the StopContainer operation.
+func (s *StopContainerOperation) Risk() internal.Risk {
+	return internal.RiskDisruptive
+}
+
+// RemoveContainerOperation represents the parameters of a RemoveContainer operation.
+type RemoveContainerOperation struct {
+	req *cpb.RemoveContainerRequest
+}
+
+// NewRemoveContainerOperation creates an empty RemoveContainerOperation.
+func NewRemoveContainerOperation() *RemoveContainerOperation {
+	return &RemoveContainerOperation{req: &cpb.RemoveContainerRequest{}}
+}
+
+// Name specifies the name of the container to remove.
+func (r *RemoveContainerOperation) Name(name string) *RemoveContainerOperation {
+	r.req.Name = name
+	return r
+}
+
+// Force specifies whether to remove the container even if it is running.
+func (r *RemoveContainerOperation) Force(force bool) *RemoveContainerOperation {
+	r.req.Force = force
+	return r
+}
+
+// Execute performs the RemoveContainer operation.
+func (r *RemoveContainerOperation) Execute(ctx context.Context, c *internal.Clients) (*cpb.RemoveContainerResponse, error) {
+	return c.Containerz().RemoveContainer(ctx, r.req)
+}
+
+// Request returns the request proto of the RemoveContainer operation.
+func (r *RemoveContainerOperation) Request() proto.Message {
+	return r.req
+}
+
+// Risk returns the risk class of the RemoveContainer operation.
+func (r *RemoveContainerOperation) Risk() internal.Risk {
+	return internal.RiskDestructive
+}
+
+// UpdateContainerOperation represents the parameters of an UpdateContainer operation.
+type UpdateContainerOperation struct {
+	req *cpb.UpdateContainerRequest
+}
+
+// NewUpdateContainerOperation creates an empty UpdateContainerOperation.
+func NewUpdateContainerOperation() *UpdateContainerOperation {
+	return &UpdateContainerOperation{req: &cpb.UpdateContainerRequest{}}
+}
+
+// InstanceName specifies the name of the container instance to update.
+func (u *UpdateContainerOperation) InstanceName(name string) *UpdateContainerOperation {
+	u.req.InstanceName = name
+	return u
+}
+
+// ImageName specifies the name of the image to update the container to.
+func (u *UpdateContainerOperation) ImageName(name string) *UpdateContainerOperation {
+	u.req.ImageName = name
+	return u
+}
+
+// ImageTag specifies the tag of the image to update the container to.
+func (u *UpdateContainerOperation) ImageTag(tag string) *UpdateContainerOperation {
+	u.req.ImageTag = tag
+	return u
+}
+
+// Params specifies the parameters the updated container is started with.
+func (u *UpdateContainerOperation) Params(params *StartContainerOperation) *UpdateContainerOperation {
+	u.req.Params = params.req
+	return u
+}
+
+// Async specifies whether the target returns before the update is complete.
+func (u *UpdateContainerOperation) Async(async bool) *UpdateContainerOperation {
+	u.req.Async = async
+	return u
+}
+
+// Execute performs the UpdateContainer operation.
+func (u *UpdateContainerOperation) Execute(ctx context.Context, c *internal.Clients) (*cpb.UpdateContainerResponse, error) {
+	return c.Containerz().UpdateContainer(ctx, u.req)
+}
+
+// Request returns the request proto of the UpdateContainer operation.
+func (u *UpdateContainerOperation) Request() proto.Message {
+	return u.req
+}
+
+// Risk returns the risk class of the UpdateContainer operation.
+func (u *UpdateContainerOperation) Risk() internal.Risk {
+	return internal.RiskDisruptive
+}
+
+// ListContainerOperation represents the parameters of a ListContainer operation.
+type ListContainerOperation struct {
+	req *cpb.ListContainerRequest
+}
+
+// NewListContainerOperation creates an empty ListContainerOperation.
+func NewListContainerOperation() *ListContainerOperation {
+	return &ListContainerOperation{req: &cpb.ListContainerRequest{}}
+}
+
+// All specifies whether to list stopped containers as well as running ones.
+func (l *ListContainerOperation) All(all bool) *ListContainerOperation {
+	l.req.All = all
+	return l
+}
+
+// Limit specifies the maximum number of containers to return.
+func (l *ListContainerOperation) Limit(limit int32) *ListContainerOperation {
+	l.req.Limit = limit
+	return l
+}
+
+// Filter adds a filter on the containers to return.
+func (l *ListContainerOperation) Filter(key string, values ...string) *ListContainerOperation {
+	l.req.Filter = append(l.req.Filter, &cpb.ListContainerRequest_Filter{Key: key, Value: values})
+	return l
+}
+
+// Execute performs the ListContainer operation.
+func (l *ListContainerOperation) Execute(ctx context.Context, c *internal.Clients) ([]*cpb.ListContainerResponse, error) {
+	list, err := c.Containerz().ListContainer(ctx, l.req)
+	if err != nil {
+		return nil, err
+	}
+
+	var listResp []*cpb.ListContainerResponse
+
+	for {
+		resp, err := list.Recv()
+		switch {
+		case err == io.EOF:
+			return listResp, nil
+		case err != nil:
+			return nil, err
+		default:
+			listResp = append(listResp, resp)
+		}
+	}
+}
+
+// Request returns the request proto of the ListContainer operation.
+func (l *ListContainerOperation) Request() proto.Message {
+	return l.req
+}
+
+// Idempotent reports that the ListContainer operation can safely be retried.
+func (l *ListContainerOperation) Idempotent() bool {
+	return true
+}
@@ -0,0 +1,471 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package containerz_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	cmpb "github.com/openconfig/gnoi/common"
+	cpb "github.com/openconfig/gnoi/containerz"
+	"github.com/openconfig/gnoigo/containerz"
+	"github.com/openconfig/gnoigo/internal"
+	"google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+type fakeContainerzClient struct {
+	cpb.ContainerzClient
+	DeployFn          func(context.Context, ...grpc.CallOption) (cpb.Containerz_DeployClient, error)
+	ListImageFn       func(context.Context, *cpb.ListImageRequest, ...grpc.CallOption) (cpb.Containerz_ListImageClient, error)
+	RemoveImageFn     func(context.Context, *cpb.RemoveImageRequest, ...grpc.CallOption) (*cpb.RemoveImageResponse, error)
+	StartContainerFn  func(context.Context, *cpb.StartContainerRequest, ...grpc.CallOption) (*cpb.StartContainerResponse, error)
+	StopContainerFn   func(context.Context, *cpb.StopContainerRequest, ...grpc.CallOption) (*cpb.StopContainerResponse, error)
+	UpdateContainerFn func(context.Context, *cpb.UpdateContainerRequest, ...grpc.CallOption) (*cpb.UpdateContainerResponse, error)
+	ListContainerFn   func(context.Context, *cpb.ListContainerRequest, ...grpc.CallOption) (cpb.Containerz_ListContainerClient, error)
+	LogFn             func(context.Context, *cpb.LogRequest, ...grpc.CallOption) (cpb.Containerz_LogClient, error)
+	CreateVolumeFn    func(context.Context, *cpb.CreateVolumeRequest, ...grpc.CallOption) (*cpb.CreateVolumeResponse, error)
+	RemoveVolumeFn    func(context.Context, *cpb.RemoveVolumeRequest, ...grpc.CallOption) (*cpb.RemoveVolumeResponse, error)
+	ListVolumeFn      func(context.Context, *cpb.ListVolumeRequest, ...grpc.CallOption) (cpb.Containerz_ListVolumeClient, error)
+	StartPluginFn     func(context.Context, *cpb.StartPluginRequest, ...grpc.CallOption) (*cpb.StartPluginResponse, error)
+	StopPluginFn      func(context.Context, *cpb.StopPluginRequest, ...grpc.CallOption) (*cpb.StopPluginResponse, error)
+	ListPluginsFn     func(context.Context, *cpb.ListPluginsRequest, ...grpc.CallOption) (*cpb.ListPluginsResponse, error)
+	RemovePluginFn    func(context.Context, *cpb.RemovePluginRequest, ...grpc.CallOption) (*cpb.RemovePluginResponse, error)
+}
+
+func (fc *fakeContainerzClient) Deploy(ctx context.Context, opts ...grpc.CallOption) (cpb.Containerz_DeployClient, error) {
+	return fc.DeployFn(ctx, opts...)
+}
+
+func (fc *fakeContainerzClient) ListImage(ctx context.Context, in *cpb.ListImageRequest, opts ...grpc.CallOption) (cpb.Containerz_ListImageClient, error) {
+	return fc.ListImageFn(ctx, in, opts...)
+}
+
+func (fc *fakeContainerzClient) RemoveImage(ctx context.Context, in *cpb.RemoveImageRequest, opts ...grpc.CallOption) (*cpb.RemoveImageResponse, error) {
+	return fc.RemoveImageFn(ctx, in, opts...)
+}
+
+func (fc *fakeContainerzClient) StartContainer(ctx context.Context, in *cpb.StartContainerRequest, opts ...grpc.CallOption) (*cpb.StartContainerResponse, error) {
+	return fc.StartContainerFn(ctx, in, opts...)
+}
+
+func (fc *fakeContainerzClient) StopContainer(ctx context.Context, in *cpb.StopContainerRequest, opts ...grpc.CallOption) (*cpb.StopContainerResponse, error) {
+	return fc.StopContainerFn(ctx, in, opts...)
+}
+
+func (fc *fakeContainerzClient) UpdateContainer(ctx context.Context, in *cpb.UpdateContainerRequest, opts ...grpc.CallOption) (*cpb.UpdateContainerResponse, error) {
+	return fc.UpdateContainerFn(ctx, in, opts...)
+}
+
+func (fc *fakeContainerzClient) ListContainer(ctx context.Context, in *cpb.ListContainerRequest, opts ...grpc.CallOption) (cpb.Containerz_ListContainerClient, error) {
+	return fc.ListContainerFn(ctx, in, opts...)
+}
+
+func (fc *fakeContainerzClient) Log(ctx context.Context, in *cpb.LogRequest, opts ...grpc.CallOption) (cpb.Containerz_LogClient, error) {
+	return fc.LogFn(ctx, in, opts...)
+}
+
+func (fc *fakeContainerzClient) CreateVolume(ctx context.Context, in *cpb.CreateVolumeRequest, opts ...grpc.CallOption) (*cpb.CreateVolumeResponse, error) {
+	return fc.CreateVolumeFn(ctx, in, opts...)
+}
+
+func (fc *fakeContainerzClient) RemoveVolume(ctx context.Context, in *cpb.RemoveVolumeRequest, opts ...grpc.CallOption) (*cpb.RemoveVolumeResponse, error) {
+	return fc.RemoveVolumeFn(ctx, in, opts...)
+}
+
+func (fc *fakeContainerzClient) ListVolume(ctx context.Context, in *cpb.ListVolumeRequest, opts ...grpc.CallOption) (cpb.Containerz_ListVolumeClient, error) {
+	return fc.ListVolumeFn(ctx, in, opts...)
+}
+
+func (fc *fakeContainerzClient) StartPlugin(ctx context.Context, in *cpb.StartPluginRequest, opts ...grpc.CallOption) (*cpb.StartPluginResponse, error) {
+	return fc.StartPluginFn(ctx, in, opts...)
+}
+
+func (fc *fakeContainerzClient) StopPlugin(ctx context.Context, in *cpb.StopPluginRequest, opts ...grpc.CallOption) (*cpb.StopPluginResponse, error) {
+	return fc.StopPluginFn(ctx, in, opts...)
+}
+
+func (fc *fakeContainerzClient) ListPlugins(ctx context.Context, in *cpb.ListPluginsRequest, opts ...grpc.CallOption) (*cpb.ListPluginsResponse, error) {
+	return fc.ListPluginsFn(ctx, in, opts...)
+}
+
+func (fc *fakeContainerzClient) RemovePlugin(ctx context.Context, in *cpb.RemovePluginRequest, opts ...grpc.CallOption) (*cpb.RemovePluginResponse, error) {
+	return fc.RemovePluginFn(ctx, in, opts...)
+}
+
+// fakeDeployClient emulates a target that replies to the image transfer with
+// ImageTransferReady, and to the end of the transfer with the final response.
+type fakeDeployClient struct {
+	cpb.Containerz_DeployClient
+	ctx       context.Context
+	chunkSize int32
+	final     *cpb.DeployResponse
+
+	mu      sync.Mutex
+	gotSent []*cpb.DeployRequest
+	recv    chan *cpb.DeployResponse
+}
+
+func newFakeDeployClient(ctx context.Context, chunkSize int32, final *cpb.DeployResponse) *fakeDeployClient {
+	return &fakeDeployClient{ctx: ctx, chunkSize: chunkSize, final: final, recv: make(chan *cpb.DeployResponse, 2)}
+}
+
+func (dc *fakeDeployClient) Send(req *cpb.DeployRequest) error {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	dc.gotSent = append(dc.gotSent, req)
+	switch {
+	case req.GetImageTransfer().GetRemoteDownload() != nil:
+		dc.recv <- dc.final
+	case req.GetImageTransfer() != nil:
+		dc.recv <- &cpb.DeployResponse{Response: &cpb.DeployResponse_ImageTransferReady{
+			ImageTransferReady: &cpb.ImageTransferReady{ChunkSize: dc.chunkSize},
+		}}
+	case req.GetImageTransferEnd() != nil:
+		dc.recv <- dc.final
+	}
+	return nil
+}
+
+func (dc *fakeDeployClient) Recv() (*cpb.DeployResponse, error) {
+	select {
+	case <-dc.ctx.Done():
+		return nil, dc.ctx.Err()
+	case resp := <-dc.recv:
+		return resp, nil
+	}
+}
+
+func (*fakeDeployClient) CloseSend() error {
+	return nil
+}
+
+func TestDeploy(t *testing.T) {
+	const data = "container image tarball"
+	sourceFile := filepath.Join(t.TempDir(), "image.tar")
+	if err := os.WriteFile(sourceFile, []byte(data), 0644); err != nil {
+		t.Fatalf("unable to write temp file contents: %v", err)
+	}
+	success := &cpb.DeployResponse{Response: &cpb.DeployResponse_ImageTransferSuccess{
+		ImageTransferSuccess: &cpb.ImageTransferSuccess{Name: "img", Tag: "v1"},
+	}}
+	remote := &cmpb.RemoteDownload{Path: "example.com/image.tar", Protocol: cmpb.RemoteDownload_HTTPS}
+	transfer := func(it *cpb.ImageTransfer) *cpb.DeployRequest {
+		return &cpb.DeployRequest{Request: &cpb.DeployRequest_ImageTransfer{ImageTransfer: it}}
+	}
+	content := func(s string) *cpb.DeployRequest {
+		return &cpb.DeployRequest{Request: &cpb.DeployRequest_Content{Content: []byte(s)}}
+	}
+	end := &cpb.DeployRequest{Request: &cpb.DeployRequest_ImageTransferEnd{ImageTransferEnd: &cpb.ImageTransferEnd{}}}
+
+	tests := []struct {
+		desc      string
+		op        *containerz.DeployOperation
+		chunkSize int32
+		final     *cpb.DeployResponse
+		want      *cpb.DeployResponse
+		wantSent  []*cpb.DeployRequest
+		wantErr   string
+	}{
+		{
+			desc:     "deploy from source file",
+			op:       containerz.NewDeployOperation().Name("img").Tag("v1").SourceFile(sourceFile),
+			final:    success,
+			want:     success,
+			wantSent: []*cpb.DeployRequest{transfer(&cpb.ImageTransfer{Name: "img", Tag: "v1", ImageSize: uint64(len(data))}), content(data), end},
+		},
+		{
+			desc:      "deploy from reader in target sized chunks",
+			op:        containerz.NewDeployOperation().Name("img").Tag("v1").ImageSize(10).Reader(bytes.NewReader([]byte("0123456789"))),
+			chunkSize: 4,
+			final:     success,
+			want:      success,
+			wantSent:  []*cpb.DeployRequest{transfer(&cpb.ImageTransfer{Name: "img", Tag: "v1", ImageSize: 10}), content("0123"), content("4567"), content("89"), end},
+		},
+		{
+			desc:     "deploy from remote download",
+			op:       containerz.NewDeployOperation().Name("img").Tag("v1").RemoteDownload(remote),
+			final:    success,
+			want:     success,
+			wantSent: []*cpb.DeployRequest{transfer(&cpb.ImageTransfer{Name: "img", Tag: "v1", RemoteDownload: remote})},
+		},
+		{
+			desc: "image transfer error",
+			op:   containerz.NewDeployOperation().Name("img").Reader(strings.NewReader(data)),
+			final: &cpb.DeployResponse{Response: &cpb.DeployResponse_ImageTransferError{
+				ImageTransferError: &status.Status{Code: int32(codes.ResourceExhausted), Message: "disk full"},
+			}},
+			wantSent: []*cpb.DeployRequest{transfer(&cpb.ImageTransfer{Name: "img"}), content(data), end},
+			wantErr:  "disk full",
+		},
+		{
+			desc:    "deploy without content",
+			op:      containerz.NewDeployOperation().Name("img"),
+			wantErr: "no reader",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var dc *fakeDeployClient
+			var fakeClient internal.Clients
+			fakeClient.ContainerzClient = &fakeContainerzClient{DeployFn: func(ctx context.Context, _ ...grpc.CallOption) (cpb.Containerz_DeployClient, error) {
+				dc = newFakeDeployClient(ctx, tt.chunkSize, tt.final)
+				return dc, nil
+			}}
+
+			got, gotErr := tt.op.Execute(context.Background(), &fakeClient)
+			if (gotErr == nil) != (tt.wantErr == "") || (gotErr != nil && !strings.Contains(gotErr.Error(), tt.wantErr)) {
+				t.Errorf("Execute() got unexpected error %v want %s", gotErr, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Execute() got unexpected response diff (-want +got): %s", diff)
+			}
+			var gotSent []*cpb.DeployRequest
+			if dc != nil {
+				gotSent = dc.gotSent
+			}
+			if diff := cmp.Diff(tt.wantSent, gotSent, protocmp.Transform()); diff != "" {
+				t.Errorf("Execute() sent unexpected requests diff (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+type fakeListImageClient struct {
+	cpb.Containerz_ListImageClient
+	resp []*cpb.ListImageResponse
+	err  error
+}
+
+func (lc *fakeListImageClient) Recv() (*cpb.ListImageResponse, error) {
+	if len(lc.resp) == 0 {
+		if lc.err != nil {
+			return nil, lc.err
+		}
+		return nil, io.EOF
+	}
+	resp := lc.resp[0]
+	lc.resp = lc.resp[1:]
+	return resp, nil
+}
+
+func TestListImage(t *testing.T) {
+	images := []*cpb.ListImageResponse{{Id: "1", ImageName: "img", Tag: "v1"}, {Id: "2", ImageName: "img", Tag: "v2"}}
+	tests := []struct {
+		desc    string
+		op      *containerz.ListImageOperation
+		resp    []*cpb.ListImageResponse
+		err     error
+		wantReq *cpb.ListImageRequest
+		want    []*cpb.ListImageResponse
+		wantErr string
+	}{
+		{
+			desc:    "list images",
+			op:      containerz.NewListImageOperation().Limit(5).Filter("name", "img"),
+			resp:    images,
+			wantReq: &cpb.ListImageRequest{Limit: 5, Filter: []*cpb.ListImageRequest_Filter{{Key: "name", Value: []string{"img"}}}},
+			want:    images,
+		},
+		{
+			desc:    "list images error",
+			op:      containerz.NewListImageOperation(),
+			resp:    images,
+			err:     errors.New("ListImage operation error"),
+			wantReq: &cpb.ListImageRequest{},
+			wantErr: "ListImage operation error",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var gotReq *cpb.ListImageRequest
+			var fakeClient internal.Clients
+			fakeClient.ContainerzClient = &fakeContainerzClient{ListImageFn: func(_ context.Context, in *cpb.ListImageRequest, _ ...grpc.CallOption) (cpb.Containerz_ListImageClient, error) {
+				gotReq = in
+				return &fakeListImageClient{resp: append([]*cpb.ListImageResponse(nil), tt.resp...), err: tt.err}, nil
+			}}
+
+			got, gotErr := tt.op.Execute(context.Background(), &fakeClient)
+			if (gotErr == nil) != (tt.wantErr == "") || (gotErr != nil && !strings.Contains(gotErr.Error(), tt.wantErr)) {
+				t.Errorf("Execute() got unexpected error %v want %s", gotErr, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.wantReq, gotReq, protocmp.Transform()); diff != "" {
+				t.Errorf("Execute() sent unexpected request diff (-want +got): %s", diff)
+			}
+			if diff := cmp.Diff(tt.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Execute() got unexpected response diff (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+type fakeListContainerClient struct {
+	cpb.Containerz_ListContainerClient
+	resp []*cpb.ListContainerResponse
+}
+
+func (lc *fakeListContainerClient) Recv() (*cpb.ListContainerResponse, error) {
+	if len(lc.resp) == 0 {
+		return nil, io.EOF
+	}
+	resp := lc.resp[0]
+	lc.resp = lc.resp[1:]
+	return resp, nil
+}
+
+func TestListContainer(t *testing.T) {
+	containers := []*cpb.ListContainerResponse{{Id: "1", Name: "agent", ImageName: "img", Status: cpb.ListContainerResponse_RUNNING}}
+	var gotReq *cpb.ListContainerRequest
+	var fakeClient internal.Clients
+	fakeClient.ContainerzClient = &fakeContainerzClient{ListContainerFn: func(_ context.Context, in *cpb.ListContainerRequest, _ ...grpc.CallOption) (cpb.Containerz_ListContainerClient, error) {
+		gotReq = in
+		return &fakeListContainerClient{resp: containers}, nil
+	}}
+
+	got, err := containerz.NewListContainerOperation().All(true).Filter("name", "agent").Execute(context.Background(), &fakeClient)
+	if err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+	wantReq := &cpb.ListContainerRequest{All: true, Filter: []*cpb.ListContainerRequest_Filter{{Key: "name", Value: []string{"agent"}}}}
+	if diff := cmp.Diff(wantReq, gotReq, protocmp.Transform()); diff != "" {
+		t.Errorf("Execute() sent unexpected request diff (-want +got): %s", diff)
+	}
+	if diff := cmp.Diff(containers, got, protocmp.Transform()); diff != "" {
+		t.Errorf("Execute() got unexpected response diff (-want +got): %s", diff)
+	}
+}
+
+func TestRemoveImage(t *testing.T) {
+	var gotReq *cpb.RemoveImageRequest
+	var fakeClient internal.Clients
+	fakeClient.ContainerzClient = &fakeContainerzClient{RemoveImageFn: func(_ context.Context, in *cpb.RemoveImageRequest, _ ...grpc.CallOption) (*cpb.RemoveImageResponse, error) {
+		gotReq = in
+		return &cpb.RemoveImageResponse{Code: cpb.RemoveImageResponse_SUCCESS}, nil
+	}}
+
+	if _, err := containerz.NewRemoveImageOperation().Name("img").Tag("v1").Force(true).Execute(context.Background(), &fakeClient); err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+	wantReq := &cpb.RemoveImageRequest{Name: "img", Tag: "v1", Force: true}
+	if diff := cmp.Diff(wantReq, gotReq, protocmp.Transform()); diff != "" {
+		t.Errorf("Execute() sent unexpected request diff (-want +got): %s", diff)
+	}
+}
+
+func TestStartContainer(t *testing.T) {
+	tests := []struct {
+		desc    string
+		op      *containerz.StartContainerOperation
+		wantReq *cpb.StartContainerRequest
+		wantErr string
+	}{
+		{
+			desc: "start container",
+			op: containerz.NewStartContainerOperation().ImageName("img").Tag("v1").InstanceName("agent").Cmd("/agent").
+				Port(80, 8080).Environment(map[string]string{"LOG": "debug"}).Volume("data", "/data", true).
+				Network("host").Restart(cpb.StartContainerRequest_Restart_ALWAYS, 3),
+			wantReq: &cpb.StartContainerRequest{
+				ImageName:    "img",
+				Tag:          "v1",
+				InstanceName: "agent",
+				Cmd:          "/agent",
+				Ports:        []*cpb.StartContainerRequest_Port{{Internal: 80, External: 8080}},
+				Environment:  map[string]string{"LOG": "debug"},
+				Volumes:      []*cpb.Volume{{Name: "data", MountPoint: "/data", ReadOnly: true}},
+				Network:      "host",
+				Restart:      &cpb.StartContainerRequest_Restart{Policy: cpb.StartContainerRequest_Restart_ALWAYS, Attempts: 3},
+			},
+		},
+		{
+			desc:    "start container error",
+			op:      containerz.NewStartContainerOperation(),
+			wantReq: &cpb.StartContainerRequest{},
+			wantErr: "StartContainer operation error",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var gotReq *cpb.StartContainerRequest
+			var fakeClient internal.Clients
+			fakeClient.ContainerzClient = &fakeContainerzClient{StartContainerFn: func(_ context.Context, in *cpb.StartContainerRequest, _ ...grpc.CallOption) (*cpb.StartContainerResponse, error) {
+				gotReq = in
+				if tt.wantErr != "" {
+					return nil, errors.New(tt.wantErr)
+				}
+				return &cpb.StartContainerResponse{}, nil
+			}}
+
+			_, gotErr := tt.op.Execute(context.Background(), &fakeClient)
+			if (gotErr == nil) != (tt.wantErr == "") || (gotErr != nil && !strings.Contains(gotErr.Error(), tt.wantErr)) {
+				t.Errorf("Execute() got unexpected error %v want %s", gotErr, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.wantReq, gotReq, protocmp.Transform()); diff != "" {
+				t.Errorf("Execute() sent unexpected request diff (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestStopContainer(t *testing.T) {
+	var gotReq *cpb.StopContainerRequest
+	var fakeClient internal.Clients
+	fakeClient.ContainerzClient = &fakeContainerzClient{StopContainerFn: func(_ context.Context, in *cpb.StopContainerRequest, _ ...grpc.CallOption) (*cpb.StopContainerResponse, error) {
+		gotReq = in
+		return &cpb.StopContainerResponse{Code: cpb.StopContainerResponse_SUCCESS}, nil
+	}}
+
+	if _, err := containerz.NewStopContainerOperation().InstanceName("agent").Force(true).Execute(context.Background(), &fakeClient); err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+	wantReq := &cpb.StopContainerRequest{InstanceName: "agent", Force: true}
+	if diff := cmp.Diff(wantReq, gotReq, protocmp.Transform()); diff != "" {
+		t.Errorf("Execute() sent unexpected request diff (-want +got): %s", diff)
+	}
+}
+
+func TestUpdateContainer(t *testing.T) {
+	var gotReq *cpb.UpdateContainerRequest
+	var fakeClient internal.Clients
+	fakeClient.ContainerzClient = &fakeContainerzClient{UpdateContainerFn: func(_ context.Context, in *cpb.UpdateContainerRequest, _ ...grpc.CallOption) (*cpb.UpdateContainerResponse, error) {
+		gotReq = in
+		return &cpb.UpdateContainerResponse{}, nil
+	}}
+
+	op := containerz.NewUpdateContainerOperation().InstanceName("agent").ImageName("img").ImageTag("v2").Async(true).
+		Params(containerz.NewStartContainerOperation().ImageName("img").Tag("v2").InstanceName("agent"))
+	if _, err := op.Execute(context.Background(), &fakeClient); err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+	wantReq := &cpb.UpdateContainerRequest{
+		InstanceName: "agent",
+		ImageName:    "img",
+		ImageTag:     "v2",
+		Async:        true,
+		Params:       &cpb.StartContainerRequest{ImageName: "img", Tag: "v2", InstanceName: "agent"},
+	}
+	if diff := cmp.Diff(wantReq, gotReq, protocmp.Transform()); diff != "" {
+		t.Errorf("Execute() sent unexpected request diff (-want +got): %s", diff)
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package containerz
+
+import (
+	"context"
+	"errors"
+	"io"
+	"iter"
+	"strings"
+
+	cpb "github.com/openconfig/gnoi/containerz"
+	"github.com/openconfig/gnoigo/internal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// LogOperation represents the parameters of a Log operation.
+type LogOperation struct {
+	req *cpb.LogRequest
+}
+
+// NewLogOperation creates an empty LogOperation.
+func NewLogOperation() *LogOperation {
+	return &LogOperation{req: &cpb.LogRequest{}}
+}
+
+// Instance specifies the name of the container instance to get the logs of.
+func (l *LogOperation) Instance(name string) *LogOperation {
+	l.req.InstanceName = name
+	return l
+}
+
+// Follow specifies whether the stream remains open for new log lines until
+// the context passed to Execute is cancelled.
+func (l *LogOperation) Follow(follow bool) *LogOperation {
+	l.req.Follow = follow
+	return l
+}
+
+// Execute performs the Log operation. The returned LogStream reads the log
+// lines as they arrive and stops when the context is cancelled or the stream
+// is closed.
+func (l *LogOperation) Execute(ctx context.Context, c *internal.Clients) (*LogStream, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	lc, err := c.Containerz().Log(ctx, l.req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return &LogStream{lc: lc, cancel: cancel}, nil
+}
+
+// Request returns the request proto of the Log operation.
+func (l *LogOperation) Request() proto.Message {
+	return l.req
+}
+
+// LogStream is a stream of container log lines.
+type LogStream struct {
+	lc      cpb.Containerz_LogClient
+	cancel  context.CancelFunc
+	pending string
+}
+
+// Close cancels the stream and releases its resources. It must be called
+// once the stream is no longer read, unless the stream ended.
+func (s *LogStream) Close() error {
+	s.cancel()
+	return nil
+}
+
+// Next returns the next log line, without a trailing newline.
+// It returns io.EOF once the target has sent all the logs.
+func (s *LogStream) Next() (string, error) {
+	resp, err := s.lc.Recv()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(resp.GetMsg(), "\n"), nil
+}
+
+// Lines returns an iterator over the log lines. Iteration stops at the end of
+// the stream; any other error is yielded with an empty line.
+func (s *LogStream) Lines() iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		for {
+			line, err := s.Next()
+			if err == io.EOF {
+				return
+			}
+			if !yield(line, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Read implements io.Reader over the newline terminated log lines.
+func (s *LogStream) Read(p []byte) (int, error) {
+	for s.pending == "" {
+		line, err := s.Next()
+		if err != nil {
+			return 0, err
+		}
+		s.pending = line + "\n"
+	}
+	n := copy(p, s.pending)
+	s.pending = s.pending[n:]
+	return n, nil
+}
+
+// Tail reads the stream until it ends and returns at most the last n lines.
+// Cancelling the context passed to Execute or closing the stream ends the
+// stream, which allows taking a snapshot of a followed log.
+func (s *LogStream) Tail(n int) ([]string, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	ring := make([]string, 0, n)
+	start := 0
+	for {
+		line, err := s.Next()
+		if err == io.EOF || isStreamEnd(err) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(ring) < n {
+			ring = append(ring, line)
+			continue
+		}
+		ring[start] = line
+		start = (start + 1) % n
+	}
+	return append(ring[start:], ring[:start]...), nil
+}
+
+func isStreamEnd(err error) bool {
+	return internal.IsCanceled(err) || errors.Is(err, context.DeadlineExceeded) || status.Code(err) == codes.DeadlineExceeded
+}
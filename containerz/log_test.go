@@ -0,0 +1,180 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package containerz_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	cpb "github.com/openconfig/gnoi/containerz"
+	"github.com/openconfig/gnoigo/containerz"
+	"github.com/openconfig/gnoigo/internal"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+// fakeLogClient returns the queued lines and then, if follow is set, blocks
+// until the context is cancelled.
+type fakeLogClient struct {
+	cpb.Containerz_LogClient
+	ctx    context.Context
+	lines  []string
+	follow bool
+	err    error
+}
+
+func (lc *fakeLogClient) Recv() (*cpb.LogResponse, error) {
+	if len(lc.lines) > 0 {
+		line := lc.lines[0]
+		lc.lines = lc.lines[1:]
+		return &cpb.LogResponse{Msg: line}, nil
+	}
+	if lc.err != nil {
+		return nil, lc.err
+	}
+	if lc.follow {
+		<-lc.ctx.Done()
+		return nil, lc.ctx.Err()
+	}
+	return nil, io.EOF
+}
+
+func newLogClients(t *testing.T, lines []string, streamErr error) (*internal.Clients, **cpb.LogRequest) {
+	t.Helper()
+	gotReq := new(*cpb.LogRequest)
+	var fakeClient internal.Clients
+	fakeClient.ContainerzClient = &fakeContainerzClient{LogFn: func(ctx context.Context, in *cpb.LogRequest, _ ...grpc.CallOption) (cpb.Containerz_LogClient, error) {
+		*gotReq = in
+		return &fakeLogClient{ctx: ctx, lines: append([]string(nil), lines...), follow: in.GetFollow(), err: streamErr}, nil
+	}}
+	return &fakeClient, gotReq
+}
+
+func TestLogLines(t *testing.T) {
+	c, gotReq := newLogClients(t, []string{"one\n", "two\n", "three"}, nil)
+	s, err := containerz.NewLogOperation().Instance("agent").Execute(context.Background(), c)
+	if err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+	var got []string
+	for line, err := range s.Lines() {
+		if err != nil {
+			t.Fatalf("Lines() got unexpected error: %v", err)
+		}
+		got = append(got, line)
+	}
+	if diff := cmp.Diff([]string{"one", "two", "three"}, got); diff != "" {
+		t.Errorf("Lines() got unexpected lines diff (-want +got): %s", diff)
+	}
+	if diff := cmp.Diff(&cpb.LogRequest{InstanceName: "agent"}, *gotReq, protocmp.Transform()); diff != "" {
+		t.Errorf("Execute() sent unexpected request diff (-want +got): %s", diff)
+	}
+}
+
+func TestLogLinesError(t *testing.T) {
+	c, _ := newLogClients(t, []string{"one"}, errors.New("stream broken"))
+	s, err := containerz.NewLogOperation().Instance("agent").Execute(context.Background(), c)
+	if err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+	var gotErr error
+	for _, err := range s.Lines() {
+		gotErr = err
+	}
+	if gotErr == nil || gotErr.Error() != "stream broken" {
+		t.Errorf("Lines() got error %v, want stream broken", gotErr)
+	}
+}
+
+func TestLogReader(t *testing.T) {
+	c, _ := newLogClients(t, []string{"one\n", "two"}, nil)
+	s, err := containerz.NewLogOperation().Instance("agent").Execute(context.Background(), c)
+	if err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+	got, err := io.ReadAll(s)
+	if err != nil {
+		t.Fatalf("ReadAll() failed: %v", err)
+	}
+	if want := "one\ntwo\n"; string(got) != want {
+		t.Errorf("ReadAll() got %q, want %q", got, want)
+	}
+}
+
+func TestLogTail(t *testing.T) {
+	lines := []string{"1", "2", "3", "4", "5"}
+	tests := []struct {
+		desc   string
+		follow bool
+		close  bool
+		n      int
+		want   []string
+	}{
+		{
+			desc: "fewer lines than requested",
+			n:    10,
+			want: lines,
+		},
+		{
+			desc: "last lines",
+			n:    2,
+			want: []string{"4", "5"},
+		},
+		{
+			desc:   "follow until cancelled",
+			follow: true,
+			n:      3,
+			want:   []string{"3", "4", "5"},
+		},
+		{
+			desc:   "follow until closed",
+			follow: true,
+			close:  true,
+			n:      3,
+			want:   []string{"3", "4", "5"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			c, gotReq := newLogClients(t, lines, nil)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			s, err := containerz.NewLogOperation().Instance("agent").Follow(tt.follow).Execute(ctx, c)
+			if err != nil {
+				t.Fatalf("Execute() failed: %v", err)
+			}
+			// The fake only blocks once all queued lines have been read.
+			switch {
+			case tt.close:
+				s.Close()
+			case tt.follow:
+				cancel()
+			}
+			got, err := s.Tail(tt.n)
+			if err != nil {
+				t.Fatalf("Tail() failed: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Tail() got unexpected lines diff (-want +got): %s", diff)
+			}
+			if (*gotReq).GetFollow() != tt.follow {
+				t.Errorf("Execute() sent follow %v, want %v", (*gotReq).GetFollow(), tt.follow)
+			}
+		})
+	}
+}
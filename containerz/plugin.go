@@ -0,0 +1,159 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package containerz
+
+import (
+	"context"
+
+	cpb "github.com/openconfig/gnoi/containerz"
+	"github.com/openconfig/gnoigo/internal"
+	"google.golang.org/protobuf/proto"
+)
+
+// StartPluginOperation represents the parameters of a StartPlugin operation.
+type StartPluginOperation struct {
+	req *cpb.StartPluginRequest
+}
+
+// NewStartPluginOperation creates an empty StartPluginOperation.
+func NewStartPluginOperation() *StartPluginOperation {
+	return &StartPluginOperation{req: &cpb.StartPluginRequest{}}
+}
+
+// Name specifies the name of the plugin, as given when it was deployed.
+func (s *StartPluginOperation) Name(name string) *StartPluginOperation {
+	s.req.Name = name
+	return s
+}
+
+// InstanceName specifies the name of the started plugin.
+func (s *StartPluginOperation) InstanceName(name string) *StartPluginOperation {
+	s.req.InstanceName = name
+	return s
+}
+
+// Config specifies the JSON configuration of the plugin.
+func (s *StartPluginOperation) Config(config string) *StartPluginOperation {
+	s.req.Config = config
+	return s
+}
+
+// Execute performs the StartPlugin operation.
+func (s *StartPluginOperation) Execute(ctx context.Context, c *internal.Clients) (*cpb.StartPluginResponse, error) {
+	return c.Containerz().StartPlugin(ctx, s.req)
+}
+
+// Request returns the request proto of the StartPlugin operation.
+func (s *StartPluginOperation) Request() proto.Message {
+	return s.req
+}
+
+// StopPluginOperation represents the parameters of a StopPlugin operation.
+type StopPluginOperation struct {
+	req *cpb.StopPluginRequest
+}
+
+// NewStopPluginOperation creates an empty StopPluginOperation.
+func NewStopPluginOperation() *StopPluginOperation {
+	return &StopPluginOperation{req: &cpb.StopPluginRequest{}}
+}
+
+// InstanceName specifies the name of the plugin instance to stop.
+func (s *StopPluginOperation) InstanceName(name string) *StopPluginOperation {
+	s.req.InstanceName = name
+	return s
+}
+
+// Execute performs the StopPlugin operation.
+func (s *StopPluginOperation) Execute(ctx context.Context, c *internal.Clients) (*cpb.StopPluginResponse, error) {
+	return c.Containerz().StopPlugin(ctx, s.req)
+}
+
+// Request returns the request proto of the StopPlugin operation.
+func (s *StopPluginOperation) Request() proto.Message {
+	return s.req
+}
+
+// Risk returns the risk class of the StopPlugin operation.
+func (s *StopPluginOperation) Risk() internal.Risk {
+	return internal.RiskDisruptive
+}
+
+// ListPluginsOperation represents the parameters of a ListPlugins operation.
+type ListPluginsOperation struct {
+	req *cpb.ListPluginsRequest
+}
+
+// NewListPluginsOperation creates an empty ListPluginsOperation that lists
+// all the plugins.
+func NewListPluginsOperation() *ListPluginsOperation {
+	return &ListPluginsOperation{req: &cpb.ListPluginsRequest{}}
+}
+
+// InstanceName limits the result to the plugin with the given instance name.
+func (l *ListPluginsOperation) InstanceName(name string) *ListPluginsOperation {
+	l.req.InstanceName = name
+	return l
+}
+
+// Execute performs the ListPlugins operation.
+func (l *ListPluginsOperation) Execute(ctx context.Context, c *internal.Clients) ([]*cpb.Plugin, error) {
+	resp, err := c.Containerz().ListPlugins(ctx, l.req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.GetPlugins(), nil
+}
+
+// Request returns the request proto of the ListPlugins operation.
+func (l *ListPluginsOperation) Request() proto.Message {
+	return l.req
+}
+
+// Idempotent reports that the ListPlugins operation can safely be retried.
+func (l *ListPluginsOperation) Idempotent() bool {
+	return true
+}
+
+// RemovePluginOperation represents the parameters of a RemovePlugin operation.
+type RemovePluginOperation struct {
+	req *cpb.RemovePluginRequest
+}
+
+// NewRemovePluginOperation creates an empty RemovePluginOperation.
+func NewRemovePluginOperation() *RemovePluginOperation {
+	return &RemovePluginOperation{req: &cpb.RemovePluginRequest{}}
+}
+
+// InstanceName specifies the name of the plugin instance to remove.
+func (r *RemovePluginOperation) InstanceName(name string) *RemovePluginOperation {
+	r.req.InstanceName = name
+	return r
+}
+
+// Execute performs the RemovePlugin operation.
+func (r *RemovePluginOperation) Execute(ctx context.Context, c *internal.Clients) (*cpb.RemovePluginResponse, error) {
+	return c.Containerz().RemovePlugin(ctx, r.req)
+}
+
+// Request returns the request proto of the RemovePlugin operation.
+func (r *RemovePluginOperation) Request() proto.Message {
+	return r.req
+}
+
+// Risk returns the risk class of the RemovePlugin operation.
+func (r *RemovePluginOperation) Risk() internal.Risk {
+	return internal.RiskDestructive
+}
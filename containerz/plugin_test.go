@@ -0,0 +1,125 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package containerz_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	cpb "github.com/openconfig/gnoi/containerz"
+	"github.com/openconfig/gnoigo/containerz"
+	"github.com/openconfig/gnoigo/internal"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+func TestStartPlugin(t *testing.T) {
+	var gotReq *cpb.StartPluginRequest
+	var fakeClient internal.Clients
+	fakeClient.ContainerzClient = &fakeContainerzClient{StartPluginFn: func(_ context.Context, in *cpb.StartPluginRequest, _ ...grpc.CallOption) (*cpb.StartPluginResponse, error) {
+		gotReq = in
+		return &cpb.StartPluginResponse{InstanceName: in.GetInstanceName()}, nil
+	}}
+
+	got, err := containerz.NewStartPluginOperation().Name("vol-driver").InstanceName("vol-driver-1").Config(`{"root":"/data"}`).Execute(context.Background(), &fakeClient)
+	if err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+	wantReq := &cpb.StartPluginRequest{Name: "vol-driver", InstanceName: "vol-driver-1", Config: `{"root":"/data"}`}
+	if diff := cmp.Diff(wantReq, gotReq, protocmp.Transform()); diff != "" {
+		t.Errorf("Execute() sent unexpected request diff (-want +got): %s", diff)
+	}
+	if got.GetInstanceName() != "vol-driver-1" {
+		t.Errorf("Execute() got instance %q, want %q", got.GetInstanceName(), "vol-driver-1")
+	}
+}
+
+func TestStopPlugin(t *testing.T) {
+	var gotReq *cpb.StopPluginRequest
+	var fakeClient internal.Clients
+	fakeClient.ContainerzClient = &fakeContainerzClient{StopPluginFn: func(_ context.Context, in *cpb.StopPluginRequest, _ ...grpc.CallOption) (*cpb.StopPluginResponse, error) {
+		gotReq = in
+		return &cpb.StopPluginResponse{}, nil
+	}}
+
+	if _, err := containerz.NewStopPluginOperation().InstanceName("vol-driver-1").Execute(context.Background(), &fakeClient); err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+	if diff := cmp.Diff(&cpb.StopPluginRequest{InstanceName: "vol-driver-1"}, gotReq, protocmp.Transform()); diff != "" {
+		t.Errorf("Execute() sent unexpected request diff (-want +got): %s", diff)
+	}
+}
+
+func TestListPlugins(t *testing.T) {
+	plugins := []*cpb.Plugin{{Id: "1", InstanceName: "vol-driver-1", Config: "{}"}}
+	tests := []struct {
+		desc    string
+		err     error
+		want    []*cpb.Plugin
+		wantErr bool
+	}{
+		{
+			desc: "list plugins",
+			want: plugins,
+		},
+		{
+			desc:    "list plugins error",
+			err:     errors.New("ListPlugins operation error"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var gotReq *cpb.ListPluginsRequest
+			var fakeClient internal.Clients
+			fakeClient.ContainerzClient = &fakeContainerzClient{ListPluginsFn: func(_ context.Context, in *cpb.ListPluginsRequest, _ ...grpc.CallOption) (*cpb.ListPluginsResponse, error) {
+				gotReq = in
+				if tt.err != nil {
+					return nil, tt.err
+				}
+				return &cpb.ListPluginsResponse{Plugins: plugins}, nil
+			}}
+
+			got, err := containerz.NewListPluginsOperation().InstanceName("vol-driver-1").Execute(context.Background(), &fakeClient)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() got error %v, want error %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(&cpb.ListPluginsRequest{InstanceName: "vol-driver-1"}, gotReq, protocmp.Transform()); diff != "" {
+				t.Errorf("Execute() sent unexpected request diff (-want +got): %s", diff)
+			}
+			if diff := cmp.Diff(tt.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Execute() got unexpected response diff (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestRemovePlugin(t *testing.T) {
+	var gotReq *cpb.RemovePluginRequest
+	var fakeClient internal.Clients
+	fakeClient.ContainerzClient = &fakeContainerzClient{RemovePluginFn: func(_ context.Context, in *cpb.RemovePluginRequest, _ ...grpc.CallOption) (*cpb.RemovePluginResponse, error) {
+		gotReq = in
+		return &cpb.RemovePluginResponse{}, nil
+	}}
+
+	if _, err := containerz.NewRemovePluginOperation().InstanceName("vol-driver-1").Execute(context.Background(), &fakeClient); err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+	if diff := cmp.Diff(&cpb.RemovePluginRequest{InstanceName: "vol-driver-1"}, gotReq, protocmp.Transform()); diff != "" {
+		t.Errorf("Execute() sent unexpected request diff (-want +got): %s", diff)
+	}
+}
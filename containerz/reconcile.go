@@ -0,0 +1,231 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package containerz
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	cpb "github.com/openconfig/gnoi/containerz"
+	"github.com/openconfig/gnoigo/internal"
+	"google.golang.org/protobuf/proto"
+)
+
+// SpecHashLabel is the container label holding the hash of the ContainerSpec
+// the container was started from. It is used to detect drift.
+const SpecHashLabel = "gnoigo.spec-hash"
+
+// ContainerSpec describes the desired state of a container on the target.
+type ContainerSpec struct {
+	ImageName    string
+	ImageTag     string
+	SourceFile   string
+	InstanceName string
+	Cmd          string
+	// Ports maps internal container ports to external ports.
+	Ports       map[uint32]uint32
+	Environment map[string]string
+	Volumes     []*cpb.Volume
+	Labels      map[string]string
+}
+
+// startOperation returns the StartContainerOperation for the spec, labelled
+// with the hash of the spec.
+func (s *ContainerSpec) startOperation() (*StartContainerOperation, string, error) {
+	op := NewStartContainerOperation().ImageName(s.ImageName).Tag(s.ImageTag).InstanceName(s.InstanceName).Cmd(s.Cmd).Environment(s.Environment)
+	for _, internalPort := range slices.Sorted(maps.Keys(s.Ports)) {
+		op.Port(internalPort, s.Ports[internalPort])
+	}
+	for _, v := range s.Volumes {
+		op.Volume(v.GetName(), v.GetMountPoint(), v.GetReadOnly())
+	}
+	op.Labels(maps.Clone(s.Labels))
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(op.req)
+	if err != nil {
+		return nil, "", err
+	}
+	sum := sha256.Sum256(b)
+	hash := hex.EncodeToString(sum[:])
+	if op.req.Labels == nil {
+		op.req.Labels = map[string]string{}
+	}
+	op.req.Labels[SpecHashLabel] = hash
+	return op, hash, nil
+}
+
+// Action is a step taken to reconcile a container with its spec.
+type Action string
+
+const (
+	// ActionDeployImage deploys the missing image.
+	ActionDeployImage Action = "deploy-image"
+	// ActionUpdateContainer updates a container whose spec drifted. The
+	// target replaces the container and starts the new one, restoring the
+	// previous container if the update fails.
+	ActionUpdateContainer Action = "update-container"
+	// ActionStartContainer starts a missing or stopped container.
+	ActionStartContainer Action = "start-container"
+)
+
+// Step is a single action of a Plan.
+type Step struct {
+	Action Action
+	Reason string
+}
+
+// Plan describes the steps needed to reconcile a container with its spec.
+type Plan struct {
+	Steps []Step
+	// Applied is true if the steps were performed on the target, false if
+	// the plan was only computed.
+	Applied bool
+}
+
+// String returns a human-readable description of the plan.
+func (p *Plan) String() string {
+	if len(p.Steps) == 0 {
+		return "container is up to date"
+	}
+	var b strings.Builder
+	for i, s := range p.Steps {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "%d. %s: %s", i+1, s.Action, s.Reason)
+	}
+	return b.String()
+}
+
+// ReconcileOperation represents the parameters of a reconciliation of a
+// container on the target with a ContainerSpec.
+type ReconcileOperation struct {
+	spec   ContainerSpec
+	dryRun bool
+}
+
+// NewReconcileOperation creates an empty ReconcileOperation.
+func NewReconcileOperation() *ReconcileOperation {
+	return &ReconcileOperation{}
+}
+
+// Spec specifies the desired state of the container.
+func (r *ReconcileOperation) Spec(spec ContainerSpec) *ReconcileOperation {
+	r.spec = spec
+	return r
+}
+
+// DryRun specifies whether to only compute the plan without applying it.
+func (r *ReconcileOperation) DryRun(dryRun bool) *ReconcileOperation {
+	r.dryRun = dryRun
+	return r
+}
+
+// Execute performs the Reconcile operation and returns the plan that was
+// applied, or that would be applied in dry-run mode.
+func (r *ReconcileOperation) Execute(ctx context.Context, c *internal.Clients) (*Plan, error) {
+	startOp, hash, err := r.spec.startOperation()
+	if err != nil {
+		return nil, err
+	}
+	plan, err := r.plan(ctx, c, hash)
+	if err != nil {
+		return nil, err
+	}
+	if r.dryRun {
+		return plan, nil
+	}
+	for _, s := range plan.Steps {
+		if err := r.apply(ctx, c, s.Action, startOp); err != nil {
+			return nil, fmt.Errorf("%s for %q failed: %w", s.Action, r.spec.InstanceName, err)
+		}
+	}
+	plan.Applied = true
+	return plan, nil
+}
+
+// Risk returns the risk class of the Reconcile operation, which is only low in
+// dry-run mode since applying a plan may stop or update the container.
+func (r *ReconcileOperation) Risk() internal.Risk {
+	if r.dryRun {
+		return internal.RiskLow
+	}
+	return internal.RiskDisruptive
+}
+
+func (r *ReconcileOperation) plan(ctx context.Context, c *internal.Clients, hash string) (*Plan, error) {
+	plan := &Plan{}
+	images, err := NewListImageOperation().Execute(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.ContainsFunc(images, func(img *cpb.ListImageResponse) bool {
+		return img.GetImageName() == r.spec.ImageName && img.GetTag() == r.spec.ImageTag
+	}) {
+		plan.Steps = append(plan.Steps, Step{ActionDeployImage, fmt.Sprintf("image %s:%s is missing", r.spec.ImageName, r.spec.ImageTag)})
+	}
+
+	containers, err := NewListContainerOperation().All(true).Execute(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	i := slices.IndexFunc(containers, func(ct *cpb.ListContainerResponse) bool {
+		return ct.GetName() == r.spec.InstanceName
+	})
+	if i < 0 || containers[i].GetStatus() == cpb.ListContainerResponse_NOT_FOUND {
+		plan.Steps = append(plan.Steps, Step{ActionStartContainer, fmt.Sprintf("container %q does not exist", r.spec.InstanceName)})
+		return plan, nil
+	}
+	if containers[i].GetLabels()[SpecHashLabel] != hash {
+		plan.Steps = append(plan.Steps, Step{ActionUpdateContainer, fmt.Sprintf("container %q spec drifted", r.spec.InstanceName)})
+		return plan, nil
+	}
+	if containers[i].GetStatus() != cpb.ListContainerResponse_RUNNING {
+		plan.Steps = append(plan.Steps, Step{ActionStartContainer, fmt.Sprintf("container %q is %v", r.spec.InstanceName, containers[i].GetStatus())})
+	}
+	return plan, nil
+}
+
+func (r *ReconcileOperation) apply(ctx context.Context, c *internal.Clients, a Action, startOp *StartContainerOperation) error {
+	switch a {
+	case ActionDeployImage:
+		_, err := NewDeployOperation().Name(r.spec.ImageName).Tag(r.spec.ImageTag).SourceFile(r.spec.SourceFile).Execute(ctx, c)
+		return err
+	case ActionUpdateContainer:
+		resp, err := NewUpdateContainerOperation().InstanceName(r.spec.InstanceName).ImageName(r.spec.ImageName).ImageTag(r.spec.ImageTag).Params(startOp).Execute(ctx, c)
+		if err != nil {
+			return err
+		}
+		if ue := resp.GetUpdateError(); ue != nil {
+			return fmt.Errorf("update error %v: %s", ue.GetErrorCode(), ue.GetDetails())
+		}
+		return nil
+	case ActionStartContainer:
+		resp, err := startOp.Execute(ctx, c)
+		if err != nil {
+			return err
+		}
+		if se := resp.GetStartError(); se != nil {
+			return fmt.Errorf("start error %v: %s", se.GetErrorCode(), se.GetDetails())
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown action %q", a)
+	}
+}
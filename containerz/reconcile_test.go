@@ -0,0 +1,192 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package containerz_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	cpb "github.com/openconfig/gnoi/containerz"
+	"github.com/openconfig/gnoigo/containerz"
+	"github.com/openconfig/gnoigo/internal"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+// fakeReconcileTarget models the images and containers of a target and
+// records the mutating RPCs issued by a reconciliation.
+type fakeReconcileTarget struct {
+	images     []*cpb.ListImageResponse
+	containers []*cpb.ListContainerResponse
+	calls      []string
+	startReq   *cpb.StartContainerRequest
+	updateReq  *cpb.UpdateContainerRequest
+}
+
+// run sets the container to running with the parameters of req.
+func (f *fakeReconcileTarget) run(req *cpb.StartContainerRequest) {
+	ct := &cpb.ListContainerResponse{
+		Name:      req.GetInstanceName(),
+		ImageName: req.GetImageName(),
+		Status:    cpb.ListContainerResponse_RUNNING,
+		Labels:    req.GetLabels(),
+	}
+	for i, c := range f.containers {
+		if c.GetName() == ct.GetName() {
+			f.containers[i] = ct
+			return
+		}
+	}
+	f.containers = append(f.containers, ct)
+}
+
+func (f *fakeReconcileTarget) clients() *internal.Clients {
+	return &internal.Clients{ContainerzClient: &fakeContainerzClient{
+		ListImageFn: func(context.Context, *cpb.ListImageRequest, ...grpc.CallOption) (cpb.Containerz_ListImageClient, error) {
+			return &fakeListImageClient{resp: append([]*cpb.ListImageResponse(nil), f.images...)}, nil
+		},
+		ListContainerFn: func(context.Context, *cpb.ListContainerRequest, ...grpc.CallOption) (cpb.Containerz_ListContainerClient, error) {
+			return &fakeListContainerClient{resp: append([]*cpb.ListContainerResponse(nil), f.containers...)}, nil
+		},
+		StartContainerFn: func(_ context.Context, in *cpb.StartContainerRequest, _ ...grpc.CallOption) (*cpb.StartContainerResponse, error) {
+			f.calls = append(f.calls, "start")
+			f.startReq = in
+			f.run(in)
+			return &cpb.StartContainerResponse{Response: &cpb.StartContainerResponse_StartOk{StartOk: &cpb.StartOK{InstanceName: in.GetInstanceName()}}}, nil
+		},
+		UpdateContainerFn: func(_ context.Context, in *cpb.UpdateContainerRequest, _ ...grpc.CallOption) (*cpb.UpdateContainerResponse, error) {
+			f.calls = append(f.calls, "update")
+			f.updateReq = in
+			f.run(in.GetParams())
+			return &cpb.UpdateContainerResponse{Response: &cpb.UpdateContainerResponse_UpdateOk{UpdateOk: &cpb.UpdateOK{}}}, nil
+		},
+	}}
+}
+
+func TestReconcile(t *testing.T) {
+	spec := containerz.ContainerSpec{
+		ImageName:    "img",
+		ImageTag:     "v1",
+		InstanceName: "agent",
+		Ports:        map[uint32]uint32{80: 8080, 443: 8443},
+		Environment:  map[string]string{"LEVEL": "debug"},
+	}
+	images := []*cpb.ListImageResponse{{ImageName: "img", Tag: "v1"}}
+
+	// Start the container once to learn the labels a reconciled container has.
+	initial := &fakeReconcileTarget{images: images}
+	if _, err := containerz.NewReconcileOperation().Spec(spec).Execute(context.Background(), initial.clients()); err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+	labels := initial.startReq.GetLabels()
+	if labels[containerz.SpecHashLabel] == "" {
+		t.Fatalf("Execute() started container without %s label: %v", containerz.SpecHashLabel, labels)
+	}
+
+	tests := []struct {
+		desc        string
+		containers  []*cpb.ListContainerResponse
+		images      []*cpb.ListImageResponse
+		dryRun      bool
+		wantActions []containerz.Action
+		wantCalls   []string
+	}{
+		{
+			desc:        "container missing",
+			images:      images,
+			wantActions: []containerz.Action{containerz.ActionStartContainer},
+			wantCalls:   []string{"start"},
+		},
+		{
+			desc:       "container up to date",
+			images:     images,
+			containers: []*cpb.ListContainerResponse{{Name: "agent", ImageName: "img", Status: cpb.ListContainerResponse_RUNNING, Labels: labels}},
+		},
+		{
+			desc:        "container stopped",
+			images:      images,
+			containers:  []*cpb.ListContainerResponse{{Name: "agent", ImageName: "img", Status: cpb.ListContainerResponse_STOPPED, Labels: labels}},
+			wantActions: []containerz.Action{containerz.ActionStartContainer},
+			wantCalls:   []string{"start"},
+		},
+		{
+			desc:        "running container drifted",
+			images:      images,
+			containers:  []*cpb.ListContainerResponse{{Name: "agent", ImageName: "img", Status: cpb.ListContainerResponse_RUNNING}},
+			wantActions: []containerz.Action{containerz.ActionUpdateContainer},
+			wantCalls:   []string{"update"},
+		},
+		{
+			desc:        "stopped container drifted",
+			images:      images,
+			containers:  []*cpb.ListContainerResponse{{Name: "agent", ImageName: "img", Status: cpb.ListContainerResponse_STOPPED, Labels: map[string]string{containerz.SpecHashLabel: "old"}}},
+			wantActions: []containerz.Action{containerz.ActionUpdateContainer},
+			wantCalls:   []string{"update"},
+		},
+		{
+			desc:        "dry run",
+			containers:  []*cpb.ListContainerResponse{{Name: "agent", ImageName: "img", Status: cpb.ListContainerResponse_RUNNING}},
+			dryRun:      true,
+			wantActions: []containerz.Action{containerz.ActionDeployImage, containerz.ActionUpdateContainer},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			target := &fakeReconcileTarget{images: tt.images, containers: tt.containers}
+			plan, err := containerz.NewReconcileOperation().Spec(spec).DryRun(tt.dryRun).Execute(context.Background(), target.clients())
+			if err != nil {
+				t.Fatalf("Execute() failed: %v", err)
+			}
+			var gotActions []containerz.Action
+			for _, s := range plan.Steps {
+				gotActions = append(gotActions, s.Action)
+			}
+			if diff := cmp.Diff(tt.wantActions, gotActions); diff != "" {
+				t.Errorf("Execute() got unexpected plan diff (-want +got): %s", diff)
+			}
+			if diff := cmp.Diff(tt.wantCalls, target.calls); diff != "" {
+				t.Errorf("Execute() issued unexpected RPCs diff (-want +got): %s", diff)
+			}
+			if plan.Applied == tt.dryRun {
+				t.Errorf("Execute() got Applied %v, want %v", plan.Applied, !tt.dryRun)
+			}
+			if target.updateReq != nil && target.updateReq.GetParams().GetLabels()[containerz.SpecHashLabel] != labels[containerz.SpecHashLabel] {
+				t.Errorf("Execute() updated container with labels %v, want %v", target.updateReq.GetParams().GetLabels(), labels)
+			}
+			if tt.dryRun {
+				return
+			}
+			want := []*cpb.ListContainerResponse{{Name: "agent", ImageName: "img", Status: cpb.ListContainerResponse_RUNNING, Labels: labels}}
+			if diff := cmp.Diff(want, target.containers, protocmp.Transform()); diff != "" {
+				t.Errorf("Execute() left containers diff (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestReconcileError(t *testing.T) {
+	target := &fakeReconcileTarget{images: []*cpb.ListImageResponse{{ImageName: "img", Tag: "v1"}}}
+	clients := target.clients()
+	clients.ContainerzClient.(*fakeContainerzClient).StartContainerFn = func(context.Context, *cpb.StartContainerRequest, ...grpc.CallOption) (*cpb.StartContainerResponse, error) {
+		return &cpb.StartContainerResponse{Response: &cpb.StartContainerResponse_StartError{StartError: &cpb.StartError{Details: "port in use"}}}, nil
+	}
+	spec := containerz.ContainerSpec{ImageName: "img", ImageTag: "v1", InstanceName: "agent"}
+	_, err := containerz.NewReconcileOperation().Spec(spec).Execute(context.Background(), clients)
+	if err == nil || !strings.Contains(err.Error(), "port in use") {
+		t.Errorf("Execute() got error %v, want error containing %q", err, "port in use")
+	}
+}
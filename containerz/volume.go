@@ -0,0 +1,161 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package containerz
+
+import (
+	"context"
+	"io"
+
+	cpb "github.com/openconfig/gnoi/containerz"
+	"github.com/openconfig/gnoigo/internal"
+	"google.golang.org/protobuf/proto"
+)
+
+// CreateVolumeOperation represents the parameters of a CreateVolume operation.
+type CreateVolumeOperation struct {
+	req *cpb.CreateVolumeRequest
+}
+
+// NewCreateVolumeOperation creates an empty CreateVolumeOperation.
+func NewCreateVolumeOperation() *CreateVolumeOperation {
+	return &CreateVolumeOperation{req: &cpb.CreateVolumeRequest{}}
+}
+
+// Name specifies the name of the volume. If unset, the target allocates one.
+func (c *CreateVolumeOperation) Name(name string) *CreateVolumeOperation {
+	c.req.Name = name
+	return c
+}
+
+// Labels specifies the labels to apply to the volume.
+func (c *CreateVolumeOperation) Labels(labels map[string]string) *CreateVolumeOperation {
+	c.req.Labels = labels
+	return c
+}
+
+// LocalMount specifies that the volume uses the local mount(8) driver, with
+// the given mount point and mount options.
+func (c *CreateVolumeOperation) LocalMount(mountPoint string, options ...string) *CreateVolumeOperation {
+	c.req.Driver = cpb.Driver_DS_LOCAL
+	c.req.Options = &cpb.CreateVolumeRequest_LocalMountOptions{LocalMountOptions: &cpb.LocalDriverOptions{
+		Type:       cpb.LocalDriverOptions_TYPE_NONE,
+		Mountpoint: mountPoint,
+		Options:    options,
+	}}
+	return c
+}
+
+// CustomDriver specifies that the volume uses a custom driver loaded as a
+// plugin, with options passed to it unchanged.
+func (c *CreateVolumeOperation) CustomDriver(options map[string]string) *CreateVolumeOperation {
+	c.req.Driver = cpb.Driver_DS_CUSTOM
+	c.req.Options = &cpb.CreateVolumeRequest_CustomOptions{CustomOptions: &cpb.CustomOptions{Options: options}}
+	return c
+}
+
+// Execute performs the CreateVolume operation.
+func (c *CreateVolumeOperation) Execute(ctx context.Context, cl *internal.Clients) (*cpb.CreateVolumeResponse, error) {
+	return cl.Containerz().CreateVolume(ctx, c.req)
+}
+
+// Request returns the request proto of the CreateVolume operation.
+func (c *CreateVolumeOperation) Request() proto.Message {
+	return c.req
+}
+
+// RemoveVolumeOperation represents the parameters of a RemoveVolume operation.
+type RemoveVolumeOperation struct {
+	req *cpb.RemoveVolumeRequest
+}
+
+// NewRemoveVolumeOperation creates an empty RemoveVolumeOperation.
+func NewRemoveVolumeOperation() *RemoveVolumeOperation {
+	return &RemoveVolumeOperation{req: &cpb.RemoveVolumeRequest{}}
+}
+
+// Name specifies the name of the volume to remove.
+func (r *RemoveVolumeOperation) Name(name string) *RemoveVolumeOperation {
+	r.req.Name = name
+	return r
+}
+
+// Force specifies whether to force the removal of the volume.
+func (r *RemoveVolumeOperation) Force(force bool) *RemoveVolumeOperation {
+	r.req.Force = force
+	return r
+}
+
+// Execute performs the RemoveVolume operation.
+func (r *RemoveVolumeOperation) Execute(ctx context.Context, c *internal.Clients) (*cpb.RemoveVolumeResponse, error) {
+	return c.Containerz().RemoveVolume(ctx, r.req)
+}
+
+// Request returns the request proto of the RemoveVolume operation.
+func (r *RemoveVolumeOperation) Request() proto.Message {
+	return r.req
+}
+
+// Risk returns the risk class of the RemoveVolume operation.
+func (r *RemoveVolumeOperation) Risk() internal.Risk {
+	return internal.RiskDestructive
+}
+
+// ListVolumeOperation represents the parameters of a ListVolume operation.
+type ListVolumeOperation struct {
+	req *cpb.ListVolumeRequest
+}
+
+// NewListVolumeOperation creates an empty ListVolumeOperation.
+func NewListVolumeOperation() *ListVolumeOperation {
+	return &ListVolumeOperation{req: &cpb.ListVolumeRequest{}}
+}
+
+// Filter adds a filter on the volumes to return.
+func (l *ListVolumeOperation) Filter(key string, values ...string) *ListVolumeOperation {
+	l.req.Filter = append(l.req.Filter, &cpb.ListVolumeRequest_Filter{Key: key, Value: values})
+	return l
+}
+
+// Execute performs the ListVolume operation.
+func (l *ListVolumeOperation) Execute(ctx context.Context, c *internal.Clients) ([]*cpb.ListVolumeResponse, error) {
+	list, err := c.Containerz().ListVolume(ctx, l.req)
+	if err != nil {
+		return nil, err
+	}
+
+	var listResp []*cpb.ListVolumeResponse
+
+	for {
+		resp, err := list.Recv()
+		switch {
+		case err == io.EOF:
+			return listResp, nil
+		case err != nil:
+			return nil, err
+		default:
+			listResp = append(listResp, resp)
+		}
+	}
+}
+
+// Request returns the request proto of the ListVolume operation.
+func (l *ListVolumeOperation) Request() proto.Message {
+	return l.req
+}
+
+// Idempotent reports that the ListVolume operation can safely be retried.
+func (l *ListVolumeOperation) Idempotent() bool {
+	return true
+}
@@ -0,0 +1,138 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package containerz_test
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	cpb "github.com/openconfig/gnoi/containerz"
+	"github.com/openconfig/gnoigo/containerz"
+	"github.com/openconfig/gnoigo/internal"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+func TestCreateVolume(t *testing.T) {
+	tests := []struct {
+		desc    string
+		op      *containerz.CreateVolumeOperation
+		wantReq *cpb.CreateVolumeRequest
+	}{
+		{
+			desc:    "default driver",
+			op:      containerz.NewCreateVolumeOperation().Name("data"),
+			wantReq: &cpb.CreateVolumeRequest{Name: "data"},
+		},
+		{
+			desc: "local mount",
+			op:   containerz.NewCreateVolumeOperation().Name("data").Labels(map[string]string{"app": "agent"}).LocalMount("/mnt/data", "bind", "ro"),
+			wantReq: &cpb.CreateVolumeRequest{
+				Name:   "data",
+				Driver: cpb.Driver_DS_LOCAL,
+				Labels: map[string]string{"app": "agent"},
+				Options: &cpb.CreateVolumeRequest_LocalMountOptions{LocalMountOptions: &cpb.LocalDriverOptions{
+					Type:       cpb.LocalDriverOptions_TYPE_NONE,
+					Mountpoint: "/mnt/data",
+					Options:    []string{"bind", "ro"},
+				}},
+			},
+		},
+		{
+			desc: "custom driver",
+			op:   containerz.NewCreateVolumeOperation().CustomDriver(map[string]string{"size": "1G"}),
+			wantReq: &cpb.CreateVolumeRequest{
+				Driver:  cpb.Driver_DS_CUSTOM,
+				Options: &cpb.CreateVolumeRequest_CustomOptions{CustomOptions: &cpb.CustomOptions{Options: map[string]string{"size": "1G"}}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var gotReq *cpb.CreateVolumeRequest
+			var fakeClient internal.Clients
+			fakeClient.ContainerzClient = &fakeContainerzClient{CreateVolumeFn: func(_ context.Context, in *cpb.CreateVolumeRequest, _ ...grpc.CallOption) (*cpb.CreateVolumeResponse, error) {
+				gotReq = in
+				return &cpb.CreateVolumeResponse{Name: "data"}, nil
+			}}
+
+			got, err := tt.op.Execute(context.Background(), &fakeClient)
+			if err != nil {
+				t.Fatalf("Execute() failed: %v", err)
+			}
+			if diff := cmp.Diff(tt.wantReq, gotReq, protocmp.Transform()); diff != "" {
+				t.Errorf("Execute() sent unexpected request diff (-want +got): %s", diff)
+			}
+			if got.GetName() != "data" {
+				t.Errorf("Execute() got volume %q, want %q", got.GetName(), "data")
+			}
+		})
+	}
+}
+
+func TestRemoveVolume(t *testing.T) {
+	var gotReq *cpb.RemoveVolumeRequest
+	var fakeClient internal.Clients
+	fakeClient.ContainerzClient = &fakeContainerzClient{RemoveVolumeFn: func(_ context.Context, in *cpb.RemoveVolumeRequest, _ ...grpc.CallOption) (*cpb.RemoveVolumeResponse, error) {
+		gotReq = in
+		return &cpb.RemoveVolumeResponse{}, nil
+	}}
+
+	if _, err := containerz.NewRemoveVolumeOperation().Name("data").Force(true).Execute(context.Background(), &fakeClient); err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+	wantReq := &cpb.RemoveVolumeRequest{Name: "data", Force: true}
+	if diff := cmp.Diff(wantReq, gotReq, protocmp.Transform()); diff != "" {
+		t.Errorf("Execute() sent unexpected request diff (-want +got): %s", diff)
+	}
+}
+
+type fakeListVolumeClient struct {
+	cpb.Containerz_ListVolumeClient
+	resp []*cpb.ListVolumeResponse
+}
+
+func (lc *fakeListVolumeClient) Recv() (*cpb.ListVolumeResponse, error) {
+	if len(lc.resp) == 0 {
+		return nil, io.EOF
+	}
+	resp := lc.resp[0]
+	lc.resp = lc.resp[1:]
+	return resp, nil
+}
+
+func TestListVolume(t *testing.T) {
+	volumes := []*cpb.ListVolumeResponse{{Name: "data", Driver: "local"}, {Name: "logs", Driver: "local"}}
+	var gotReq *cpb.ListVolumeRequest
+	var fakeClient internal.Clients
+	fakeClient.ContainerzClient = &fakeContainerzClient{ListVolumeFn: func(_ context.Context, in *cpb.ListVolumeRequest, _ ...grpc.CallOption) (cpb.Containerz_ListVolumeClient, error) {
+		gotReq = in
+		return &fakeListVolumeClient{resp: volumes}, nil
+	}}
+
+	got, err := containerz.NewListVolumeOperation().Filter("driver", "local").Execute(context.Background(), &fakeClient)
+	if err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+	wantReq := &cpb.ListVolumeRequest{Filter: []*cpb.ListVolumeRequest_Filter{{Key: "driver", Value: []string{"local"}}}}
+	if diff := cmp.Diff(wantReq, gotReq, protocmp.Transform()); diff != "" {
+		t.Errorf("Execute() sent unexpected request diff (-want +got): %s", diff)
+	}
+	if diff := cmp.Diff(volumes, got, protocmp.Transform()); diff != "" {
+		t.Errorf("Execute() got unexpected response diff (-want +got): %s", diff)
+	}
+}
@@ -0,0 +1,225 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	log "github.com/golang/glog"
+	dpb "github.com/openconfig/gnoi/diag"
+	tpb "github.com/openconfig/gnoi/types"
+	"github.com/openconfig/gnoigo/internal"
+)
+
+// defaultBERTPollInterval is the time between GetBERTResult calls while the
+// BERT is running.
+const defaultBERTPollInterval = 10 * time.Second
+
+// stopTimeout bounds the StopBERT call issued after the context is cancelled.
+const stopTimeout = 30 * time.Second
+
+type bertPort struct {
+	intf       *tpb.Path
+	polynomial dpb.PrbsPolynomial
+	duration   time.Duration
+}
+
+// RunBERTOperation represents the parameters of a complete BERT run: it
+// starts BERT on the ports, polls the results until the longest test duration
+// has elapsed, and stops BERT.
+type RunBERTOperation struct {
+	id           string
+	ports        []bertPort
+	maxErrors    uint64
+	pollInterval time.Duration
+}
+
+// NewRunBERTOperation creates an empty RunBERTOperation.
+func NewRunBERTOperation() *RunBERTOperation {
+	return &RunBERTOperation{pollInterval: defaultBERTPollInterval}
+}
+
+// OperationID specifies the ID of the BERT operation.
+func (r *RunBERTOperation) OperationID(id string) *RunBERTOperation {
+	r.id = id
+	return r
+}
+
+// Port adds a port to run BERT on, with the PRBS polynomial and the test
+// duration, which is rounded up to whole seconds.
+func (r *RunBERTOperation) Port(intf *tpb.Path, polynomial dpb.PrbsPolynomial, duration time.Duration) *RunBERTOperation {
+	r.ports = append(r.ports, bertPort{intf: intf, polynomial: polynomial, duration: duration})
+	return r
+}
+
+// MaxErrors specifies the number of bit errors a port may accumulate and still
+// pass. The default is zero.
+func (r *RunBERTOperation) MaxErrors(n uint64) *RunBERTOperation {
+	r.maxErrors = n
+	return r
+}
+
+// PollInterval specifies the time between GetBERTResult calls.
+func (r *RunBERTOperation) PollInterval(interval time.Duration) *RunBERTOperation {
+	r.pollInterval = interval
+	return r
+}
+
+// PortResult is the outcome of BERT on a port.
+type PortResult struct {
+	Interface           *tpb.Path
+	Status              dpb.BertStatus
+	PeerLockEstablished bool
+	PeerLockLost        bool
+	ErrorCountPerMinute []uint32
+	TotalErrors         uint64
+	Passed              bool
+}
+
+// BERTResult is the outcome of a RunBERTOperation.
+type BERTResult struct {
+	OperationID string
+	Ports       []*PortResult
+}
+
+// Passed reports whether every port passed.
+func (r *BERTResult) Passed() bool {
+	for _, p := range r.Ports {
+		if !p.Passed {
+			return false
+		}
+	}
+	return len(r.Ports) > 0
+}
+
+// Execute performs the RunBERT operation. Once BERT has started, it is
+// stopped even if ctx is cancelled.
+func (r *RunBERTOperation) Execute(ctx context.Context, c *internal.Clients) (*BERTResult, error) {
+	if len(r.ports) == 0 {
+		return nil, errors.New("no ports specified for BERT")
+	}
+	start := NewStartBERTOperation().OperationID(r.id)
+	var longest time.Duration
+	for _, p := range r.ports {
+		start.Port(p.intf, p.polynomial, p.duration)
+		longest = max(longest, time.Duration(durationSecs(p.duration))*time.Second)
+	}
+	startTime := time.Now()
+	startResp, err := start.Execute(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	var startErrs []error
+	for _, p := range startResp.GetPerPortResponses() {
+		if p.GetStatus() != dpb.BertStatus_BERT_STATUS_OK {
+			startErrs = append(startErrs, fmt.Errorf("port %v: %v", p.GetInterface(), p.GetStatus()))
+		}
+	}
+
+	res, runErr := r.run(ctx, c, startTime, longest, startErrs)
+	if err := r.stop(ctx, c); err != nil {
+		return nil, errors.Join(runErr, fmt.Errorf("error stopping BERT: %w", err))
+	}
+	if runErr != nil {
+		return nil, runErr
+	}
+	return res, nil
+}
+
+// Risk returns the risk class of the RunBERT operation, which takes the ports
+// out of service for the duration of the test.
+func (r *RunBERTOperation) Risk() internal.Risk {
+	return internal.RiskDisruptive
+}
+
+func (r *RunBERTOperation) run(ctx context.Context, c *internal.Clients, startTime time.Time, longest time.Duration, startErrs []error) (*BERTResult, error) {
+	if err := errors.Join(startErrs...); err != nil {
+		return nil, fmt.Errorf("error starting BERT: %w", err)
+	}
+	get := NewGetBERTResultOperation().OperationID(r.id)
+	for _, p := range r.ports {
+		get.Port(p.intf)
+	}
+	for {
+		elapsed := time.Since(startTime)
+		resp, err := get.Execute(ctx, c)
+		if err != nil {
+			return nil, err
+		}
+		if elapsed >= longest || allFailed(resp) {
+			return r.result(resp), nil
+		}
+		log.Infof("waiting for BERT %q to complete: %v of %v elapsed", r.id, elapsed.Round(time.Second), longest)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(min(r.pollInterval, longest-elapsed)):
+		}
+	}
+}
+
+// stop stops BERT on the ports. Ports on which BERT already completed are not
+// an error.
+func (r *RunBERTOperation) stop(ctx context.Context, c *internal.Clients) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), stopTimeout)
+	defer cancel()
+	stop := NewStopBERTOperation().OperationID(r.id)
+	for _, p := range r.ports {
+		stop.Port(p.intf)
+	}
+	resp, err := stop.Execute(ctx, c)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, p := range resp.GetPerPortResponses() {
+		switch p.GetStatus() {
+		case dpb.BertStatus_BERT_STATUS_OK, dpb.BertStatus_BERT_STATUS_PORT_NOT_RUNNING_BERT:
+		default:
+			errs = append(errs, fmt.Errorf("port %v: %v", p.GetInterface(), p.GetStatus()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// allFailed reports whether no port can still pass, so polling can end early.
+func allFailed(resp *dpb.GetBERTResultResponse) bool {
+	for _, p := range resp.GetPerPortResponses() {
+		if p.GetStatus() == dpb.BertStatus_BERT_STATUS_OK && !p.GetPeerLockLost() {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *RunBERTOperation) result(resp *dpb.GetBERTResultResponse) *BERTResult {
+	res := &BERTResult{OperationID: r.id}
+	for _, p := range resp.GetPerPortResponses() {
+		pr := &PortResult{
+			Interface:           p.GetInterface(),
+			Status:              p.GetStatus(),
+			PeerLockEstablished: p.GetPeerLockEstablished(),
+			PeerLockLost:        p.GetPeerLockLost(),
+			ErrorCountPerMinute: p.GetErrorCountPerMinute(),
+			TotalErrors:         p.GetTotalErrors(),
+		}
+		pr.Passed = pr.Status == dpb.BertStatus_BERT_STATUS_OK && pr.PeerLockEstablished && !pr.PeerLockLost && pr.TotalErrors <= r.maxErrors
+		res.Ports = append(res.Ports, pr)
+	}
+	return res
+}
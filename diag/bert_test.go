@@ -0,0 +1,140 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	dpb "github.com/openconfig/gnoi/diag"
+	"github.com/openconfig/gnoigo/diag"
+	"github.com/openconfig/gnoigo/internal"
+	"google.golang.org/grpc"
+)
+
+func okPort(name string, errors uint64) *dpb.GetBERTResultResponse_PerPortResponse {
+	return &dpb.GetBERTResultResponse_PerPortResponse{
+		Interface:           diag.InterfacePath(name),
+		Status:              dpb.BertStatus_BERT_STATUS_OK,
+		PeerLockEstablished: true,
+		TotalErrors:         errors,
+	}
+}
+
+func TestRunBERT(t *testing.T) {
+	tests := []struct {
+		desc        string
+		startStatus dpb.BertStatus
+		results     []*dpb.GetBERTResultResponse_PerPortResponse
+		maxErrors   uint64
+		wantPassed  []bool
+		polls       bool
+		wantErr     string
+	}{
+		{
+			desc:       "all ports pass",
+			results:    []*dpb.GetBERTResultResponse_PerPortResponse{okPort("Ethernet1", 0), okPort("Ethernet2", 0)},
+			wantPassed: []bool{true, true},
+			polls:      true,
+		},
+		{
+			desc:       "errors above threshold",
+			results:    []*dpb.GetBERTResultResponse_PerPortResponse{okPort("Ethernet1", 2), okPort("Ethernet2", 5)},
+			maxErrors:  2,
+			wantPassed: []bool{true, false},
+			polls:      true,
+		},
+		{
+			desc: "peer lock lost ends early",
+			results: []*dpb.GetBERTResultResponse_PerPortResponse{
+				{Interface: diag.InterfacePath("Ethernet1"), Status: dpb.BertStatus_BERT_STATUS_OK, PeerLockEstablished: true, PeerLockLost: true},
+				{Interface: diag.InterfacePath("Ethernet2"), Status: dpb.BertStatus_BERT_STATUS_PEER_LOCK_FAILURE},
+			},
+			wantPassed: []bool{false, false},
+		},
+		{
+			desc:        "start failure",
+			startStatus: dpb.BertStatus_BERT_STATUS_PORT_ALREADY_IN_BERT,
+			wantErr:     "BERT_STATUS_PORT_ALREADY_IN_BERT",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var gets, stops int
+			var fakeClient internal.Clients
+			fakeClient.DiagClient = &fakeDiagClient{
+				StartBERTFn: func(_ context.Context, in *dpb.StartBERTRequest, _ ...grpc.CallOption) (*dpb.StartBERTResponse, error) {
+					resp := &dpb.StartBERTResponse{BertOperationId: in.GetBertOperationId()}
+					for i, p := range in.GetPerPortRequests() {
+						status := dpb.BertStatus_BERT_STATUS_OK
+						if i == 1 && tt.startStatus != dpb.BertStatus_BERT_STATUS_UNKNOWN {
+							status = tt.startStatus
+						}
+						resp.PerPortResponses = append(resp.PerPortResponses, &dpb.StartBERTResponse_PerPortResponse{Interface: p.GetInterface(), Status: status})
+					}
+					return resp, nil
+				},
+				GetBERTResultFn: func(context.Context, *dpb.GetBERTResultRequest, ...grpc.CallOption) (*dpb.GetBERTResultResponse, error) {
+					gets++
+					return &dpb.GetBERTResultResponse{PerPortResponses: tt.results}, nil
+				},
+				StopBERTFn: func(_ context.Context, in *dpb.StopBERTRequest, _ ...grpc.CallOption) (*dpb.StopBERTResponse, error) {
+					stops++
+					resp := &dpb.StopBERTResponse{}
+					for _, p := range in.GetPerPortRequests() {
+						resp.PerPortResponses = append(resp.PerPortResponses, &dpb.StopBERTResponse_PerPortResponse{Interface: p.GetInterface(), Status: dpb.BertStatus_BERT_STATUS_PORT_NOT_RUNNING_BERT})
+					}
+					return resp, nil
+				},
+			}
+
+			// The port durations round up to one second.
+			got, err := diag.NewRunBERTOperation().OperationID("bert1").
+				Port(diag.InterfacePath("Ethernet1"), dpb.PrbsPolynomial_PRBS_POLYNOMIAL_PRBS31, 100*time.Millisecond).
+				Port(diag.InterfacePath("Ethernet2"), dpb.PrbsPolynomial_PRBS_POLYNOMIAL_PRBS31, 100*time.Millisecond).
+				MaxErrors(tt.maxErrors).PollInterval(200*time.Millisecond).
+				Execute(context.Background(), &fakeClient)
+			if stops != 1 {
+				t.Errorf("Execute() called StopBERT %d times, want 1", stops)
+			}
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Execute() got error %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Execute() failed: %v", err)
+			}
+			if gotPolls := gets > 1; gotPolls != tt.polls {
+				t.Errorf("Execute() called GetBERTResult %d times, want polling %v", gets, tt.polls)
+			}
+			var gotPassed []bool
+			for _, p := range got.Ports {
+				gotPassed = append(gotPassed, p.Passed)
+			}
+			if len(gotPassed) != len(tt.wantPassed) {
+				t.Fatalf("Execute() got %d port results, want %d", len(gotPassed), len(tt.wantPassed))
+			}
+			for i := range gotPassed {
+				if gotPassed[i] != tt.wantPassed[i] {
+					t.Errorf("Execute() port %d got Passed %v, want %v", i, gotPassed[i], tt.wantPassed[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package diag provides gNOI diag operations.
+package diag
+
+import (
+	"context"
+	"time"
+
+	dpb "github.com/openconfig/gnoi/diag"
+	tpb "github.com/openconfig/gnoi/types"
+	"github.com/openconfig/gnoigo/internal"
+	"google.golang.org/protobuf/proto"
+)
+
+// InterfacePath returns the path `/openconfig/interfaces/interface[name=<n>]`.
+func InterfacePath(n string) *tpb.Path {
+	return internal.InterfacePath(n)
+}
+
+// durationSecs converts d to whole seconds, rounding up.
+func durationSecs(d time.Duration) uint32 {
+	return uint32((d + time.Second - 1) / time.Second)
+}
+
+// StartBERTOperation represents the parameters of a StartBERT operation.
+type StartBERTOperation struct {
+	req *dpb.StartBERTRequest
+}
+
+// NewStartBERTOperation creates an empty StartBERTOperation.
+func NewStartBERTOperation() *StartBERTOperation {
+	return &StartBERTOperation{req: &dpb.StartBERTRequest{}}
+}
+
+// OperationID specifies the ID of the BERT operation.
+func (s *StartBERTOperation) OperationID(id string) *StartBERTOperation {
+	s.req.BertOperationId = id
+	return s
+}
+
+// Port adds a port to run BERT on, with the PRBS polynomial and the test
+// duration, which is rounded up to whole seconds.
+func (s *StartBERTOperation) Port(intf *tpb.Path, polynomial dpb.PrbsPolynomial, duration time.Duration) *StartBERTOperation {
+	s.req.PerPortRequests = append(s.req.PerPortRequests, &dpb.StartBERTRequest_PerPortRequest{
+		Interface:          intf,
+		PrbsPolynomial:     polynomial,
+		TestDurationInSecs: durationSecs(duration),
+	})
+	return s
+}
+
+// Execute performs the StartBERT operation.
+func (s *StartBERTOperation) Execute(ctx context.Context, c *internal.Clients) (*dpb.StartBERTResponse, error) {
+	return c.Diag().StartBERT(ctx, s.req)
+}
+
+// Request returns the request proto of the StartBERT operation.
+func (s *StartBERTOperation) Request() proto.Message {
+	return s.req
+}
+
+// Risk returns the risk class of the StartBERT operation, which takes the
+// ports out of service for the duration of the test.
+func (s *StartBERTOperation) Risk() internal.Risk {
+	return internal.RiskDisruptive
+}
+
+// StopBERTOperation represents the parameters of a StopBERT operation.
+type StopBERTOperation struct {
+	req *dpb.StopBERTRequest
+}
+
+// NewStopBERTOperation creates an empty StopBERTOperation.
+func NewStopBERTOperation() *StopBERTOperation {
+	return &StopBERTOperation{req: &dpb.StopBERTRequest{}}
+}
+
+// OperationID specifies the ID of the BERT operation to stop.
+func (s *StopBERTOperation) OperationID(id string) *StopBERTOperation {
+	s.req.BertOperationId = id
+	return s
+}
+
+// Port adds a port to stop BERT on.
+func (s *StopBERTOperation) Port(intf *tpb.Path) *StopBERTOperation {
+	s.req.PerPortRequests = append(s.req.PerPortRequests, &dpb.StopBERTRequest_PerPortRequest{Interface: intf})
+	return s
+}
+
+// Execute performs the StopBERT operation.
+func (s *StopBERTOperation) Execute(ctx context.Context, c *internal.Clients) (*dpb.StopBERTResponse, error) {
+	return c.Diag().StopBERT(ctx, s.req)
+}
+
+// Request returns the request proto of the StopBERT operation.
+func (s *StopBERTOperation) Request() proto.Message {
+	return s.req
+}
+
+// GetBERTResultOperation represents the parameters of a GetBERTResult operation.
+type GetBERTResultOperation struct {
+	req *dpb.GetBERTResultRequest
+}
+
+// NewGetBERTResultOperation creates an empty GetBERTResultOperation.
+func NewGetBERTResultOperation() *GetBERTResultOperation {
+	return &GetBERTResultOperation{req: &dpb.GetBERTResultRequest{}}
+}
+
+// OperationID specifies the ID of the BERT operation to get the results of.
+func (g *GetBERTResultOperation) OperationID(id string) *GetBERTResultOperation {
+	g.req.BertOperationId = id
+	return g
+}
+
+// Port adds a port to get the BERT results of.
+func (g *GetBERTResultOperation) Port(intf *tpb.Path) *GetBERTResultOperation {
+	g.req.PerPortRequests = append(g.req.PerPortRequests, &dpb.GetBERTResultRequest_PerPortRequest{Interface: intf})
+	return g
+}
+
+// AllPorts specifies whether to get the results of every port, ignoring the
+// operation ID and ports.
+func (g *GetBERTResultOperation) AllPorts(all bool) *GetBERTResultOperation {
+	g.req.ResultFromAllPorts = all
+	return g
+}
+
+// Execute performs the GetBERTResult operation.
+func (g *GetBERTResultOperation) Execute(ctx context.Context, c *internal.Clients) (*dpb.GetBERTResultResponse, error) {
+	return c.Diag().GetBERTResult(ctx, g.req)
+}
+
+// Request returns the request proto of the GetBERTResult operation.
+func (g *GetBERTResultOperation) Request() proto.Message {
+	return g.req
+}
+
+// Idempotent reports that the GetBERTResult operation can safely be retried.
+func (g *GetBERTResultOperation) Idempotent() bool {
+	return true
+}
@@ -0,0 +1,110 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diag_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	dpb "github.com/openconfig/gnoi/diag"
+	"github.com/openconfig/gnoigo/diag"
+	"github.com/openconfig/gnoigo/internal"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+type fakeDiagClient struct {
+	dpb.DiagClient
+	StartBERTFn     func(context.Context, *dpb.StartBERTRequest, ...grpc.CallOption) (*dpb.StartBERTResponse, error)
+	StopBERTFn      func(context.Context, *dpb.StopBERTRequest, ...grpc.CallOption) (*dpb.StopBERTResponse, error)
+	GetBERTResultFn func(context.Context, *dpb.GetBERTResultRequest, ...grpc.CallOption) (*dpb.GetBERTResultResponse, error)
+}
+
+func (fc *fakeDiagClient) StartBERT(ctx context.Context, in *dpb.StartBERTRequest, opts ...grpc.CallOption) (*dpb.StartBERTResponse, error) {
+	return fc.StartBERTFn(ctx, in, opts...)
+}
+
+func (fc *fakeDiagClient) StopBERT(ctx context.Context, in *dpb.StopBERTRequest, opts ...grpc.CallOption) (*dpb.StopBERTResponse, error) {
+	return fc.StopBERTFn(ctx, in, opts...)
+}
+
+func (fc *fakeDiagClient) GetBERTResult(ctx context.Context, in *dpb.GetBERTResultRequest, opts ...grpc.CallOption) (*dpb.GetBERTResultResponse, error) {
+	return fc.GetBERTResultFn(ctx, in, opts...)
+}
+
+func TestStartBERT(t *testing.T) {
+	var gotReq *dpb.StartBERTRequest
+	var fakeClient internal.Clients
+	fakeClient.DiagClient = &fakeDiagClient{StartBERTFn: func(_ context.Context, in *dpb.StartBERTRequest, _ ...grpc.CallOption) (*dpb.StartBERTResponse, error) {
+		gotReq = in
+		return &dpb.StartBERTResponse{BertOperationId: in.GetBertOperationId()}, nil
+	}}
+
+	_, err := diag.NewStartBERTOperation().OperationID("bert1").
+		Port(diag.InterfacePath("Ethernet1"), dpb.PrbsPolynomial_PRBS_POLYNOMIAL_PRBS31, 90*time.Second).
+		Port(diag.InterfacePath("Ethernet2"), dpb.PrbsPolynomial_PRBS_POLYNOMIAL_PRBS7, 1500*time.Millisecond).
+		Execute(context.Background(), &fakeClient)
+	if err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+	wantReq := &dpb.StartBERTRequest{
+		BertOperationId: "bert1",
+		PerPortRequests: []*dpb.StartBERTRequest_PerPortRequest{
+			{Interface: diag.InterfacePath("Ethernet1"), PrbsPolynomial: dpb.PrbsPolynomial_PRBS_POLYNOMIAL_PRBS31, TestDurationInSecs: 90},
+			{Interface: diag.InterfacePath("Ethernet2"), PrbsPolynomial: dpb.PrbsPolynomial_PRBS_POLYNOMIAL_PRBS7, TestDurationInSecs: 2},
+		},
+	}
+	if diff := cmp.Diff(wantReq, gotReq, protocmp.Transform()); diff != "" {
+		t.Errorf("Execute() sent unexpected request diff (-want +got): %s", diff)
+	}
+}
+
+func TestStopBERT(t *testing.T) {
+	var gotReq *dpb.StopBERTRequest
+	var fakeClient internal.Clients
+	fakeClient.DiagClient = &fakeDiagClient{StopBERTFn: func(_ context.Context, in *dpb.StopBERTRequest, _ ...grpc.CallOption) (*dpb.StopBERTResponse, error) {
+		gotReq = in
+		return &dpb.StopBERTResponse{}, nil
+	}}
+
+	if _, err := diag.NewStopBERTOperation().OperationID("bert1").Port(diag.InterfacePath("Ethernet1")).Execute(context.Background(), &fakeClient); err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+	wantReq := &dpb.StopBERTRequest{
+		BertOperationId: "bert1",
+		PerPortRequests: []*dpb.StopBERTRequest_PerPortRequest{{Interface: diag.InterfacePath("Ethernet1")}},
+	}
+	if diff := cmp.Diff(wantReq, gotReq, protocmp.Transform()); diff != "" {
+		t.Errorf("Execute() sent unexpected request diff (-want +got): %s", diff)
+	}
+}
+
+func TestGetBERTResult(t *testing.T) {
+	var gotReq *dpb.GetBERTResultRequest
+	var fakeClient internal.Clients
+	fakeClient.DiagClient = &fakeDiagClient{GetBERTResultFn: func(_ context.Context, in *dpb.GetBERTResultRequest, _ ...grpc.CallOption) (*dpb.GetBERTResultResponse, error) {
+		gotReq = in
+		return &dpb.GetBERTResultResponse{}, nil
+	}}
+
+	if _, err := diag.NewGetBERTResultOperation().AllPorts(true).Execute(context.Background(), &fakeClient); err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+	if diff := cmp.Diff(&dpb.GetBERTResultRequest{ResultFromAllPorts: true}, gotReq, protocmp.Transform()); diff != "" {
+		t.Errorf("Execute() sent unexpected request diff (-want +got): %s", diff)
+	}
+}
@@ -17,8 +17,11 @@ package factoryreset
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	frpb "github.com/openconfig/gnoi/factory_reset"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/openconfig/gnoigo/internal"
 )
@@ -56,3 +59,58 @@ func (s *StartOperation) RetainCerts(rc bool) *StartOperation {
 func (s *StartOperation) Execute(ctx context.Context, c *internal.Clients) (*frpb.StartResponse, error) {
 	return c.FactoryReset().Start(ctx, s.req)
 }
+
+// Request returns the request proto of the Start operation.
+func (s *StartOperation) Request() proto.Message {
+	return s.req
+}
+
+// Risk returns the risk class of the Start operation.
+func (s *StartOperation) Risk() internal.Risk {
+	return internal.RiskDestructive
+}
+
+// ResetError is the error reported by the target in a StartResponse when it
+// cannot perform the factory reset.
+type ResetError struct {
+	FactoryOSUnsupported bool
+	ZeroFillUnsupported  bool
+	Other                bool
+	Detail               string
+}
+
+func (e *ResetError) Error() string {
+	var reasons []string
+	if e.FactoryOSUnsupported {
+		reasons = append(reasons, "factory OS rollback unsupported")
+	}
+	if e.ZeroFillUnsupported {
+		reasons = append(reasons, "zero fill unsupported")
+	}
+	if e.Other || len(reasons) == 0 {
+		reasons = append(reasons, "other error")
+	}
+	msg := "factory reset failed: " + strings.Join(reasons, ", ")
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+	return msg
+}
+
+// ErrorOf returns a *ResetError if the StartResponse reports a ResetError,
+// an error if it reports neither success nor error, and nil otherwise.
+func ErrorOf(resp *frpb.StartResponse) error {
+	if resp.GetResetSuccess() != nil {
+		return nil
+	}
+	re := resp.GetResetError()
+	if re == nil {
+		return fmt.Errorf("factory reset response has no result: %v", resp)
+	}
+	return &ResetError{
+		FactoryOSUnsupported: re.GetFactoryOsUnsupported(),
+		ZeroFillUnsupported:  re.GetZeroFillUnsupported(),
+		Other:                re.GetOther(),
+		Detail:               re.GetDetail(),
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"google.golang.org/grpc"
 
 	frpb "github.com/openconfig/gnoi/factory_reset"
@@ -78,3 +79,58 @@ func TestFactoryResetStart(t *testing.T) {
 		})
 	}
 }
+
+func TestErrorOf(t *testing.T) {
+	tests := []struct {
+		desc    string
+		resp    *frpb.StartResponse
+		want    *factoryreset.ResetError
+		wantErr string
+	}{
+		{
+			desc: "success",
+			resp: &frpb.StartResponse{Response: &frpb.StartResponse_ResetSuccess{ResetSuccess: &frpb.ResetSuccess{}}},
+		},
+		{
+			desc: "zero fill unsupported",
+			resp: &frpb.StartResponse{Response: &frpb.StartResponse_ResetError{ResetError: &frpb.ResetError{ZeroFillUnsupported: true}}},
+			want: &factoryreset.ResetError{ZeroFillUnsupported: true},
+		},
+		{
+			desc: "other",
+			resp: &frpb.StartResponse{Response: &frpb.StartResponse_ResetError{ResetError: &frpb.ResetError{Other: true, Detail: "disk busy"}}},
+			want: &factoryreset.ResetError{Other: true, Detail: "disk busy"},
+		},
+		{
+			desc:    "empty response",
+			resp:    &frpb.StartResponse{},
+			wantErr: "no result",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			err := factoryreset.ErrorOf(tt.resp)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ErrorOf() got error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			var got *factoryreset.ResetError
+			if err != nil && !errors.As(err, &got) {
+				t.Fatalf("ErrorOf() got error %v, want *ResetError", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("ErrorOf() got unexpected error diff (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestResetErrorString(t *testing.T) {
+	err := &factoryreset.ResetError{FactoryOSUnsupported: true, ZeroFillUnsupported: true, Detail: "not supported on this platform"}
+	want := "factory reset failed: factory OS rollback unsupported, zero fill unsupported: not supported on this platform"
+	if got := err.Error(); got != want {
+		t.Errorf("Error() got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package factoryreset
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	log "github.com/golang/glog"
+	spb "github.com/openconfig/gnoi/system"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/openconfig/gnoigo/internal"
+	"github.com/openconfig/gnoigo/system"
+)
+
+const (
+	// defaultBootDelay is the time to wait after the reset starts before
+	// trying to reach the target, so the target is not reached before it
+	// goes down.
+	defaultBootDelay = time.Minute
+	// defaultReprovisionPollInterval is the time between attempts to reach
+	// the target while it is bootstrapped.
+	defaultReprovisionPollInterval = 10 * time.Second
+	// probeTimeout bounds a single attempt to reach the target.
+	probeTimeout = 30 * time.Second
+)
+
+// DialFunc opens a new connection to the target. It is called repeatedly
+// while waiting for the target to be bootstrapped, so it typically carries
+// the credentials the target is provisioned with after the reset.
+type DialFunc func(context.Context) (*grpc.ClientConn, error)
+
+// ReprovisionOperation represents the parameters of a factory reset that
+// waits for the target to be reachable again once it is bootstrapped.
+type ReprovisionOperation struct {
+	start        *StartOperation
+	dial         DialFunc
+	bootDelay    time.Duration
+	pollInterval time.Duration
+}
+
+// NewReprovisionOperation creates an empty ReprovisionOperation.
+func NewReprovisionOperation() *ReprovisionOperation {
+	return &ReprovisionOperation{
+		start:        NewStartOperation(),
+		bootDelay:    defaultBootDelay,
+		pollInterval: defaultReprovisionPollInterval,
+	}
+}
+
+// Start specifies the parameters of the factory reset.
+func (r *ReprovisionOperation) Start(op *StartOperation) *ReprovisionOperation {
+	r.start = op
+	return r
+}
+
+// Dialer specifies how to connect to the target after the reset.
+func (r *ReprovisionOperation) Dialer(dial DialFunc) *ReprovisionOperation {
+	r.dial = dial
+	return r
+}
+
+// BootDelay specifies the time to wait after the reset starts before trying
+// to reach the target.
+func (r *ReprovisionOperation) BootDelay(delay time.Duration) *ReprovisionOperation {
+	r.bootDelay = delay
+	return r
+}
+
+// PollInterval specifies the time between attempts to reach the target.
+func (r *ReprovisionOperation) PollInterval(interval time.Duration) *ReprovisionOperation {
+	r.pollInterval = interval
+	return r
+}
+
+// Risk returns the risk class of the Reprovision operation.
+func (r *ReprovisionOperation) Risk() internal.Risk {
+	return internal.RiskDestructive
+}
+
+// Execute performs the Reprovision operation. The target may drop the session
+// before answering the Start request, which is not treated as a failure. It
+// returns a connection to the bootstrapped target, which the caller must
+// close.
+func (r *ReprovisionOperation) Execute(ctx context.Context, c *internal.Clients) (*grpc.ClientConn, error) {
+	if r.dial == nil {
+		return nil, errors.New("no dialer specified")
+	}
+	resp, err := r.start.Execute(ctx, c)
+	switch {
+	case status.Code(err) == codes.Unavailable:
+		log.Infof("session dropped during factory reset, assuming the reset started: %v", err)
+	case err != nil:
+		return nil, err
+	default:
+		if err := ErrorOf(resp); err != nil {
+			return nil, err
+		}
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(r.bootDelay):
+	}
+	for {
+		conn, err := r.probe(ctx)
+		if err == nil {
+			return conn, nil
+		}
+		log.Infof("waiting for target to be bootstrapped after factory reset: %v", err)
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("target not reachable after factory reset: %w", errors.Join(ctx.Err(), err))
+		case <-time.After(r.pollInterval):
+		}
+	}
+}
+
+// probe dials the target and calls System.Time over the new connection to
+// check that the target is serving gNOI again.
+func (r *ReprovisionOperation) probe(ctx context.Context) (*grpc.ClientConn, error) {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+	conn, err := r.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := system.NewTimeOperation().Execute(ctx, &internal.Clients{SystemClient: spb.NewSystemClient(conn)}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
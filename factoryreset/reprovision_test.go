@@ -0,0 +1,142 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package factoryreset_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	frpb "github.com/openconfig/gnoi/factory_reset"
+	spb "github.com/openconfig/gnoi/system"
+	"github.com/openconfig/gnoigo/factoryreset"
+	"github.com/openconfig/gnoigo/internal"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type fakeSystemServer struct {
+	spb.UnimplementedSystemServer
+}
+
+func (*fakeSystemServer) Time(context.Context, *spb.TimeRequest) (*spb.TimeResponse, error) {
+	return &spb.TimeResponse{Time: 1}, nil
+}
+
+// bootstrappedDialer returns a DialFunc that fails until it has been called
+// failures times and then connects to a System server.
+func bootstrappedDialer(t *testing.T, failures int) (factoryreset.DialFunc, *int) {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	spb.RegisterSystemServer(srv, &fakeSystemServer{})
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	calls := 0
+	return func(context.Context) (*grpc.ClientConn, error) {
+		calls++
+		if calls <= failures {
+			return nil, errors.New("connection refused")
+		}
+		return grpc.NewClient("passthrough:///device",
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+				return lis.DialContext(ctx)
+			}))
+	}, &calls
+}
+
+func TestReprovision(t *testing.T) {
+	success := &frpb.StartResponse{Response: &frpb.StartResponse_ResetSuccess{ResetSuccess: &frpb.ResetSuccess{}}}
+	tests := []struct {
+		desc      string
+		resp      *frpb.StartResponse
+		startErr  error
+		failures  int
+		wantDials int
+		wantErr   string
+	}{
+		{
+			desc:      "reachable after reset",
+			resp:      success,
+			failures:  2,
+			wantDials: 3,
+		},
+		{
+			desc:      "session dropped",
+			startErr:  status.Error(codes.Unavailable, "transport is closing"),
+			wantDials: 1,
+		},
+		{
+			desc:    "reset error",
+			resp:    &frpb.StartResponse{Response: &frpb.StartResponse_ResetError{ResetError: &frpb.ResetError{FactoryOsUnsupported: true}}},
+			wantErr: "factory OS rollback unsupported",
+		},
+		{
+			desc:     "start failed",
+			startErr: status.Error(codes.PermissionDenied, "denied"),
+			wantErr:  "denied",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var gotReq *frpb.StartRequest
+			fakeClient := &internal.Clients{FactoryResetClient: &fakeFactoryResetClient{StartFn: func(_ context.Context, in *frpb.StartRequest, _ ...grpc.CallOption) (*frpb.StartResponse, error) {
+				gotReq = in
+				return tt.resp, tt.startErr
+			}}}
+			dial, calls := bootstrappedDialer(t, tt.failures)
+			op := factoryreset.NewReprovisionOperation().
+				Start(factoryreset.NewStartOperation().ZeroFill(true)).
+				Dialer(dial).
+				BootDelay(time.Millisecond).
+				PollInterval(time.Millisecond)
+
+			conn, err := op.Execute(context.Background(), fakeClient)
+			if (err == nil) != (tt.wantErr == "") || (err != nil && !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("Execute() got error %v, want %q", err, tt.wantErr)
+			}
+			if conn != nil {
+				conn.Close()
+			}
+			if !gotReq.GetZeroFill() {
+				t.Errorf("Execute() sent request %v, want zero fill", gotReq)
+			}
+			if *calls != tt.wantDials {
+				t.Errorf("Execute() dialed %d times, want %d", *calls, tt.wantDials)
+			}
+		})
+	}
+}
+
+func TestReprovisionTimeout(t *testing.T) {
+	fakeClient := &internal.Clients{FactoryResetClient: &fakeFactoryResetClient{StartFn: func(context.Context, *frpb.StartRequest, ...grpc.CallOption) (*frpb.StartResponse, error) {
+		return &frpb.StartResponse{Response: &frpb.StartResponse_ResetSuccess{ResetSuccess: &frpb.ResetSuccess{}}}, nil
+	}}}
+	dial, _ := bootstrappedDialer(t, 1<<30)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := factoryreset.NewReprovisionOperation().Dialer(dial).BootDelay(0).PollInterval(time.Millisecond).Execute(ctx, fakeClient)
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "connection refused") {
+		t.Errorf("Execute() got error %v, want deadline exceeded with last dial error", err)
+	}
+}
@@ -24,6 +24,7 @@ import (
 	fpb "github.com/openconfig/gnoi/file"
 	tpb "github.com/openconfig/gnoi/types"
 	"github.com/openconfig/gnoigo/internal"
+	"google.golang.org/protobuf/proto"
 )
 
 const (
@@ -122,3 +123,8 @@ func (p *PutOperation) Execute(ctx context.Context, c *internal.Clients) (*fpb.P
 
 	return pclient.CloseAndRecv()
 }
+
+// Request returns the request proto of the Put operation.
+func (p *PutOperation) Request() proto.Message {
+	return p.req
+}
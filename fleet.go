@@ -0,0 +1,166 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gnoigo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/openconfig/gnoigo/internal"
+)
+
+// defaultFleetConcurrency is the number of targets an operation is executed
+// against at the same time, unless specified otherwise.
+const defaultFleetConcurrency = 10
+
+// ErrSkipped is the error of the targets an operation was not executed
+// against, because the context was done or another target failed in
+// fail-fast mode.
+var ErrSkipped = errors.New("operation not executed")
+
+// Result is the outcome of an operation on a single target.
+type Result[T any] struct {
+	Response T
+	Err      error
+	Duration time.Duration
+}
+
+// Fleet represents a set of targets and the parameters of the execution of
+// an operation against all of them.
+type Fleet struct {
+	targets     map[string]Clients
+	labels      map[string]map[string]string
+	concurrency int
+	timeout     time.Duration
+	failFast    bool
+	opts        []ExecuteOption
+}
+
+// NewFleet creates a Fleet of the targets, keyed by name.
+func NewFleet(targets map[string]Clients) *Fleet {
+	return &Fleet{
+		targets:     targets,
+		labels:      map[string]map[string]string{},
+		concurrency: defaultFleetConcurrency,
+	}
+}
+
+// Labels specifies the labels of the named target, which are used to
+// evaluate the policy.
+func (f *Fleet) Labels(name string, labels map[string]string) *Fleet {
+	f.labels[name] = labels
+	return f
+}
+
+// Concurrency specifies the maximum number of targets the operation is
+// executed against at the same time.
+func (f *Fleet) Concurrency(n int) *Fleet {
+	f.concurrency = n
+	return f
+}
+
+// Timeout specifies the time allowed for the operation on each target. Zero
+// means the operation is only bounded by the context.
+func (f *Fleet) Timeout(timeout time.Duration) *Fleet {
+	f.timeout = timeout
+	return f
+}
+
+// FailFast specifies whether to cancel the operation on all targets as soon
+// as it fails on one. Otherwise the operation is executed on every target
+// regardless of failures.
+func (f *Fleet) FailFast(failFast bool) *Fleet {
+	f.failFast = failFast
+	return f
+}
+
+// Options specifies the options used to execute the operation on every
+// target. The target name and labels are set with WithTarget, overriding
+// any target set in an Executor.
+func (f *Fleet) Options(opts ...ExecuteOption) *Fleet {
+	f.opts = opts
+	return f
+}
+
+// ExecuteAll performs an operation against every target of the fleet and
+// returns the result of each target, keyed by name. The returned error joins
+// the errors of all targets. The operation is executed concurrently, so it
+// must not hold state consumed by its execution, such as an io.Reader.
+//
+// The context of each target is cancelled when ExecuteAll returns, or when
+// the fleet timeout expires, so operations that return a stream, such as
+// containerz.LogOperation, layer2.PerformBERTOperation or
+// otdr.InitiateOperation, must be executed with Execute instead: their
+// responses can no longer be read.
+func ExecuteAll[T any](ctx context.Context, f *Fleet, op Operation[T]) (map[string]Result[T], error) {
+	return ExecuteEach(ctx, f, func(string) Operation[T] { return op })
+}
+
+// ExecuteEach is like ExecuteAll, but performs the operation returned by
+// factory for each target, so that the parameters of the operation can
+// differ between targets.
+func ExecuteEach[T any](ctx context.Context, f *Fleet, factory func(name string) Operation[T]) (map[string]Result[T], error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		mu      sync.Mutex
+		results = make(map[string]Result[T], len(f.targets))
+	)
+	notRun := internal.ForEach(ctx, slices.Sorted(maps.Keys(f.targets)), f.concurrency, func(name string) {
+		res := executeTarget(ctx, f, name, factory(name))
+		mu.Lock()
+		results[name] = res
+		mu.Unlock()
+		if res.Err != nil && f.failFast {
+			cancel()
+		}
+	})
+	for _, name := range notRun {
+		results[name] = Result[T]{Err: ErrSkipped}
+	}
+
+	var errs []error
+	skipped := 0
+	for _, name := range slices.Sorted(maps.Keys(results)) {
+		switch err := results[name].Err; {
+		case errors.Is(err, ErrSkipped):
+			skipped++
+		case err != nil:
+			errs = append(errs, fmt.Errorf("target %s: %w", name, err))
+		}
+	}
+	if skipped > 0 {
+		errs = append(errs, fmt.Errorf("%d targets: %w", skipped, ErrSkipped))
+	}
+	return results, errors.Join(errs...)
+}
+
+// executeTarget performs the operation against the named target of f.
+func executeTarget[T any](ctx context.Context, f *Fleet, name string, op Operation[T]) Result[T] {
+	if f.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, f.timeout)
+		defer cancel()
+	}
+	opts := append(slices.Clone(f.opts), WithTarget(Target{Name: name, Labels: f.labels[name]}))
+	start := time.Now()
+	resp, err := Execute(ctx, f.targets[name], op, opts...)
+	return Result[T]{Response: resp, Err: err, Duration: time.Since(start)}
+}
@@ -0,0 +1,185 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gnoigo_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	spb "github.com/openconfig/gnoi/system"
+	"github.com/openconfig/gnoigo"
+	"github.com/openconfig/gnoigo/internal"
+	"github.com/openconfig/gnoigo/system"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fleetTracker records the number of concurrent Time calls across a fleet.
+type fleetTracker struct {
+	mu         sync.Mutex
+	running    int
+	maxRunning int
+	calls      int
+}
+
+// fleetSystemClient answers Time after delay, or fails if fail is set.
+type fleetSystemClient struct {
+	spb.SystemClient
+	tracker *fleetTracker
+	delay   time.Duration
+	fail    bool
+}
+
+func (fc *fleetSystemClient) Time(ctx context.Context, _ *spb.TimeRequest, _ ...grpc.CallOption) (*spb.TimeResponse, error) {
+	fc.tracker.mu.Lock()
+	fc.tracker.calls++
+	fc.tracker.running++
+	fc.tracker.maxRunning = max(fc.tracker.maxRunning, fc.tracker.running)
+	fc.tracker.mu.Unlock()
+	defer func() {
+		fc.tracker.mu.Lock()
+		fc.tracker.running--
+		fc.tracker.mu.Unlock()
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, status.FromContextError(ctx.Err()).Err()
+	case <-time.After(fc.delay):
+	}
+	if fc.fail {
+		return nil, status.Error(codes.Internal, "boom")
+	}
+	return &spb.TimeResponse{Time: 1}, nil
+}
+
+func newFleet(tracker *fleetTracker, n int, delay time.Duration, failing ...string) map[string]gnoigo.Clients {
+	targets := map[string]gnoigo.Clients{}
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("dut%02d", i)
+		fc := &fleetSystemClient{tracker: tracker, delay: delay}
+		for _, f := range failing {
+			fc.fail = fc.fail || f == name
+		}
+		targets[name] = &internal.Clients{SystemClient: fc}
+	}
+	return targets
+}
+
+func TestExecuteAll(t *testing.T) {
+	tracker := &fleetTracker{}
+	fleet := gnoigo.NewFleet(newFleet(tracker, 12, 10*time.Millisecond, "dut03")).Concurrency(4)
+	got, err := gnoigo.ExecuteAll(context.Background(), fleet, system.NewTimeOperation())
+	if err == nil || !strings.Contains(err.Error(), "target dut03") {
+		t.Errorf("ExecuteAll() got error %v, want error for dut03", err)
+	}
+	if len(got) != 12 {
+		t.Fatalf("ExecuteAll() got %d results, want 12", len(got))
+	}
+	for name, res := range got {
+		if wantErr := name == "dut03"; (res.Err != nil) != wantErr {
+			t.Errorf("ExecuteAll() target %s got error %v, want error %v", name, res.Err, wantErr)
+		}
+		if res.Err == nil && res.Response.GetTime() != 1 {
+			t.Errorf("ExecuteAll() target %s got response %v", name, res.Response)
+		}
+		if res.Duration <= 0 {
+			t.Errorf("ExecuteAll() target %s got duration %v", name, res.Duration)
+		}
+	}
+	if tracker.maxRunning > 4 {
+		t.Errorf("ExecuteAll() ran %d targets concurrently, want at most 4", tracker.maxRunning)
+	}
+}
+
+func TestExecuteEach(t *testing.T) {
+	tracker := &fleetTracker{}
+	fleet := gnoigo.NewFleet(newFleet(tracker, 3, 0))
+	var mu sync.Mutex
+	var names []string
+	got, err := gnoigo.ExecuteEach(context.Background(), fleet, func(name string) gnoigo.Operation[*spb.TimeResponse] {
+		mu.Lock()
+		defer mu.Unlock()
+		names = append(names, name)
+		return system.NewTimeOperation()
+	})
+	if err != nil {
+		t.Fatalf("ExecuteEach() failed: %v", err)
+	}
+	slices.Sort(names)
+	if want := []string{"dut00", "dut01", "dut02"}; !slices.Equal(names, want) {
+		t.Errorf("ExecuteEach() created operations for %v, want %v", names, want)
+	}
+	if len(got) != 3 || tracker.calls != 3 {
+		t.Errorf("ExecuteEach() got %d results from %d calls, want 3", len(got), tracker.calls)
+	}
+}
+
+func TestExecuteAllFailFast(t *testing.T) {
+	tracker := &fleetTracker{}
+	fleet := gnoigo.NewFleet(newFleet(tracker, 10, 20*time.Millisecond, "dut00")).Concurrency(1).FailFast(true)
+	got, err := gnoigo.ExecuteAll(context.Background(), fleet, system.NewTimeOperation())
+	if !errors.Is(err, gnoigo.ErrSkipped) {
+		t.Errorf("ExecuteAll() got error %v, want ErrSkipped", err)
+	}
+	if tracker.calls != 1 {
+		t.Errorf("ExecuteAll() made %d calls, want 1", tracker.calls)
+	}
+	for name, res := range got {
+		if name != "dut00" && !errors.Is(res.Err, gnoigo.ErrSkipped) {
+			t.Errorf("ExecuteAll() target %s got error %v, want ErrSkipped", name, res.Err)
+		}
+	}
+}
+
+func TestExecuteAllTimeout(t *testing.T) {
+	tracker := &fleetTracker{}
+	fleet := gnoigo.NewFleet(newFleet(tracker, 3, time.Minute)).Timeout(10 * time.Millisecond)
+	got, err := gnoigo.ExecuteAll(context.Background(), fleet, system.NewTimeOperation())
+	if err == nil {
+		t.Fatalf("ExecuteAll() got no error, want timeouts")
+	}
+	for name, res := range got {
+		if status.Code(res.Err) != codes.DeadlineExceeded {
+			t.Errorf("ExecuteAll() target %s got error %v, want deadline exceeded", name, res.Err)
+		}
+	}
+}
+
+func TestExecuteAllPolicy(t *testing.T) {
+	tracker := &fleetTracker{}
+	policy := gnoigo.NewPolicy(func(_ context.Context, c *gnoigo.Check) error {
+		if c.Target.Labels["env"] == "production" {
+			return &gnoigo.PolicyDenied{Check: *c, Reason: "frozen"}
+		}
+		return nil
+	})
+	fleet := gnoigo.NewFleet(newFleet(tracker, 2, 0)).Labels("dut01", map[string]string{"env": "production"}).Options(gnoigo.WithPolicy(policy))
+	got, _ := gnoigo.ExecuteAll(context.Background(), fleet, system.NewTimeOperation())
+	var denied *gnoigo.PolicyDenied
+	if !errors.As(got["dut01"].Err, &denied) || denied.Target.Name != "dut01" {
+		t.Errorf("ExecuteAll() target dut01 got error %v, want PolicyDenied", got["dut01"].Err)
+	}
+	if got["dut00"].Err != nil {
+		t.Errorf("ExecuteAll() target dut00 got error %v, want nil", got["dut00"].Err)
+	}
+}
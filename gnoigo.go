@@ -17,6 +17,8 @@ package gnoigo
 
 import (
 	"context"
+	"fmt"
+	"slices"
 
 	"google.golang.org/grpc"
 
@@ -102,8 +104,84 @@ func toInternalClients(c Clients) *internal.Clients {
 	}
 }
 
+// ExecuteOption configures the execution of an operation.
+type ExecuteOption func(*executeOptions)
+
+type executeOptions struct {
+	target      Target
+	policy      *Policy
+	middlewares []Middleware
+}
+
+// WithTarget describes the target the operation is executed against, which
+// is used to evaluate the policy.
+func WithTarget(t Target) ExecuteOption {
+	return func(o *executeOptions) {
+		o.target = t
+	}
+}
+
+// WithPolicy specifies a policy that is evaluated before the operation is
+// executed. If the policy denies the operation, no RPC is sent.
+func WithPolicy(p *Policy) ExecuteOption {
+	return func(o *executeOptions) {
+		o.policy = p
+	}
+}
+
+// WithMiddleware adds middlewares that wrap the execution of the operation.
+// The first middleware is the outermost one. The policy is evaluated inside
+// all middlewares, so they observe PolicyDenied errors.
+func WithMiddleware(mw ...Middleware) ExecuteOption {
+	return func(o *executeOptions) {
+		o.middlewares = append(o.middlewares, mw...)
+	}
+}
+
+// Executor is a set of clients with options applied to every operation
+// executed with it.
+type Executor struct {
+	Clients
+	opts []ExecuteOption
+}
+
+// NewExecutor creates an Executor that applies opts to every operation
+// executed against c.
+func NewExecutor(c Clients, opts ...ExecuteOption) *Executor {
+	return &Executor{Clients: c, opts: opts}
+}
+
 // Execute performs an operation and returns one or more response protos.
 // For example, a PingOperation returns a slice of PingResponse messages.
-func Execute[T any](ctx context.Context, c Clients, op Operation[T]) (T, error) {
-	return op.Execute(ctx, toInternalClients(c))
+// If c is an Executor, its options are applied before opts.
+func Execute[T any](ctx context.Context, c Clients, op Operation[T], opts ...ExecuteOption) (T, error) {
+	if e, ok := c.(*Executor); ok {
+		c = e.Clients
+		opts = append(slices.Clone(e.opts), opts...)
+	}
+	o := &executeOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	clients := toInternalClients(c)
+	var h Handler = func(ctx context.Context, _ *Call) (any, error) {
+		return op.Execute(ctx, clients)
+	}
+	if o.policy != nil {
+		h = o.policy.middleware(h)
+	}
+	for i := len(o.middlewares) - 1; i >= 0; i-- {
+		h = o.middlewares[i](h)
+	}
+
+	var zero T
+	res, err := h(ctx, newCall(op, o.target))
+	if res == nil {
+		return zero, err
+	}
+	t, ok := res.(T)
+	if !ok {
+		return zero, fmt.Errorf("middleware returned result of type %T, want %T", res, zero)
+	}
+	return t, err
 }
@@ -0,0 +1,306 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthz
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	hpb "github.com/openconfig/gnoi/healthz"
+	tpb "github.com/openconfig/gnoi/types"
+	"github.com/openconfig/gnoigo/internal"
+)
+
+// ManifestFile is the name of the JSON manifest in an evidence bundle.
+const ManifestFile = "manifest.json"
+
+// Manifest describes the contents of an evidence bundle.
+type Manifest struct {
+	Collected  time.Time            `json:"collected"`
+	Root       string               `json:"root"`
+	Components []*ManifestComponent `json:"components"`
+}
+
+// ManifestComponent describes the state of an unhealthy component.
+type ManifestComponent struct {
+	Path         string              `json:"path"`
+	ID           string              `json:"id"`
+	Status       string              `json:"status"`
+	Acknowledged bool                `json:"acknowledged"`
+	Created      *time.Time          `json:"created,omitempty"`
+	Expires      *time.Time          `json:"expires,omitempty"`
+	Artifacts    []*ManifestArtifact `json:"artifacts,omitempty"`
+}
+
+// ManifestArtifact describes an artifact stored in an evidence bundle.
+type ManifestArtifact struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+	// File is the name of the artifact in the bundle.
+	File string `json:"file"`
+	Size int64  `json:"size"`
+}
+
+// BundleOperation represents the parameters of an evidence collection. It
+// gets every unhealthy component under a root path, downloads all their
+// artifacts and writes them with a manifest to a tar.gz archive.
+type BundleOperation struct {
+	root        *tpb.Path
+	w           io.Writer
+	acknowledge bool
+}
+
+// NewBundleOperation creates an empty BundleOperation.
+func NewBundleOperation() *BundleOperation {
+	return &BundleOperation{}
+}
+
+// PathFromSubcomponentName sets the root path to `/openconfig/components/component[name=<n>]`.
+func (b *BundleOperation) PathFromSubcomponentName(n string) *BundleOperation {
+	return b.Path(ComponentPath(n))
+}
+
+// Path sets the root path under which to collect unhealthy components.
+func (b *BundleOperation) Path(p *tpb.Path) *BundleOperation {
+	b.root = p
+	return b
+}
+
+// Writer specifies where to write the tar.gz archive.
+func (b *BundleOperation) Writer(w io.Writer) *BundleOperation {
+	b.w = w
+	return b
+}
+
+// Acknowledge specifies whether to acknowledge the collected events once the
+// archive is written.
+func (b *BundleOperation) Acknowledge(acknowledge bool) *BundleOperation {
+	b.acknowledge = acknowledge
+	return b
+}
+
+// Execute performs the Bundle operation and returns the manifest written to
+// the archive. File artifacts are streamed into the archive and other
+// artifacts are buffered in temporary files, so artifacts are never held in
+// memory. If Execute fails before the manifest is written, the archive is
+// left incomplete and whatever was written to the writer must be discarded.
+func (b *BundleOperation) Execute(ctx context.Context, c *internal.Clients) (*Manifest, error) {
+	if b.w == nil {
+		return nil, errors.New("no writer specified for bundle")
+	}
+	list, err := NewListOperation().Path(b.root).Execute(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	var unhealthy []*hpb.ComponentStatus
+	for _, s := range list.GetStatuses() {
+		unhealthy = appendUnhealthy(unhealthy, s)
+	}
+
+	manifest := &Manifest{Collected: time.Now(), Root: PathString(b.root), Components: []*ManifestComponent{}}
+	gw := gzip.NewWriter(b.w)
+	tw := tar.NewWriter(gw)
+	var statuses []*hpb.ComponentStatus
+	for i, s := range unhealthy {
+		resp, err := NewGetOperation().Path(s.GetPath()).Execute(ctx, c)
+		if err != nil {
+			return nil, fmt.Errorf("error getting %s: %w", PathString(s.GetPath()), err)
+		}
+		status := resp.GetComponent()
+		statuses = append(statuses, status)
+		mc := newManifestComponent(status)
+		dir := fmt.Sprintf("%03d-%s", i, sanitize(status.GetId()))
+		for _, h := range status.GetArtifacts() {
+			a, err := b.writeArtifact(ctx, c, tw, dir, h)
+			if err != nil {
+				return nil, err
+			}
+			mc.Artifacts = append(mc.Artifacts, a)
+		}
+		manifest.Components = append(manifest.Components, mc)
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFile(tw, ManifestFile, manifest.Collected, data); err != nil {
+		return nil, err
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gw.Close(); err != nil {
+		return nil, err
+	}
+
+	if b.acknowledge {
+		var errs []error
+		for _, s := range statuses {
+			if s.GetAcknowledged() {
+				continue
+			}
+			if _, err := NewAcknowledgeOperation().Path(s.GetPath()).ID(s.GetId()).Execute(ctx, c); err != nil {
+				errs = append(errs, fmt.Errorf("error acknowledging %s event %q: %w", PathString(s.GetPath()), s.GetId(), err))
+			}
+		}
+		if err := errors.Join(errs...); err != nil {
+			return manifest, err
+		}
+	}
+	return manifest, nil
+}
+
+func (b *BundleOperation) writeArtifact(ctx context.Context, c *internal.Clients, tw *tar.Writer, dir string, h *hpb.ArtifactHeader) (*ManifestArtifact, error) {
+	a := &ManifestArtifact{ID: h.GetId()}
+	name := sanitize(h.GetId())
+	switch {
+	case h.GetFile() != nil:
+		a.Type = "file"
+		a.Name = h.GetFile().GetName()
+		if n := path.Base(a.Name); n != "." && n != "/" {
+			name += "-" + sanitize(n)
+		}
+	case h.GetProto() != nil:
+		a.Type = "proto"
+		name += ".pb"
+	default:
+		a.Type = "custom"
+	}
+	a.File = path.Join(dir, name)
+
+	if h.GetFile().GetSize() > 0 {
+		// The size of a file artifact is usually known up front, so it is
+		// written straight into the archive entry. Artifacts of unknown size
+		// are buffered to learn it.
+		a.Size = h.GetFile().GetSize()
+		if err := tw.WriteHeader(&tar.Header{Name: a.File, Mode: 0644, Size: a.Size, ModTime: time.Now()}); err != nil {
+			return nil, err
+		}
+		header, err := NewArtifactOperation().ID(h.GetId()).Writer(tw).Execute(ctx, c)
+		if err != nil {
+			return nil, fmt.Errorf("error downloading artifact %q: %w", h.GetId(), err)
+		}
+		if got := header.GetFile().GetSize(); got != a.Size {
+			return nil, fmt.Errorf("artifact %q has size %d, want %d as listed by Get", h.GetId(), got, a.Size)
+		}
+		return a, nil
+	}
+
+	tmp, err := os.CreateTemp("", "healthz-artifact-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+	if _, err := NewArtifactOperation().ID(h.GetId()).Writer(tmp).Execute(ctx, c); err != nil {
+		return nil, fmt.Errorf("error downloading artifact %q: %w", h.GetId(), err)
+	}
+	if a.Size, err = tmp.Seek(0, io.SeekCurrent); err != nil {
+		return nil, err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	if err := tw.WriteHeader(&tar.Header{Name: a.File, Mode: 0644, Size: a.Size, ModTime: time.Now()}); err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(tw, tmp); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func writeFile(tw *tar.Writer, name string, modTime time.Time, data []byte) error {
+	if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), ModTime: modTime}); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+// appendUnhealthy appends s and its subcomponents that are unhealthy.
+func appendUnhealthy(dst []*hpb.ComponentStatus, s *hpb.ComponentStatus) []*hpb.ComponentStatus {
+	if s.GetStatus() == hpb.Status_STATUS_UNHEALTHY {
+		dst = append(dst, s)
+	}
+	for _, sub := range s.GetSubcomponents() {
+		dst = appendUnhealthy(dst, sub)
+	}
+	return dst
+}
+
+func newManifestComponent(s *hpb.ComponentStatus) *ManifestComponent {
+	mc := &ManifestComponent{
+		Path:         PathString(s.GetPath()),
+		ID:           s.GetId(),
+		Status:       s.GetStatus().String(),
+		Acknowledged: s.GetAcknowledged(),
+	}
+	if s.GetCreated() != nil {
+		t := s.GetCreated().AsTime()
+		mc.Created = &t
+	}
+	if s.GetExpires() != nil {
+		t := s.GetExpires().AsTime()
+		mc.Expires = &t
+	}
+	return mc
+}
+
+// PathString returns the path in the `/elem[key=value]/elem` form.
+func PathString(p *tpb.Path) string {
+	var b strings.Builder
+	for _, e := range p.GetElem() {
+		b.WriteString("/")
+		b.WriteString(e.GetName())
+		keys := make([]string, 0, len(e.GetKey()))
+		for k := range e.GetKey() {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(&b, "[%s=%s]", k, e.GetKey()[k])
+		}
+	}
+	if b.Len() == 0 {
+		return "/"
+	}
+	return b.String()
+}
+
+// sanitize makes s safe to use as a single archive path element.
+func sanitize(s string) string {
+	if s == "" || s == "." || s == ".." {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', 0:
+			return '_'
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,162 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthz_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	hpb "github.com/openconfig/gnoi/healthz"
+	"github.com/openconfig/gnoigo/healthz"
+	"github.com/openconfig/gnoigo/internal"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func readBundle(t *testing.T, r io.Reader) map[string][]byte {
+	t.Helper()
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		t.Fatalf("gzip.NewReader() failed: %v", err)
+	}
+	files := map[string][]byte{}
+	tr := tar.NewReader(gr)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return files
+		}
+		if err != nil {
+			t.Fatalf("tar.Next() failed: %v", err)
+		}
+		b, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatalf("io.ReadAll() failed: %v", err)
+		}
+		files[h.Name] = b
+	}
+}
+
+func TestBundle(t *testing.T) {
+	created := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	fan := &hpb.ComponentStatus{Path: healthz.ComponentPath("FAN0"), Status: hpb.Status_STATUS_HEALTHY}
+	lc := &hpb.ComponentStatus{Path: healthz.ComponentPath("LC0"), Status: hpb.Status_STATUS_UNHEALTHY, Id: "ev-lc"}
+	rp := &hpb.ComponentStatus{Path: rp0Path, Status: hpb.Status_STATUS_UNHEALTHY, Id: "ev-rp", Subcomponents: []*hpb.ComponentStatus{lc}}
+	coreDump := []byte("core dump contents")
+	// The trace is a file artifact whose size the target does not report.
+	trace := []byte("trace contents")
+	traceHeader := fileHeader("trace", trace)
+	traceHeader.GetHeader().GetFile().Size = 0
+	event, err := anypb.New(&hpb.ArtifactHeader{Id: "event"})
+	if err != nil {
+		t.Fatalf("anypb.New() failed: %v", err)
+	}
+	var events bytes.Buffer
+	if _, err := protodelim.MarshalTo(&events, event); err != nil {
+		t.Fatalf("protodelim.MarshalTo() failed: %v", err)
+	}
+	eventsHeader := &hpb.ArtifactHeader{Id: "events", ArtifactType: &hpb.ArtifactHeader_Proto{Proto: &hpb.ProtoArtifactType{}}}
+	artifacts := map[string][]*hpb.ArtifactResponse{
+		"core":  {fileHeader("core", coreDump), bytesResp(coreDump), trailerResp},
+		"trace": {traceHeader, bytesResp(trace), trailerResp},
+		"events": {
+			{Contents: &hpb.ArtifactResponse_Header{Header: eventsHeader}},
+			{Contents: &hpb.ArtifactResponse_Proto{Proto: event}},
+			trailerResp,
+		},
+	}
+	details := map[string]*hpb.ComponentStatus{
+		"/components/component[name=RP0]": {
+			Path: rp0Path, Status: hpb.Status_STATUS_UNHEALTHY, Id: "ev-rp", Created: timestamppb.New(created),
+			Artifacts: []*hpb.ArtifactHeader{fileHeader("core", coreDump).GetHeader(), eventsHeader, traceHeader.GetHeader()},
+		},
+		"/components/component[name=LC0]": {Path: healthz.ComponentPath("LC0"), Status: hpb.Status_STATUS_UNHEALTHY, Id: "ev-lc", Acknowledged: true},
+	}
+
+	for _, ack := range []bool{false, true} {
+		var gotAcks []string
+		var fakeClient internal.Clients
+		fakeClient.HealthzClient = &fakeHealthzClient{
+			ListFn: func(context.Context, *hpb.ListRequest, ...grpc.CallOption) (*hpb.ListResponse, error) {
+				return &hpb.ListResponse{Statuses: []*hpb.ComponentStatus{rp, fan}}, nil
+			},
+			GetFn: func(_ context.Context, in *hpb.GetRequest, _ ...grpc.CallOption) (*hpb.GetResponse, error) {
+				return &hpb.GetResponse{Component: details[healthz.PathString(in.GetPath())]}, nil
+			},
+			ArtifactFn: func(_ context.Context, in *hpb.ArtifactRequest, _ ...grpc.CallOption) (hpb.Healthz_ArtifactClient, error) {
+				return &fakeArtifactClient{resp: artifacts[in.GetId()]}, nil
+			},
+			AcknowledgeFn: func(_ context.Context, in *hpb.AcknowledgeRequest, _ ...grpc.CallOption) (*hpb.AcknowledgeResponse, error) {
+				gotAcks = append(gotAcks, in.GetId())
+				return &hpb.AcknowledgeResponse{}, nil
+			},
+		}
+
+		var buf bytes.Buffer
+		manifest, err := healthz.NewBundleOperation().Path(nil).Writer(&buf).Acknowledge(ack).Execute(context.Background(), &fakeClient)
+		if err != nil {
+			t.Fatalf("Execute() failed: %v", err)
+		}
+		want := []*healthz.ManifestComponent{
+			{
+				Path: "/components/component[name=RP0]", ID: "ev-rp", Status: "STATUS_UNHEALTHY", Created: &created,
+				Artifacts: []*healthz.ManifestArtifact{
+					{ID: "core", Type: "file", Name: "core.log", File: "000-ev-rp/core-core.log", Size: int64(len(coreDump))},
+					{ID: "events", Type: "proto", File: "000-ev-rp/events.pb", Size: int64(events.Len())},
+					{ID: "trace", Type: "file", Name: "trace.log", File: "000-ev-rp/trace-trace.log", Size: int64(len(trace))},
+				},
+			},
+			{Path: "/components/component[name=LC0]", ID: "ev-lc", Status: "STATUS_UNHEALTHY", Acknowledged: true},
+		}
+		if diff := cmp.Diff(want, manifest.Components); diff != "" {
+			t.Errorf("Execute() got unexpected manifest diff (-want +got): %s", diff)
+		}
+
+		files := readBundle(t, &buf)
+		if got := files["000-ev-rp/core-core.log"]; !bytes.Equal(got, coreDump) {
+			t.Errorf("bundle artifact got %q, want %q", got, coreDump)
+		}
+		if got := files["000-ev-rp/events.pb"]; !bytes.Equal(got, events.Bytes()) {
+			t.Errorf("bundle artifact got %q, want %q", got, events.Bytes())
+		}
+		if got := files["000-ev-rp/trace-trace.log"]; !bytes.Equal(got, trace) {
+			t.Errorf("bundle artifact got %q, want %q", got, trace)
+		}
+		var gotManifest healthz.Manifest
+		if err := json.Unmarshal(files[healthz.ManifestFile], &gotManifest); err != nil {
+			t.Fatalf("json.Unmarshal() of manifest failed: %v", err)
+		}
+		if diff := cmp.Diff(want, gotManifest.Components); diff != "" {
+			t.Errorf("bundle got unexpected manifest diff (-want +got): %s", diff)
+		}
+
+		var wantAcks []string
+		if ack {
+			wantAcks = []string{"ev-rp"}
+		}
+		if diff := cmp.Diff(wantAcks, gotAcks); diff != "" {
+			t.Errorf("Execute() acknowledged unexpected events diff (-want +got): %s", diff)
+		}
+	}
+}
@@ -0,0 +1,320 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package healthz provides gNOI healthz operations.
+package healthz
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+
+	hpb "github.com/openconfig/gnoi/healthz"
+	tpb "github.com/openconfig/gnoi/types"
+	"github.com/openconfig/gnoigo/internal"
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/proto"
+)
+
+// ComponentPath returns the path `/openconfig/components/component[name=<n>]`.
+func ComponentPath(n string) *tpb.Path {
+	return internal.ComponentPath(n)
+}
+
+// GetOperation represents the parameters of a Get operation.
+type GetOperation struct {
+	req *hpb.GetRequest
+}
+
+// NewGetOperation creates an empty GetOperation.
+func NewGetOperation() *GetOperation {
+	return &GetOperation{req: &hpb.GetRequest{}}
+}
+
+// PathFromSubcomponentName sets the path of the component to `/openconfig/components/component[name=<n>]`.
+func (g *GetOperation) PathFromSubcomponentName(n string) *GetOperation {
+	return g.Path(ComponentPath(n))
+}
+
+// Path sets the path of the component.
+func (g *GetOperation) Path(p *tpb.Path) *GetOperation {
+	g.req.Path = p
+	return g
+}
+
+// Execute performs the Get operation.
+func (g *GetOperation) Execute(ctx context.Context, c *internal.Clients) (*hpb.GetResponse, error) {
+	return c.Healthz().Get(ctx, g.req)
+}
+
+// Request returns the request proto of the Get operation.
+func (g *GetOperation) Request() proto.Message {
+	return g.req
+}
+
+// Idempotent reports that the Get operation can safely be retried.
+func (g *GetOperation) Idempotent() bool {
+	return true
+}
+
+// ListOperation represents the parameters of a List operation.
+type ListOperation struct {
+	req *hpb.ListRequest
+}
+
+// NewListOperation creates an empty ListOperation.
+func NewListOperation() *ListOperation {
+	return &ListOperation{req: &hpb.ListRequest{}}
+}
+
+// PathFromSubcomponentName sets the path of the component to `/openconfig/components/component[name=<n>]`.
+func (l *ListOperation) PathFromSubcomponentName(n string) *ListOperation {
+	return l.Path(ComponentPath(n))
+}
+
+// Path sets the path of the component.
+func (l *ListOperation) Path(p *tpb.Path) *ListOperation {
+	l.req.Path = p
+	return l
+}
+
+// IncludeAcknowledged specifies whether to include acknowledged events.
+func (l *ListOperation) IncludeAcknowledged(include bool) *ListOperation {
+	l.req.IncludeAcknowledged = include
+	return l
+}
+
+// Execute performs the List operation.
+func (l *ListOperation) Execute(ctx context.Context, c *internal.Clients) (*hpb.ListResponse, error) {
+	return c.Healthz().List(ctx, l.req)
+}
+
+// Request returns the request proto of the List operation.
+func (l *ListOperation) Request() proto.Message {
+	return l.req
+}
+
+// Idempotent reports that the List operation can safely be retried.
+func (l *ListOperation) Idempotent() bool {
+	return true
+}
+
+// CheckOperation represents the parameters of a Check operation.
+type CheckOperation struct {
+	req *hpb.CheckRequest
+}
+
+// NewCheckOperation creates an empty CheckOperation.
+func NewCheckOperation() *CheckOperation {
+	return &CheckOperation{req: &hpb.CheckRequest{}}
+}
+
+// PathFromSubcomponentName sets the path of the component to `/openconfig/components/component[name=<n>]`.
+func (ch *CheckOperation) PathFromSubcomponentName(n string) *CheckOperation {
+	return ch.Path(ComponentPath(n))
+}
+
+// Path sets the path of the component.
+func (ch *CheckOperation) Path(p *tpb.Path) *CheckOperation {
+	ch.req.Path = p
+	return ch
+}
+
+// EventID specifies the ID of an existing event to re-run the check for.
+func (ch *CheckOperation) EventID(id string) *CheckOperation {
+	ch.req.EventId = id
+	return ch
+}
+
+// Execute performs the Check operation.
+func (ch *CheckOperation) Execute(ctx context.Context, c *internal.Clients) (*hpb.CheckResponse, error) {
+	return c.Healthz().Check(ctx, ch.req)
+}
+
+// Request returns the request proto of the Check operation.
+func (ch *CheckOperation) Request() proto.Message {
+	return ch.req
+}
+
+// AcknowledgeOperation represents the parameters of an Acknowledge operation.
+type AcknowledgeOperation struct {
+	req *hpb.AcknowledgeRequest
+}
+
+// NewAcknowledgeOperation creates an empty AcknowledgeOperation.
+func NewAcknowledgeOperation() *AcknowledgeOperation {
+	return &AcknowledgeOperation{req: &hpb.AcknowledgeRequest{}}
+}
+
+// PathFromSubcomponentName sets the path of the component to `/openconfig/components/component[name=<n>]`.
+func (a *AcknowledgeOperation) PathFromSubcomponentName(n string) *AcknowledgeOperation {
+	return a.Path(ComponentPath(n))
+}
+
+// Path sets the path of the component.
+func (a *AcknowledgeOperation) Path(p *tpb.Path) *AcknowledgeOperation {
+	a.req.Path = p
+	return a
+}
+
+// ID specifies the ID of the event to acknowledge.
+func (a *AcknowledgeOperation) ID(id string) *AcknowledgeOperation {
+	a.req.Id = id
+	return a
+}
+
+// Execute performs the Acknowledge operation.
+func (a *AcknowledgeOperation) Execute(ctx context.Context, c *internal.Clients) (*hpb.AcknowledgeResponse, error) {
+	return c.Healthz().Acknowledge(ctx, a.req)
+}
+
+// Request returns the request proto of the Acknowledge operation.
+func (a *AcknowledgeOperation) Request() proto.Message {
+	return a.req
+}
+
+// ArtifactOperation represents the parameters of an Artifact operation.
+type ArtifactOperation struct {
+	req *hpb.ArtifactRequest
+	w   io.Writer
+}
+
+// NewArtifactOperation creates an empty ArtifactOperation.
+func NewArtifactOperation() *ArtifactOperation {
+	return &ArtifactOperation{req: &hpb.ArtifactRequest{}}
+}
+
+// ID specifies the ID of the artifact to download.
+func (a *ArtifactOperation) ID(id string) *ArtifactOperation {
+	a.req.Id = id
+	return a
+}
+
+// Writer specifies where to write the artifact contents. File artifacts are
+// written as received. Proto artifact messages are written as a sequence of
+// google.protobuf.Any messages, each in wire format prefixed by its varint
+// encoded size, which protodelim.UnmarshalFrom reads back one at a time.
+func (a *ArtifactOperation) Writer(w io.Writer) *ArtifactOperation {
+	a.w = w
+	return a
+}
+
+// Execute performs the Artifact operation and returns the header of the
+// artifact. The contents of file artifacts are verified against the size and
+// hash in the header.
+func (a *ArtifactOperation) Execute(ctx context.Context, c *internal.Clients) (*hpb.ArtifactHeader, error) {
+	if a.w == nil {
+		return nil, errors.New("no writer specified for artifact")
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	ac, err := c.Healthz().Artifact(ctx, a.req)
+	if err != nil {
+		return nil, err
+	}
+
+	var header *hpb.ArtifactHeader
+	var hasher hash.Hash
+	var size int64
+	for {
+		resp, err := ac.Recv()
+		if err == io.EOF {
+			return nil, fmt.Errorf("artifact %q stream ended before trailer", a.req.GetId())
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch contents := resp.GetContents().(type) {
+		case *hpb.ArtifactResponse_Header:
+			if header != nil {
+				return nil, fmt.Errorf("artifact %q received duplicate header", a.req.GetId())
+			}
+			header = contents.Header
+			if hasher, err = newHasher(header.GetFile().GetHash().GetMethod()); err != nil {
+				return nil, err
+			}
+		case *hpb.ArtifactResponse_Bytes:
+			if header == nil {
+				return nil, fmt.Errorf("artifact %q received contents before header", a.req.GetId())
+			}
+			if hasher != nil {
+				hasher.Write(contents.Bytes)
+			}
+			size += int64(len(contents.Bytes))
+			if _, err := a.w.Write(contents.Bytes); err != nil {
+				return nil, err
+			}
+		case *hpb.ArtifactResponse_Proto:
+			if header == nil {
+				return nil, fmt.Errorf("artifact %q received contents before header", a.req.GetId())
+			}
+			if _, err := protodelim.MarshalTo(a.w, contents.Proto); err != nil {
+				return nil, err
+			}
+		case *hpb.ArtifactResponse_Trailer:
+			if header == nil {
+				return nil, fmt.Errorf("artifact %q received trailer before header", a.req.GetId())
+			}
+			if err := verifyFile(header.GetFile(), size, hasher); err != nil {
+				return nil, fmt.Errorf("artifact %q: %w", a.req.GetId(), err)
+			}
+			return header, nil
+		default:
+			return nil, fmt.Errorf("artifact %q received unexpected response %T", a.req.GetId(), contents)
+		}
+	}
+}
+
+// Request returns the request proto of the Artifact operation.
+func (a *ArtifactOperation) Request() proto.Message {
+	return a.req
+}
+
+// newHasher returns the hash for the given method, or nil if the method is
+// unspecified.
+func newHasher(m tpb.HashType_HashMethod) (hash.Hash, error) {
+	switch m {
+	case tpb.HashType_UNSPECIFIED:
+		return nil, nil
+	case tpb.HashType_SHA256:
+		return sha256.New(), nil
+	case tpb.HashType_SHA512:
+		return sha512.New(), nil
+	case tpb.HashType_MD5:
+		return md5.New(), nil
+	default:
+		return nil, fmt.Errorf("unsupported hash method %v", m)
+	}
+}
+
+func verifyFile(f *hpb.FileArtifactType, size int64, hasher hash.Hash) error {
+	if f == nil {
+		return nil
+	}
+	if f.GetSize() > 0 && f.GetSize() != size {
+		return fmt.Errorf("received %d bytes, want %d", size, f.GetSize())
+	}
+	if hasher != nil {
+		if got, want := hasher.Sum(nil), f.GetHash().GetHash(); !bytes.Equal(got, want) {
+			return fmt.Errorf("%v hash mismatch: got %x, want %x", f.GetHash().GetMethod(), got, want)
+		}
+	}
+	return nil
+}
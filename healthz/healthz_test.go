@@ -0,0 +1,301 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package healthz_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	hpb "github.com/openconfig/gnoi/healthz"
+	tpb "github.com/openconfig/gnoi/types"
+	"github.com/openconfig/gnoigo/healthz"
+	"github.com/openconfig/gnoigo/internal"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+type fakeHealthzClient struct {
+	hpb.HealthzClient
+	GetFn         func(context.Context, *hpb.GetRequest, ...grpc.CallOption) (*hpb.GetResponse, error)
+	ListFn        func(context.Context, *hpb.ListRequest, ...grpc.CallOption) (*hpb.ListResponse, error)
+	AcknowledgeFn func(context.Context, *hpb.AcknowledgeRequest, ...grpc.CallOption) (*hpb.AcknowledgeResponse, error)
+	ArtifactFn    func(context.Context, *hpb.ArtifactRequest, ...grpc.CallOption) (hpb.Healthz_ArtifactClient, error)
+	CheckFn       func(context.Context, *hpb.CheckRequest, ...grpc.CallOption) (*hpb.CheckResponse, error)
+}
+
+func (fc *fakeHealthzClient) Get(ctx context.Context, in *hpb.GetRequest, opts ...grpc.CallOption) (*hpb.GetResponse, error) {
+	return fc.GetFn(ctx, in, opts...)
+}
+
+func (fc *fakeHealthzClient) List(ctx context.Context, in *hpb.ListRequest, opts ...grpc.CallOption) (*hpb.ListResponse, error) {
+	return fc.ListFn(ctx, in, opts...)
+}
+
+func (fc *fakeHealthzClient) Acknowledge(ctx context.Context, in *hpb.AcknowledgeRequest, opts ...grpc.CallOption) (*hpb.AcknowledgeResponse, error) {
+	return fc.AcknowledgeFn(ctx, in, opts...)
+}
+
+func (fc *fakeHealthzClient) Artifact(ctx context.Context, in *hpb.ArtifactRequest, opts ...grpc.CallOption) (hpb.Healthz_ArtifactClient, error) {
+	return fc.ArtifactFn(ctx, in, opts...)
+}
+
+func (fc *fakeHealthzClient) Check(ctx context.Context, in *hpb.CheckRequest, opts ...grpc.CallOption) (*hpb.CheckResponse, error) {
+	return fc.CheckFn(ctx, in, opts...)
+}
+
+type fakeArtifactClient struct {
+	hpb.Healthz_ArtifactClient
+	resp []*hpb.ArtifactResponse
+}
+
+func (ac *fakeArtifactClient) Recv() (*hpb.ArtifactResponse, error) {
+	if len(ac.resp) == 0 {
+		return nil, io.EOF
+	}
+	resp := ac.resp[0]
+	ac.resp = ac.resp[1:]
+	return resp, nil
+}
+
+var rp0Path = &tpb.Path{
+	Origin: "openconfig",
+	Elem: []*tpb.PathElem{
+		{Name: "components"},
+		{Name: "component", Key: map[string]string{"name": "RP0"}},
+	},
+}
+
+func TestGet(t *testing.T) {
+	var gotReq *hpb.GetRequest
+	var fakeClient internal.Clients
+	want := &hpb.GetResponse{Component: &hpb.ComponentStatus{Path: rp0Path, Status: hpb.Status_STATUS_HEALTHY}}
+	fakeClient.HealthzClient = &fakeHealthzClient{GetFn: func(_ context.Context, in *hpb.GetRequest, _ ...grpc.CallOption) (*hpb.GetResponse, error) {
+		gotReq = in
+		return want, nil
+	}}
+
+	got, err := healthz.NewGetOperation().PathFromSubcomponentName("RP0").Execute(context.Background(), &fakeClient)
+	if err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+	if diff := cmp.Diff(&hpb.GetRequest{Path: rp0Path}, gotReq, protocmp.Transform()); diff != "" {
+		t.Errorf("Execute() sent unexpected request diff (-want +got): %s", diff)
+	}
+	if diff := cmp.Diff(want, got, protocmp.Transform()); diff != "" {
+		t.Errorf("Execute() got unexpected response diff (-want +got): %s", diff)
+	}
+}
+
+func TestList(t *testing.T) {
+	var gotReq *hpb.ListRequest
+	var fakeClient internal.Clients
+	fakeClient.HealthzClient = &fakeHealthzClient{ListFn: func(_ context.Context, in *hpb.ListRequest, _ ...grpc.CallOption) (*hpb.ListResponse, error) {
+		gotReq = in
+		return &hpb.ListResponse{}, nil
+	}}
+
+	if _, err := healthz.NewListOperation().Path(rp0Path).IncludeAcknowledged(true).Execute(context.Background(), &fakeClient); err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+	if diff := cmp.Diff(&hpb.ListRequest{Path: rp0Path, IncludeAcknowledged: true}, gotReq, protocmp.Transform()); diff != "" {
+		t.Errorf("Execute() sent unexpected request diff (-want +got): %s", diff)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	var gotReq *hpb.CheckRequest
+	var fakeClient internal.Clients
+	fakeClient.HealthzClient = &fakeHealthzClient{CheckFn: func(_ context.Context, in *hpb.CheckRequest, _ ...grpc.CallOption) (*hpb.CheckResponse, error) {
+		gotReq = in
+		return &hpb.CheckResponse{}, nil
+	}}
+
+	if _, err := healthz.NewCheckOperation().PathFromSubcomponentName("RP0").EventID("ev1").Execute(context.Background(), &fakeClient); err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+	if diff := cmp.Diff(&hpb.CheckRequest{Path: rp0Path, EventId: "ev1"}, gotReq, protocmp.Transform()); diff != "" {
+		t.Errorf("Execute() sent unexpected request diff (-want +got): %s", diff)
+	}
+}
+
+func TestAcknowledge(t *testing.T) {
+	var gotReq *hpb.AcknowledgeRequest
+	var fakeClient internal.Clients
+	fakeClient.HealthzClient = &fakeHealthzClient{AcknowledgeFn: func(_ context.Context, in *hpb.AcknowledgeRequest, _ ...grpc.CallOption) (*hpb.AcknowledgeResponse, error) {
+		gotReq = in
+		return &hpb.AcknowledgeResponse{}, nil
+	}}
+
+	if _, err := healthz.NewAcknowledgeOperation().PathFromSubcomponentName("RP0").ID("ev1").Execute(context.Background(), &fakeClient); err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+	if diff := cmp.Diff(&hpb.AcknowledgeRequest{Path: rp0Path, Id: "ev1"}, gotReq, protocmp.Transform()); diff != "" {
+		t.Errorf("Execute() sent unexpected request diff (-want +got): %s", diff)
+	}
+}
+
+func fileHeader(id string, content []byte) *hpb.ArtifactResponse {
+	sum := sha256.Sum256(content)
+	return &hpb.ArtifactResponse{Contents: &hpb.ArtifactResponse_Header{Header: &hpb.ArtifactHeader{
+		Id: id,
+		ArtifactType: &hpb.ArtifactHeader_File{File: &hpb.FileArtifactType{
+			Name: id + ".log",
+			Size: int64(len(content)),
+			Hash: &tpb.HashType{Method: tpb.HashType_SHA256, Hash: sum[:]},
+		}},
+	}}}
+}
+
+func bytesResp(b []byte) *hpb.ArtifactResponse {
+	return &hpb.ArtifactResponse{Contents: &hpb.ArtifactResponse_Bytes{Bytes: b}}
+}
+
+var trailerResp = &hpb.ArtifactResponse{Contents: &hpb.ArtifactResponse_Trailer{Trailer: &hpb.ArtifactTrailer{}}}
+
+func TestArtifact(t *testing.T) {
+	content := []byte("core dump contents")
+	var anyMsgs []*anypb.Any
+	var wantAny bytes.Buffer
+	for _, m := range []proto.Message{rp0Path, &hpb.ArtifactHeader{Id: "a2"}} {
+		anyMsg, err := anypb.New(m)
+		if err != nil {
+			t.Fatalf("anypb.New() failed: %v", err)
+		}
+		anyMsgs = append(anyMsgs, anyMsg)
+		if _, err := protodelim.MarshalTo(&wantAny, anyMsg); err != nil {
+			t.Fatalf("protodelim.MarshalTo() failed: %v", err)
+		}
+	}
+	tests := []struct {
+		desc    string
+		resp    []*hpb.ArtifactResponse
+		want    []byte
+		wantErr string
+	}{
+		{
+			desc: "file artifact",
+			resp: []*hpb.ArtifactResponse{fileHeader("a1", content), bytesResp(content[:5]), bytesResp(content[5:]), trailerResp},
+			want: content,
+		},
+		{
+			desc: "proto artifact",
+			resp: []*hpb.ArtifactResponse{
+				{Contents: &hpb.ArtifactResponse_Header{Header: &hpb.ArtifactHeader{Id: "a1", ArtifactType: &hpb.ArtifactHeader_Proto{Proto: &hpb.ProtoArtifactType{}}}}},
+				{Contents: &hpb.ArtifactResponse_Proto{Proto: anyMsgs[0]}},
+				{Contents: &hpb.ArtifactResponse_Proto{Proto: anyMsgs[1]}},
+				trailerResp,
+			},
+			want: wantAny.Bytes(),
+		},
+		{
+			desc:    "hash mismatch",
+			resp:    []*hpb.ArtifactResponse{fileHeader("a1", content), bytesResp([]byte("core dump CONTENTS")), trailerResp},
+			wantErr: "hash mismatch",
+		},
+		{
+			desc:    "size mismatch",
+			resp:    []*hpb.ArtifactResponse{fileHeader("a1", content), bytesResp(content[:5]), trailerResp},
+			wantErr: "received 5 bytes",
+		},
+		{
+			desc:    "missing trailer",
+			resp:    []*hpb.ArtifactResponse{fileHeader("a1", content), bytesResp(content)},
+			wantErr: "ended before trailer",
+		},
+		{
+			desc:    "contents before header",
+			resp:    []*hpb.ArtifactResponse{bytesResp(content)},
+			wantErr: "before header",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var gotReq *hpb.ArtifactRequest
+			var fakeClient internal.Clients
+			fakeClient.HealthzClient = &fakeHealthzClient{ArtifactFn: func(_ context.Context, in *hpb.ArtifactRequest, _ ...grpc.CallOption) (hpb.Healthz_ArtifactClient, error) {
+				gotReq = in
+				return &fakeArtifactClient{resp: tt.resp}, nil
+			}}
+
+			var buf bytes.Buffer
+			header, err := healthz.NewArtifactOperation().ID("a1").Writer(&buf).Execute(context.Background(), &fakeClient)
+			if (err == nil) != (tt.wantErr == "") || (err != nil && !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("Execute() got unexpected error %v want %s", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(&hpb.ArtifactRequest{Id: "a1"}, gotReq, protocmp.Transform()); diff != "" {
+				t.Errorf("Execute() sent unexpected request diff (-want +got): %s", diff)
+			}
+			if err != nil {
+				return
+			}
+			if header.GetId() != "a1" {
+				t.Errorf("Execute() got header ID %q, want %q", header.GetId(), "a1")
+			}
+			if !bytes.Equal(buf.Bytes(), tt.want) {
+				t.Errorf("Execute() wrote %q, want %q", buf.Bytes(), tt.want)
+			}
+		})
+	}
+}
+
+func TestArtifactProtoMessages(t *testing.T) {
+	var anyMsgs []*anypb.Any
+	resp := []*hpb.ArtifactResponse{
+		{Contents: &hpb.ArtifactResponse_Header{Header: &hpb.ArtifactHeader{Id: "a1", ArtifactType: &hpb.ArtifactHeader_Proto{Proto: &hpb.ProtoArtifactType{}}}}},
+	}
+	for _, id := range []string{"e1", "e2", "e3"} {
+		anyMsg, err := anypb.New(&hpb.ArtifactHeader{Id: id})
+		if err != nil {
+			t.Fatalf("anypb.New() failed: %v", err)
+		}
+		anyMsgs = append(anyMsgs, anyMsg)
+		resp = append(resp, &hpb.ArtifactResponse{Contents: &hpb.ArtifactResponse_Proto{Proto: anyMsg}})
+	}
+	resp = append(resp, trailerResp)
+	var fakeClient internal.Clients
+	fakeClient.HealthzClient = &fakeHealthzClient{ArtifactFn: func(context.Context, *hpb.ArtifactRequest, ...grpc.CallOption) (hpb.Healthz_ArtifactClient, error) {
+		return &fakeArtifactClient{resp: resp}, nil
+	}}
+
+	var buf bytes.Buffer
+	if _, err := healthz.NewArtifactOperation().ID("a1").Writer(&buf).Execute(context.Background(), &fakeClient); err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+	r := bufio.NewReader(&buf)
+	var got []*anypb.Any
+	for {
+		m := &anypb.Any{}
+		err := protodelim.UnmarshalFrom(r, m)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("protodelim.UnmarshalFrom() failed: %v", err)
+		}
+		got = append(got, m)
+	}
+	if diff := cmp.Diff(anyMsgs, got, protocmp.Transform()); diff != "" {
+		t.Errorf("Execute() wrote unexpected messages diff (-want +got): %s", diff)
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"context"
+	"sync"
+)
+
+// ForEach calls fn concurrently for each of the keys, in order, with at most
+// concurrency calls running at the same time, or all of them if concurrency
+// is not positive. Once ctx is done, no more calls are started. ForEach
+// returns when all started calls have returned, with the keys fn was not
+// called for.
+func ForEach(ctx context.Context, keys []string, concurrency int, fn func(key string)) []string {
+	if concurrency <= 0 {
+		concurrency = len(keys)
+	}
+	sem := make(chan struct{}, max(concurrency, 1))
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for i, k := range keys {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			return keys[i:]
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(k)
+		}()
+	}
+	return nil
+}
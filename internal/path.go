@@ -0,0 +1,39 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import tpb "github.com/openconfig/gnoi/types"
+
+// ComponentPath returns the path `/openconfig/components/component[name=<n>]`.
+func ComponentPath(n string) *tpb.Path {
+	return &tpb.Path{
+		Origin: "openconfig",
+		Elem: []*tpb.PathElem{
+			{Name: "components"},
+			{Name: "component", Key: map[string]string{"name": n}},
+		},
+	}
+}
+
+// InterfacePath returns the path `/openconfig/interfaces/interface[name=<n>]`.
+func InterfacePath(n string) *tpb.Path {
+	return &tpb.Path{
+		Origin: "openconfig",
+		Elem: []*tpb.PathElem{
+			{Name: "interfaces"},
+			{Name: "interface", Key: map[string]string{"name": n}},
+		},
+	}
+}
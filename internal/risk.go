@@ -0,0 +1,46 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import "fmt"
+
+// Risk classifies how disruptive an operation is to the target.
+type Risk int
+
+const (
+	// RiskLow means the operation does not interrupt the target. It is the
+	// risk class of operations that do not declare one.
+	RiskLow Risk = iota
+	// RiskDisruptive means the operation interrupts the service of the
+	// target, e.g. by rebooting it or killing a process.
+	RiskDisruptive
+	// RiskDestructive means the operation erases state from the target,
+	// e.g. a factory reset.
+	RiskDestructive
+)
+
+// String returns a human-readable name of the risk class.
+func (r Risk) String() string {
+	switch r {
+	case RiskLow:
+		return "low"
+	case RiskDisruptive:
+		return "disruptive"
+	case RiskDestructive:
+		return "destructive"
+	default:
+		return fmt.Sprintf("Risk(%d)", int(r))
+	}
+}
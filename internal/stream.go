@@ -0,0 +1,47 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// MergeStreamErrors combines the errors from the sending and receiving sides
+// of a bidirectional stream. A cancellation on one side that was caused by a
+// failure on the other side is dropped in favour of the root cause.
+func MergeStreamErrors(sendErr, recvErr error) error {
+	switch {
+	case sendErr == nil:
+		return recvErr
+	case recvErr == nil:
+		return fmt.Errorf("error sending to stream: %w", sendErr)
+	case IsCanceled(sendErr):
+		return recvErr
+	case IsCanceled(recvErr):
+		return fmt.Errorf("error sending to stream: %w", sendErr)
+	default:
+		return errors.Join(fmt.Errorf("error sending to stream: %w", sendErr), recvErr)
+	}
+}
+
+// IsCanceled reports whether err is the result of a cancelled context or RPC.
+func IsCanceled(err error) bool {
+	return errors.Is(err, context.Canceled) || status.Code(err) == codes.Canceled
+}
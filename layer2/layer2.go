@@ -0,0 +1,294 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package layer2 provides gNOI layer2 operations.
+package layer2
+
+import (
+	"context"
+	"io"
+	"iter"
+	"net"
+	"time"
+
+	lpb "github.com/openconfig/gnoi/layer2"
+	tpb "github.com/openconfig/gnoi/types"
+	"github.com/openconfig/gnoigo/internal"
+	"google.golang.org/protobuf/proto"
+)
+
+// InterfacePath returns the path `/openconfig/interfaces/interface[name=<n>]`.
+func InterfacePath(n string) *tpb.Path {
+	return internal.InterfacePath(n)
+}
+
+// ClearNeighborDiscoveryOperation represents the parameters of a ClearNeighborDiscovery operation.
+type ClearNeighborDiscoveryOperation struct {
+	req *lpb.ClearNeighborDiscoveryRequest
+}
+
+// NewClearNeighborDiscoveryOperation creates an empty ClearNeighborDiscoveryOperation.
+func NewClearNeighborDiscoveryOperation() *ClearNeighborDiscoveryOperation {
+	return &ClearNeighborDiscoveryOperation{req: &lpb.ClearNeighborDiscoveryRequest{}}
+}
+
+// Protocol specifies the L3 protocol of the neighbor entries to clear.
+func (c *ClearNeighborDiscoveryOperation) Protocol(protocol tpb.L3Protocol) *ClearNeighborDiscoveryOperation {
+	c.req.Protocol = protocol
+	return c
+}
+
+// Address specifies the address of the neighbor entry to clear.
+// If unset, all the entries of the protocol are cleared.
+func (c *ClearNeighborDiscoveryOperation) Address(address string) *ClearNeighborDiscoveryOperation {
+	c.req.Address = address
+	return c
+}
+
+// Execute performs the ClearNeighborDiscovery operation.
+func (c *ClearNeighborDiscoveryOperation) Execute(ctx context.Context, cl *internal.Clients) (*lpb.ClearNeighborDiscoveryResponse, error) {
+	return cl.Layer2().ClearNeighborDiscovery(ctx, c.req)
+}
+
+// Request returns the request proto of the ClearNeighborDiscovery operation.
+func (c *ClearNeighborDiscoveryOperation) Request() proto.Message {
+	return c.req
+}
+
+// ClearSpanningTreeOperation represents the parameters of a ClearSpanningTree operation.
+type ClearSpanningTreeOperation struct {
+	req *lpb.ClearSpanningTreeRequest
+}
+
+// NewClearSpanningTreeOperation creates an empty ClearSpanningTreeOperation.
+func NewClearSpanningTreeOperation() *ClearSpanningTreeOperation {
+	return &ClearSpanningTreeOperation{req: &lpb.ClearSpanningTreeRequest{}}
+}
+
+// InterfaceName sets the interface path to `/openconfig/interfaces/interface[name=<n>]`.
+func (c *ClearSpanningTreeOperation) InterfaceName(n string) *ClearSpanningTreeOperation {
+	return c.Interface(InterfacePath(n))
+}
+
+// Interface sets the path of the interface to clear the spanning tree on.
+func (c *ClearSpanningTreeOperation) Interface(p *tpb.Path) *ClearSpanningTreeOperation {
+	c.req.Interface = p
+	return c
+}
+
+// Execute performs the ClearSpanningTree operation.
+func (c *ClearSpanningTreeOperation) Execute(ctx context.Context, cl *internal.Clients) (*lpb.ClearSpanningTreeResponse, error) {
+	return cl.Layer2().ClearSpanningTree(ctx, c.req)
+}
+
+// Request returns the request proto of the ClearSpanningTree operation.
+func (c *ClearSpanningTreeOperation) Request() proto.Message {
+	return c.req
+}
+
+// Risk returns the risk class of the ClearSpanningTree operation, which
+// reconverges the spanning tree on the interface.
+func (c *ClearSpanningTreeOperation) Risk() internal.Risk {
+	return internal.RiskDisruptive
+}
+
+// ClearLLDPInterfaceOperation represents the parameters of a ClearLLDPInterface operation.
+type ClearLLDPInterfaceOperation struct {
+	req *lpb.ClearLLDPInterfaceRequest
+}
+
+// NewClearLLDPInterfaceOperation creates an empty ClearLLDPInterfaceOperation.
+func NewClearLLDPInterfaceOperation() *ClearLLDPInterfaceOperation {
+	return &ClearLLDPInterfaceOperation{req: &lpb.ClearLLDPInterfaceRequest{}}
+}
+
+// InterfaceName sets the interface path to `/openconfig/interfaces/interface[name=<n>]`.
+func (c *ClearLLDPInterfaceOperation) InterfaceName(n string) *ClearLLDPInterfaceOperation {
+	return c.Interface(InterfacePath(n))
+}
+
+// Interface sets the path of the interface to clear the LLDP state of.
+func (c *ClearLLDPInterfaceOperation) Interface(p *tpb.Path) *ClearLLDPInterfaceOperation {
+	c.req.Interface = p
+	return c
+}
+
+// Execute performs the ClearLLDPInterface operation.
+func (c *ClearLLDPInterfaceOperation) Execute(ctx context.Context, cl *internal.Clients) (*lpb.ClearLLDPInterfaceResponse, error) {
+	return cl.Layer2().ClearLLDPInterface(ctx, c.req)
+}
+
+// Request returns the request proto of the ClearLLDPInterface operation.
+func (c *ClearLLDPInterfaceOperation) Request() proto.Message {
+	return c.req
+}
+
+// SendWakeOnLANOperation represents the parameters of a SendWakeOnLAN operation.
+type SendWakeOnLANOperation struct {
+	req *lpb.SendWakeOnLANRequest
+}
+
+// NewSendWakeOnLANOperation creates an empty SendWakeOnLANOperation.
+func NewSendWakeOnLANOperation() *SendWakeOnLANOperation {
+	return &SendWakeOnLANOperation{req: &lpb.SendWakeOnLANRequest{}}
+}
+
+// InterfaceName sets the interface path to `/openconfig/interfaces/interface[name=<n>]`.
+func (s *SendWakeOnLANOperation) InterfaceName(n string) *SendWakeOnLANOperation {
+	return s.Interface(InterfacePath(n))
+}
+
+// Interface sets the path of the interface to send the packet from.
+func (s *SendWakeOnLANOperation) Interface(p *tpb.Path) *SendWakeOnLANOperation {
+	s.req.Interface = p
+	return s
+}
+
+// Address specifies the IP address of the target to wake.
+func (s *SendWakeOnLANOperation) Address(address string) *SendWakeOnLANOperation {
+	s.req.Address = address
+	return s
+}
+
+// MACAddress specifies the MAC address of the target to wake.
+func (s *SendWakeOnLANOperation) MACAddress(mac net.HardwareAddr) *SendWakeOnLANOperation {
+	s.req.MacAddress = mac
+	return s
+}
+
+// Execute performs the SendWakeOnLAN operation.
+func (s *SendWakeOnLANOperation) Execute(ctx context.Context, c *internal.Clients) (*lpb.SendWakeOnLANResponse, error) {
+	return c.Layer2().SendWakeOnLAN(ctx, s.req)
+}
+
+// Request returns the request proto of the SendWakeOnLAN operation.
+func (s *SendWakeOnLANOperation) Request() proto.Message {
+	return s.req
+}
+
+// PerformBERTOperation represents the parameters of a PerformBERT operation.
+type PerformBERTOperation struct {
+	req *lpb.PerformBERTRequest
+}
+
+// NewPerformBERTOperation creates an empty PerformBERTOperation.
+func NewPerformBERTOperation() *PerformBERTOperation {
+	return &PerformBERTOperation{req: &lpb.PerformBERTRequest{}}
+}
+
+// ID specifies the ID of the BERT test.
+func (p *PerformBERTOperation) ID(id string) *PerformBERTOperation {
+	p.req.Id = id
+	return p
+}
+
+// InterfaceName sets the interface path to `/openconfig/interfaces/interface[name=<n>]`.
+func (p *PerformBERTOperation) InterfaceName(n string) *PerformBERTOperation {
+	return p.Interface(InterfacePath(n))
+}
+
+// Interface sets the path of the interface to run the BERT test on.
+func (p *PerformBERTOperation) Interface(path *tpb.Path) *PerformBERTOperation {
+	p.req.Interface = path
+	return p
+}
+
+// Execute performs the PerformBERT operation. The returned BERTStream reads
+// the results as the target reports them.
+func (p *PerformBERTOperation) Execute(ctx context.Context, c *internal.Clients) (*BERTStream, error) {
+	bc, err := c.Layer2().PerformBERT(ctx, p.req)
+	if err != nil {
+		return nil, err
+	}
+	return &BERTStream{bc: bc, intf: p.req.GetInterface()}, nil
+}
+
+// Request returns the request proto of the PerformBERT operation.
+func (p *PerformBERTOperation) Request() proto.Message {
+	return p.req
+}
+
+// Risk returns the risk class of the PerformBERT operation, which takes the
+// interface out of service for the duration of the test.
+func (p *PerformBERTOperation) Risk() internal.Risk {
+	return internal.RiskDisruptive
+}
+
+// BERTResult is a BERT test result reported for an interface.
+type BERTResult struct {
+	ID           string
+	Interface    *tpb.Path
+	State        lpb.PerformBERTResponse_BERTState
+	Elapsed      time.Duration
+	Pattern      []byte
+	Errors       int64
+	ReceivedBits int64
+}
+
+// ErrorRate returns the bit error rate, or 0 if no bits were received.
+func (r *BERTResult) ErrorRate() float64 {
+	if r.ReceivedBits == 0 {
+		return 0
+	}
+	return float64(r.Errors) / float64(r.ReceivedBits)
+}
+
+// Done reports whether the test has reached a terminal state.
+func (r *BERTResult) Done() bool {
+	switch r.State {
+	case lpb.PerformBERTResponse_COMPLETE, lpb.PerformBERTResponse_ERROR, lpb.PerformBERTResponse_DISABLED:
+		return true
+	}
+	return false
+}
+
+// BERTStream is a stream of BERT test results.
+type BERTStream struct {
+	bc   lpb.Layer2_PerformBERTClient
+	intf *tpb.Path
+}
+
+// Next returns the next result. It returns io.EOF once the target has sent
+// all the results.
+func (s *BERTStream) Next() (*BERTResult, error) {
+	resp, err := s.bc.Recv()
+	if err != nil {
+		return nil, err
+	}
+	return &BERTResult{
+		ID:           resp.GetId(),
+		Interface:    s.intf,
+		State:        resp.GetState(),
+		Elapsed:      time.Duration(resp.GetElapsedPeriod()),
+		Pattern:      resp.GetPattern(),
+		Errors:       resp.GetErrors(),
+		ReceivedBits: resp.GetReceivedBits(),
+	}, nil
+}
+
+// Results returns an iterator over the results. Iteration stops at the end of
+// the stream; any other error is yielded with a nil result.
+func (s *BERTStream) Results() iter.Seq2[*BERTResult, error] {
+	return func(yield func(*BERTResult, error) bool) {
+		for {
+			res, err := s.Next()
+			if err == io.EOF {
+				return
+			}
+			if !yield(res, err) || err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package layer2_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	lpb "github.com/openconfig/gnoi/layer2"
+	tpb "github.com/openconfig/gnoi/types"
+	"github.com/openconfig/gnoigo/internal"
+	"github.com/openconfig/gnoigo/layer2"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+type fakeLayer2Client struct {
+	lpb.Layer2Client
+	gotReq proto.Message
+	stream lpb.Layer2_PerformBERTClient
+}
+
+func (fc *fakeLayer2Client) ClearNeighborDiscovery(_ context.Context, in *lpb.ClearNeighborDiscoveryRequest, _ ...grpc.CallOption) (*lpb.ClearNeighborDiscoveryResponse, error) {
+	fc.gotReq = in
+	return &lpb.ClearNeighborDiscoveryResponse{}, nil
+}
+
+func (fc *fakeLayer2Client) ClearSpanningTree(_ context.Context, in *lpb.ClearSpanningTreeRequest, _ ...grpc.CallOption) (*lpb.ClearSpanningTreeResponse, error) {
+	fc.gotReq = in
+	return &lpb.ClearSpanningTreeResponse{}, nil
+}
+
+func (fc *fakeLayer2Client) ClearLLDPInterface(_ context.Context, in *lpb.ClearLLDPInterfaceRequest, _ ...grpc.CallOption) (*lpb.ClearLLDPInterfaceResponse, error) {
+	fc.gotReq = in
+	return &lpb.ClearLLDPInterfaceResponse{}, nil
+}
+
+func (fc *fakeLayer2Client) SendWakeOnLAN(_ context.Context, in *lpb.SendWakeOnLANRequest, _ ...grpc.CallOption) (*lpb.SendWakeOnLANResponse, error) {
+	fc.gotReq = in
+	return &lpb.SendWakeOnLANResponse{}, nil
+}
+
+func (fc *fakeLayer2Client) PerformBERT(_ context.Context, in *lpb.PerformBERTRequest, _ ...grpc.CallOption) (lpb.Layer2_PerformBERTClient, error) {
+	fc.gotReq = in
+	return fc.stream, nil
+}
+
+type fakePerformBERTClient struct {
+	lpb.Layer2_PerformBERTClient
+	resp []*lpb.PerformBERTResponse
+	err  error
+}
+
+func (bc *fakePerformBERTClient) Recv() (*lpb.PerformBERTResponse, error) {
+	if len(bc.resp) == 0 {
+		if bc.err != nil {
+			return nil, bc.err
+		}
+		return nil, io.EOF
+	}
+	resp := bc.resp[0]
+	bc.resp = bc.resp[1:]
+	return resp, nil
+}
+
+func TestUnaryOperations(t *testing.T) {
+	eth1 := &tpb.Path{
+		Origin: "openconfig",
+		Elem: []*tpb.PathElem{
+			{Name: "interfaces"},
+			{Name: "interface", Key: map[string]string{"name": "Ethernet1"}},
+		},
+	}
+	mac, err := net.ParseMAC("00:11:22:33:44:55")
+	if err != nil {
+		t.Fatalf("net.ParseMAC() failed: %v", err)
+	}
+	tests := []struct {
+		desc    string
+		execute func(context.Context, *internal.Clients) error
+		wantReq proto.Message
+	}{
+		{
+			desc: "ClearNeighborDiscovery",
+			execute: func(ctx context.Context, c *internal.Clients) error {
+				_, err := layer2.NewClearNeighborDiscoveryOperation().Protocol(tpb.L3Protocol_IPV6).Address("2001:db8::1").Execute(ctx, c)
+				return err
+			},
+			wantReq: &lpb.ClearNeighborDiscoveryRequest{Protocol: tpb.L3Protocol_IPV6, Address: "2001:db8::1"},
+		},
+		{
+			desc: "ClearSpanningTree",
+			execute: func(ctx context.Context, c *internal.Clients) error {
+				_, err := layer2.NewClearSpanningTreeOperation().InterfaceName("Ethernet1").Execute(ctx, c)
+				return err
+			},
+			wantReq: &lpb.ClearSpanningTreeRequest{Interface: eth1},
+		},
+		{
+			desc: "ClearLLDPInterface",
+			execute: func(ctx context.Context, c *internal.Clients) error {
+				_, err := layer2.NewClearLLDPInterfaceOperation().Interface(eth1).Execute(ctx, c)
+				return err
+			},
+			wantReq: &lpb.ClearLLDPInterfaceRequest{Interface: eth1},
+		},
+		{
+			desc: "SendWakeOnLAN",
+			execute: func(ctx context.Context, c *internal.Clients) error {
+				_, err := layer2.NewSendWakeOnLANOperation().InterfaceName("Ethernet1").Address("192.0.2.1").MACAddress(mac).Execute(ctx, c)
+				return err
+			},
+			wantReq: &lpb.SendWakeOnLANRequest{Interface: eth1, Address: "192.0.2.1", MacAddress: mac},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			fake := &fakeLayer2Client{}
+			if err := tt.execute(context.Background(), &internal.Clients{Layer2Client: fake}); err != nil {
+				t.Fatalf("Execute() failed: %v", err)
+			}
+			if diff := cmp.Diff(tt.wantReq, fake.gotReq, protocmp.Transform()); diff != "" {
+				t.Errorf("Execute() sent unexpected request diff (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestPerformBERT(t *testing.T) {
+	streamErr := errors.New("stream broken")
+	tests := []struct {
+		desc    string
+		stream  *fakePerformBERTClient
+		want    []*layer2.BERTResult
+		wantErr error
+	}{
+		{
+			desc: "complete",
+			stream: &fakePerformBERTClient{resp: []*lpb.PerformBERTResponse{
+				{Id: "b1", State: lpb.PerformBERTResponse_RUNNING, ElapsedPeriod: int64(time.Second), ReceivedBits: 1000},
+				{Id: "b1", State: lpb.PerformBERTResponse_COMPLETE, ElapsedPeriod: int64(2 * time.Second), Errors: 2, ReceivedBits: 2000},
+			}},
+			want: []*layer2.BERTResult{
+				{ID: "b1", Interface: layer2.InterfacePath("Ethernet1"), State: lpb.PerformBERTResponse_RUNNING, Elapsed: time.Second, ReceivedBits: 1000},
+				{ID: "b1", Interface: layer2.InterfacePath("Ethernet1"), State: lpb.PerformBERTResponse_COMPLETE, Elapsed: 2 * time.Second, Errors: 2, ReceivedBits: 2000},
+			},
+		},
+		{
+			desc: "stream error",
+			stream: &fakePerformBERTClient{
+				resp: []*lpb.PerformBERTResponse{{Id: "b1", State: lpb.PerformBERTResponse_RUNNING}},
+				err:  streamErr,
+			},
+			want:    []*layer2.BERTResult{{ID: "b1", Interface: layer2.InterfacePath("Ethernet1"), State: lpb.PerformBERTResponse_RUNNING}},
+			wantErr: streamErr,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			fake := &fakeLayer2Client{stream: tt.stream}
+			stream, err := layer2.NewPerformBERTOperation().ID("b1").InterfaceName("Ethernet1").Execute(context.Background(), &internal.Clients{Layer2Client: fake})
+			if err != nil {
+				t.Fatalf("Execute() failed: %v", err)
+			}
+			wantReq := &lpb.PerformBERTRequest{Id: "b1", Interface: layer2.InterfacePath("Ethernet1")}
+			if diff := cmp.Diff(wantReq, fake.gotReq, protocmp.Transform()); diff != "" {
+				t.Errorf("Execute() sent unexpected request diff (-want +got): %s", diff)
+			}
+
+			var got []*layer2.BERTResult
+			var gotErr error
+			for res, err := range stream.Results() {
+				if err != nil {
+					gotErr = err
+					break
+				}
+				got = append(got, res)
+			}
+			if !errors.Is(gotErr, tt.wantErr) {
+				t.Errorf("Results() got error %v, want %v", gotErr, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Results() got unexpected results diff (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestBERTResult(t *testing.T) {
+	r := &layer2.BERTResult{State: lpb.PerformBERTResponse_COMPLETE, Errors: 5, ReceivedBits: 1000}
+	if got, want := r.ErrorRate(), 0.005; got != want {
+		t.Errorf("ErrorRate() got %v, want %v", got, want)
+	}
+	if !r.Done() {
+		t.Errorf("Done() got false for state %v, want true", r.State)
+	}
+	if got := (&layer2.BERTResult{State: lpb.PerformBERTResponse_RUNNING}).ErrorRate(); got != 0 {
+		t.Errorf("ErrorRate() without received bits got %v, want 0", got)
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package linkqual provides gNOI packet link qualification operations.
+package linkqual
+
+import (
+	"context"
+
+	plqpb "github.com/openconfig/gnoi/packet_link_qualification"
+	"github.com/openconfig/gnoigo/internal"
+	"google.golang.org/protobuf/proto"
+)
+
+// CreateOperation represents the parameters of a Create operation.
+type CreateOperation struct {
+	req *plqpb.CreateRequest
+}
+
+// NewCreateOperation creates an empty CreateOperation.
+func NewCreateOperation() *CreateOperation {
+	return &CreateOperation{req: &plqpb.CreateRequest{}}
+}
+
+// Qualification adds qualification configurations to create.
+func (c *CreateOperation) Qualification(configs ...*plqpb.QualificationConfiguration) *CreateOperation {
+	c.req.Interfaces = append(c.req.Interfaces, configs...)
+	return c
+}
+
+// Execute performs the Create operation.
+func (c *CreateOperation) Execute(ctx context.Context, cl *internal.Clients) (*plqpb.CreateResponse, error) {
+	return cl.LinkQualification().Create(ctx, c.req)
+}
+
+// Request returns the request proto of the Create operation.
+func (c *CreateOperation) Request() proto.Message {
+	return c.req
+}
+
+// Risk returns the risk class of the Create operation, which takes the
+// interfaces out of service for the duration of the qualification.
+func (c *CreateOperation) Risk() internal.Risk {
+	return internal.RiskDisruptive
+}
+
+// GetOperation represents the parameters of a Get operation.
+type GetOperation struct {
+	req *plqpb.GetRequest
+}
+
+// NewGetOperation creates an empty GetOperation.
+func NewGetOperation() *GetOperation {
+	return &GetOperation{req: &plqpb.GetRequest{}}
+}
+
+// IDs adds the IDs of the qualifications to get the results of.
+func (g *GetOperation) IDs(ids ...string) *GetOperation {
+	g.req.Ids = append(g.req.Ids, ids...)
+	return g
+}
+
+// Execute performs the Get operation.
+func (g *GetOperation) Execute(ctx context.Context, c *internal.Clients) (*plqpb.GetResponse, error) {
+	return c.LinkQualification().Get(ctx, g.req)
+}
+
+// Request returns the request proto of the Get operation.
+func (g *GetOperation) Request() proto.Message {
+	return g.req
+}
+
+// Idempotent reports that the Get operation can safely be retried.
+func (g *GetOperation) Idempotent() bool {
+	return true
+}
+
+// CapabilitiesOperation represents the parameters of a Capabilities operation.
+type CapabilitiesOperation struct {
+	req *plqpb.CapabilitiesRequest
+}
+
+// NewCapabilitiesOperation creates an empty CapabilitiesOperation.
+func NewCapabilitiesOperation() *CapabilitiesOperation {
+	return &CapabilitiesOperation{req: &plqpb.CapabilitiesRequest{}}
+}
+
+// Execute performs the Capabilities operation.
+func (ca *CapabilitiesOperation) Execute(ctx context.Context, c *internal.Clients) (*plqpb.CapabilitiesResponse, error) {
+	return c.LinkQualification().Capabilities(ctx, ca.req)
+}
+
+// Request returns the request proto of the Capabilities operation.
+func (ca *CapabilitiesOperation) Request() proto.Message {
+	return ca.req
+}
+
+// Idempotent reports that the Capabilities operation can safely be retried.
+func (ca *CapabilitiesOperation) Idempotent() bool {
+	return true
+}
+
+// DeleteOperation represents the parameters of a Delete operation.
+type DeleteOperation struct {
+	req *plqpb.DeleteRequest
+}
+
+// NewDeleteOperation creates an empty DeleteOperation.
+func NewDeleteOperation() *DeleteOperation {
+	return &DeleteOperation{req: &plqpb.DeleteRequest{}}
+}
+
+// IDs adds the IDs of the qualifications to delete.
+func (d *DeleteOperation) IDs(ids ...string) *DeleteOperation {
+	d.req.Ids = append(d.req.Ids, ids...)
+	return d
+}
+
+// Execute performs the Delete operation.
+func (d *DeleteOperation) Execute(ctx context.Context, c *internal.Clients) (*plqpb.DeleteResponse, error) {
+	return c.LinkQualification().Delete(ctx, d.req)
+}
+
+// Request returns the request proto of the Delete operation.
+func (d *DeleteOperation) Request() proto.Message {
+	return d.req
+}
+
+// ListOperation represents the parameters of a List operation.
+type ListOperation struct {
+	req *plqpb.ListRequest
+}
+
+// NewListOperation creates an empty ListOperation.
+func NewListOperation() *ListOperation {
+	return &ListOperation{req: &plqpb.ListRequest{}}
+}
+
+// Execute performs the List operation.
+func (l *ListOperation) Execute(ctx context.Context, c *internal.Clients) (*plqpb.ListResponse, error) {
+	return c.LinkQualification().List(ctx, l.req)
+}
+
+// Request returns the request proto of the List operation.
+func (l *ListOperation) Request() proto.Message {
+	return l.req
+}
+
+// Idempotent reports that the List operation can safely be retried.
+func (l *ListOperation) Idempotent() bool {
+	return true
+}
@@ -0,0 +1,119 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linkqual_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	plqpb "github.com/openconfig/gnoi/packet_link_qualification"
+	"github.com/openconfig/gnoigo/internal"
+	"github.com/openconfig/gnoigo/linkqual"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+type fakeLinkQualClient struct {
+	plqpb.LinkQualificationClient
+	gotReq proto.Message
+}
+
+func (fc *fakeLinkQualClient) Create(_ context.Context, in *plqpb.CreateRequest, _ ...grpc.CallOption) (*plqpb.CreateResponse, error) {
+	fc.gotReq = in
+	return &plqpb.CreateResponse{}, nil
+}
+
+func (fc *fakeLinkQualClient) Get(_ context.Context, in *plqpb.GetRequest, _ ...grpc.CallOption) (*plqpb.GetResponse, error) {
+	fc.gotReq = in
+	return &plqpb.GetResponse{}, nil
+}
+
+func (fc *fakeLinkQualClient) Capabilities(_ context.Context, in *plqpb.CapabilitiesRequest, _ ...grpc.CallOption) (*plqpb.CapabilitiesResponse, error) {
+	fc.gotReq = in
+	return &plqpb.CapabilitiesResponse{}, nil
+}
+
+func (fc *fakeLinkQualClient) Delete(_ context.Context, in *plqpb.DeleteRequest, _ ...grpc.CallOption) (*plqpb.DeleteResponse, error) {
+	fc.gotReq = in
+	return &plqpb.DeleteResponse{}, nil
+}
+
+func (fc *fakeLinkQualClient) List(_ context.Context, in *plqpb.ListRequest, _ ...grpc.CallOption) (*plqpb.ListResponse, error) {
+	fc.gotReq = in
+	return &plqpb.ListResponse{}, nil
+}
+
+func TestOperations(t *testing.T) {
+	config := &plqpb.QualificationConfiguration{Id: "q1", InterfaceName: "Ethernet1"}
+	tests := []struct {
+		desc    string
+		execute func(context.Context, *internal.Clients) error
+		wantReq proto.Message
+	}{
+		{
+			desc: "Create",
+			execute: func(ctx context.Context, c *internal.Clients) error {
+				_, err := linkqual.NewCreateOperation().Qualification(config).Execute(ctx, c)
+				return err
+			},
+			wantReq: &plqpb.CreateRequest{Interfaces: []*plqpb.QualificationConfiguration{config}},
+		},
+		{
+			desc: "Get",
+			execute: func(ctx context.Context, c *internal.Clients) error {
+				_, err := linkqual.NewGetOperation().IDs("q1", "q2").Execute(ctx, c)
+				return err
+			},
+			wantReq: &plqpb.GetRequest{Ids: []string{"q1", "q2"}},
+		},
+		{
+			desc: "Capabilities",
+			execute: func(ctx context.Context, c *internal.Clients) error {
+				_, err := linkqual.NewCapabilitiesOperation().Execute(ctx, c)
+				return err
+			},
+			wantReq: &plqpb.CapabilitiesRequest{},
+		},
+		{
+			desc: "Delete",
+			execute: func(ctx context.Context, c *internal.Clients) error {
+				_, err := linkqual.NewDeleteOperation().IDs("q1").Execute(ctx, c)
+				return err
+			},
+			wantReq: &plqpb.DeleteRequest{Ids: []string{"q1"}},
+		},
+		{
+			desc: "List",
+			execute: func(ctx context.Context, c *internal.Clients) error {
+				_, err := linkqual.NewListOperation().Execute(ctx, c)
+				return err
+			},
+			wantReq: &plqpb.ListRequest{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			fake := &fakeLinkQualClient{}
+			if err := tt.execute(context.Background(), &internal.Clients{LinkQualClient: fake}); err != nil {
+				t.Fatalf("Execute() failed: %v", err)
+			}
+			if diff := cmp.Diff(tt.wantReq, fake.gotReq, protocmp.Transform()); diff != "" {
+				t.Errorf("Execute() sent unexpected request diff (-want +got): %s", diff)
+			}
+		})
+	}
+}
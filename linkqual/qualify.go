@@ -0,0 +1,434 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linkqual
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	log "github.com/golang/glog"
+	plqpb "github.com/openconfig/gnoi/packet_link_qualification"
+	"github.com/openconfig/gnoigo"
+	"google.golang.org/genproto/googleapis/rpc/code"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+const (
+	defaultPollInterval = 10 * time.Second
+	cleanupTimeout      = 30 * time.Second
+)
+
+// Pair is a link to qualify: an interface of the generator device connected
+// to an interface of the reflector device. The ID is used on both devices.
+type Pair struct {
+	ID        string
+	Generator string
+	Reflector string
+}
+
+// Qualification describes the qualification of links between a generator
+// device and a reflector device.
+type Qualification struct {
+	pairs        []Pair
+	generator    *plqpb.QualificationConfiguration
+	reflector    *plqpb.QualificationConfiguration
+	timing       *plqpb.RPCSyncedTiming
+	pollInterval time.Duration
+}
+
+// NewQualification creates a Qualification that generates packets at 1000
+// packets per second of 1500 bytes, reflects them with a PMD loopback, and
+// runs for one minute. Unless specified, the setup and teardown durations are
+// the larger of the minimums reported by both devices.
+func NewQualification() *Qualification {
+	q := &Qualification{
+		generator:    &plqpb.QualificationConfiguration{},
+		reflector:    &plqpb.QualificationConfiguration{},
+		timing:       &plqpb.RPCSyncedTiming{Duration: durationpb.New(time.Minute)},
+		pollInterval: defaultPollInterval,
+	}
+	return q.PacketGenerator(1000, 1500).PMDLoopback()
+}
+
+// Pair adds a link between the generatorIntf interface of the generator and
+// the reflectorIntf interface of the reflector, qualified with the given ID.
+func (q *Qualification) Pair(id, generatorIntf, reflectorIntf string) *Qualification {
+	q.pairs = append(q.pairs, Pair{ID: id, Generator: generatorIntf, Reflector: reflectorIntf})
+	return q
+}
+
+// PacketGenerator specifies that the generator sends packetRate packets per
+// second of packetSize bytes.
+func (q *Qualification) PacketGenerator(packetRate uint64, packetSize uint32) *Qualification {
+	q.generator.EndpointType = &plqpb.QualificationConfiguration_PacketGenerator{
+		PacketGenerator: &plqpb.PacketGeneratorConfiguration{PacketRate: packetRate, PacketSize: packetSize},
+	}
+	return q
+}
+
+// PMDLoopback specifies that the reflector loops packets back at the PMD.
+func (q *Qualification) PMDLoopback() *Qualification {
+	q.reflector.EndpointType = &plqpb.QualificationConfiguration_PmdLoopback{PmdLoopback: &plqpb.PmdLoopbackConfiguration{}}
+	return q
+}
+
+// ASICLoopback specifies that the reflector loops packets back at the ASIC.
+func (q *Qualification) ASICLoopback() *Qualification {
+	q.reflector.EndpointType = &plqpb.QualificationConfiguration_AsicLoopback{AsicLoopback: &plqpb.AsicLoopbackConfiguration{}}
+	return q
+}
+
+// Duration specifies how long packets are sent for.
+func (q *Qualification) Duration(d time.Duration) *Qualification {
+	q.timing.Duration = durationpb.New(d)
+	return q
+}
+
+// SetupDuration specifies the time given to both devices to set up the
+// interfaces. It must be at least the minimum setup duration they report.
+func (q *Qualification) SetupDuration(d time.Duration) *Qualification {
+	q.timing.SetupDuration = durationpb.New(d)
+	return q
+}
+
+// TeardownDuration specifies the time given to both devices to reset the
+// interfaces. It must be at least the minimum teardown duration they report.
+func (q *Qualification) TeardownDuration(d time.Duration) *Qualification {
+	q.timing.TeardownDuration = durationpb.New(d)
+	return q
+}
+
+// PollInterval specifies the time between Get calls while the qualification
+// runs.
+func (q *Qualification) PollInterval(interval time.Duration) *Qualification {
+	q.pollInterval = interval
+	return q
+}
+
+// GeneratorConfigs returns the configurations to create on the generator.
+// Setup and teardown durations that are not specified are left unset.
+func (q *Qualification) GeneratorConfigs() []*plqpb.QualificationConfiguration {
+	return q.generatorConfigs(q.timing)
+}
+
+// ReflectorConfigs returns the configurations to create on the reflector.
+// Setup and teardown durations that are not specified are left unset.
+func (q *Qualification) ReflectorConfigs() []*plqpb.QualificationConfiguration {
+	return q.reflectorConfigs(q.timing)
+}
+
+func (q *Qualification) generatorConfigs(timing *plqpb.RPCSyncedTiming) []*plqpb.QualificationConfiguration {
+	return q.configs(q.generator, timing, func(p Pair) string { return p.Generator })
+}
+
+func (q *Qualification) reflectorConfigs(timing *plqpb.RPCSyncedTiming) []*plqpb.QualificationConfiguration {
+	return q.configs(q.reflector, timing, func(p Pair) string { return p.Reflector })
+}
+
+func (q *Qualification) configs(endpoint *plqpb.QualificationConfiguration, timing *plqpb.RPCSyncedTiming, intf func(Pair) string) []*plqpb.QualificationConfiguration {
+	var configs []*plqpb.QualificationConfiguration
+	for _, p := range q.pairs {
+		configs = append(configs, &plqpb.QualificationConfiguration{
+			Id:            p.ID,
+			InterfaceName: intf(p),
+			Timing:        &plqpb.QualificationConfiguration_Rpc{Rpc: timing},
+			EndpointType:  endpoint.GetEndpointType(),
+		})
+	}
+	return configs
+}
+
+func (q *Qualification) ids() []string {
+	var ids []string
+	for _, p := range q.pairs {
+		ids = append(ids, p.ID)
+	}
+	return ids
+}
+
+// minTiming holds the minimum setup and teardown durations a device reports.
+type minTiming struct {
+	setup, teardown time.Duration
+}
+
+func newMinTiming(setup, teardown *durationpb.Duration) minTiming {
+	return minTiming{setup: setup.AsDuration(), teardown: teardown.AsDuration()}
+}
+
+// checkGenerator checks the generator capabilities support the qualification
+// and returns the minimum timing of the generator.
+func (q *Qualification) checkGenerator(caps *plqpb.CapabilitiesResponse) (minTiming, error) {
+	pg := q.generator.GetPacketGenerator()
+	gc := caps.GetGenerator().GetPacketGenerator()
+	if gc == nil {
+		return minTiming{}, errors.New("packet generator not supported")
+	}
+	if gc.GetMaxPps() > 0 && pg.GetPacketRate() > gc.GetMaxPps() {
+		return minTiming{}, fmt.Errorf("packet rate %d exceeds maximum %d", pg.GetPacketRate(), gc.GetMaxPps())
+	}
+	if pg.GetPacketSize() < gc.GetMinMtu() || (gc.GetMaxMtu() > 0 && pg.GetPacketSize() > gc.GetMaxMtu()) {
+		return minTiming{}, fmt.Errorf("packet size %d outside MTU range [%d, %d]", pg.GetPacketSize(), gc.GetMinMtu(), gc.GetMaxMtu())
+	}
+	return newMinTiming(gc.GetMinSetupDuration(), gc.GetMinTeardownDuration()), nil
+}
+
+// checkReflector checks the reflector capabilities support the qualification
+// and returns the minimum timing of the reflector.
+func (q *Qualification) checkReflector(caps *plqpb.CapabilitiesResponse) (minTiming, error) {
+	rc := caps.GetReflector()
+	switch {
+	case q.reflector.GetPmdLoopback() != nil:
+		if rc.GetPmdLoopback() == nil {
+			return minTiming{}, errors.New("PMD loopback not supported")
+		}
+		return newMinTiming(rc.GetPmdLoopback().GetMinSetupDuration(), rc.GetPmdLoopback().GetMinTeardownDuration()), nil
+	case q.reflector.GetAsicLoopback() != nil:
+		if rc.GetAsicLoopback() == nil {
+			return minTiming{}, errors.New("ASIC loopback not supported")
+		}
+		return newMinTiming(rc.GetAsicLoopback().GetMinSetupDuration(), rc.GetAsicLoopback().GetMinTeardownDuration()), nil
+	}
+	return minTiming{}, nil
+}
+
+// syncedTiming returns the timing of the qualification on both devices, with
+// unset setup and teardown durations defaulting to the larger of the minimums
+// of the devices. Specified durations must be at least those minimums.
+func (q *Qualification) syncedTiming(generator, reflector minTiming) (*plqpb.RPCSyncedTiming, error) {
+	timing := proto.Clone(q.timing).(*plqpb.RPCSyncedTiming)
+	minSetup := max(generator.setup, reflector.setup)
+	minTeardown := max(generator.teardown, reflector.teardown)
+	if timing.SetupDuration == nil {
+		timing.SetupDuration = durationpb.New(minSetup)
+	} else if setup := timing.GetSetupDuration().AsDuration(); setup < minSetup {
+		return nil, fmt.Errorf("setup duration %v below minimum %v", setup, minSetup)
+	}
+	if timing.TeardownDuration == nil {
+		timing.TeardownDuration = durationpb.New(minTeardown)
+	} else if teardown := timing.GetTeardownDuration().AsDuration(); teardown < minTeardown {
+		return nil, fmt.Errorf("teardown duration %v below minimum %v", teardown, minTeardown)
+	}
+	return timing, nil
+}
+
+// InterfaceResult is the result of a qualification on one interface.
+type InterfaceResult struct {
+	ID                string
+	Interface         string
+	State             plqpb.QualificationState
+	PacketsSent       uint64
+	PacketsReceived   uint64
+	PacketsError      uint64
+	PacketsDropped    uint64
+	ExpectedRate      uint64
+	QualificationRate uint64
+	// Err is the error reported by the device for the qualification, if any.
+	Err error
+}
+
+// ErrorRate returns the fraction of received packets that had errors.
+func (r *InterfaceResult) ErrorRate() float64 {
+	if r.PacketsReceived == 0 {
+		return 0
+	}
+	return float64(r.PacketsError) / float64(r.PacketsReceived)
+}
+
+// DropRate returns the fraction of packets that were dropped.
+func (r *InterfaceResult) DropRate() float64 {
+	total := r.PacketsReceived + r.PacketsDropped
+	if total == 0 {
+		return 0
+	}
+	return float64(r.PacketsDropped) / float64(total)
+}
+
+func newInterfaceResult(r *plqpb.QualificationResult) *InterfaceResult {
+	res := &InterfaceResult{
+		ID:                r.GetId(),
+		Interface:         r.GetInterfaceName(),
+		State:             r.GetState(),
+		PacketsSent:       r.GetPacketsSent(),
+		PacketsReceived:   r.GetPacketsReceived(),
+		PacketsError:      r.GetPacketsError(),
+		PacketsDropped:    r.GetPacketsDropped(),
+		ExpectedRate:      r.GetExpectedRateBytesPerSecond(),
+		QualificationRate: r.GetQualificationRateBytesPerSecond(),
+	}
+	if r.GetStatus().GetCode() != int32(code.Code_OK) {
+		res.Err = status.ErrorProto(r.GetStatus())
+	}
+	return res
+}
+
+// PairResult is the result of the qualification of a link on both devices.
+type PairResult struct {
+	Pair      Pair
+	Generator *InterfaceResult
+	Reflector *InterfaceResult
+}
+
+// Passed reports whether the qualification completed on both devices without
+// errors.
+func (r *PairResult) Passed() bool {
+	for _, ir := range []*InterfaceResult{r.Generator, r.Reflector} {
+		if ir == nil || ir.State != plqpb.QualificationState_QUALIFICATION_STATE_COMPLETED || ir.Err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// RunQualification runs q between the generator and reflector devices. It
+// checks the capabilities of both devices, creates the qualifications, polls
+// them until they complete or fail, and deletes them.
+func RunQualification(ctx context.Context, generator, reflector gnoigo.Clients, q *Qualification) ([]*PairResult, error) {
+	if len(q.pairs) == 0 {
+		return nil, errors.New("no interface pairs to qualify")
+	}
+	genCaps, err := gnoigo.Execute(ctx, generator, NewCapabilitiesOperation())
+	if err != nil {
+		return nil, fmt.Errorf("generator capabilities: %w", err)
+	}
+	genTiming, err := q.checkGenerator(genCaps)
+	if err != nil {
+		return nil, fmt.Errorf("generator: %w", err)
+	}
+	reflCaps, err := gnoigo.Execute(ctx, reflector, NewCapabilitiesOperation())
+	if err != nil {
+		return nil, fmt.Errorf("reflector capabilities: %w", err)
+	}
+	reflTiming, err := q.checkReflector(reflCaps)
+	if err != nil {
+		return nil, fmt.Errorf("reflector: %w", err)
+	}
+	timing, err := q.syncedTiming(genTiming, reflTiming)
+	if err != nil {
+		return nil, err
+	}
+
+	// The reflector is created first so it is ready for the generated packets.
+	ends := []struct {
+		name    string
+		clients gnoigo.Clients
+		configs []*plqpb.QualificationConfiguration
+	}{
+		{"reflector", reflector, q.reflectorConfigs(timing)},
+		{"generator", generator, q.generatorConfigs(timing)},
+	}
+	var created []gnoigo.Clients
+	defer func() {
+		cleanupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cleanupTimeout)
+		defer cancel()
+		for _, c := range created {
+			if err := deleteQualifications(cleanupCtx, c, q.ids()); err != nil {
+				log.Warningf("error deleting link qualifications %v: %v", q.ids(), err)
+			}
+		}
+	}()
+	for _, end := range ends {
+		resp, err := gnoigo.Execute(ctx, end.clients, NewCreateOperation().Qualification(end.configs...))
+		if err != nil {
+			return nil, fmt.Errorf("%s create: %w", end.name, err)
+		}
+		created = append(created, end.clients)
+		if err := statusErrors(resp.GetStatus()); err != nil {
+			return nil, fmt.Errorf("%s create: %w", end.name, err)
+		}
+	}
+
+	genResults, err := q.await(ctx, generator)
+	if err != nil {
+		return nil, fmt.Errorf("generator: %w", err)
+	}
+	reflResults, err := q.await(ctx, reflector)
+	if err != nil {
+		return nil, fmt.Errorf("reflector: %w", err)
+	}
+	var results []*PairResult
+	for _, p := range q.pairs {
+		pr := &PairResult{Pair: p}
+		if r, ok := genResults[p.ID]; ok {
+			pr.Generator = newInterfaceResult(r)
+		}
+		if r, ok := reflResults[p.ID]; ok {
+			pr.Reflector = newInterfaceResult(r)
+		}
+		results = append(results, pr)
+	}
+	return results, nil
+}
+
+// await polls the qualifications until they all reach a terminal state. A
+// qualification missing from the results is an error, as it never completes.
+func (q *Qualification) await(ctx context.Context, c gnoigo.Clients) (map[string]*plqpb.QualificationResult, error) {
+	for {
+		resp, err := gnoigo.Execute(ctx, c, NewGetOperation().IDs(q.ids()...))
+		if err != nil {
+			return nil, err
+		}
+		done := true
+		for _, id := range q.ids() {
+			r, ok := resp.GetResults()[id]
+			if !ok {
+				return nil, fmt.Errorf("qualification %q missing from results", id)
+			}
+			switch r.GetState() {
+			case plqpb.QualificationState_QUALIFICATION_STATE_COMPLETED, plqpb.QualificationState_QUALIFICATION_STATE_ERROR:
+			default:
+				done = false
+			}
+		}
+		if done {
+			return resp.GetResults(), nil
+		}
+		log.Infof("waiting for link qualifications %v to complete", q.ids())
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(q.pollInterval):
+		}
+	}
+}
+
+func deleteQualifications(ctx context.Context, c gnoigo.Clients, ids []string) error {
+	resp, err := gnoigo.Execute(ctx, c, NewDeleteOperation().IDs(ids...))
+	if err != nil {
+		return err
+	}
+	return statusErrors(resp.GetResults())
+}
+
+// statusErrors joins the errors of the non-OK statuses, ordered by ID.
+func statusErrors(statuses map[string]*spb.Status) error {
+	ids := make([]string, 0, len(statuses))
+	for id := range statuses {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	var errs []error
+	for _, id := range ids {
+		if s := statuses[id]; s.GetCode() != int32(code.Code_OK) {
+			errs = append(errs, fmt.Errorf("qualification %q: %w", id, status.ErrorProto(s)))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,249 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linkqual_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	plqpb "github.com/openconfig/gnoi/packet_link_qualification"
+	"github.com/openconfig/gnoigo/internal"
+	"github.com/openconfig/gnoigo/linkqual"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// fakeDevice runs each created qualification for a number of Get calls
+// before completing it with fixed counters.
+type fakeDevice struct {
+	plqpb.LinkQualificationClient
+	caps      *plqpb.CapabilitiesResponse
+	createErr map[string]*spb.Status
+	created   []*plqpb.QualificationConfiguration
+	deleted   []string
+	gets      int
+	finalErr  *plqpb.QualificationResult
+	// missing is omitted from the Get results.
+	missing string
+}
+
+func (d *fakeDevice) Capabilities(context.Context, *plqpb.CapabilitiesRequest, ...grpc.CallOption) (*plqpb.CapabilitiesResponse, error) {
+	return d.caps, nil
+}
+
+func (d *fakeDevice) Create(_ context.Context, in *plqpb.CreateRequest, _ ...grpc.CallOption) (*plqpb.CreateResponse, error) {
+	d.created = append(d.created, in.GetInterfaces()...)
+	resp := &plqpb.CreateResponse{Status: map[string]*spb.Status{}}
+	for _, c := range in.GetInterfaces() {
+		resp.Status[c.GetId()] = &spb.Status{}
+		if s, ok := d.createErr[c.GetId()]; ok {
+			resp.Status[c.GetId()] = s
+		}
+	}
+	return resp, nil
+}
+
+func (d *fakeDevice) Get(_ context.Context, in *plqpb.GetRequest, _ ...grpc.CallOption) (*plqpb.GetResponse, error) {
+	d.gets++
+	resp := &plqpb.GetResponse{Results: map[string]*plqpb.QualificationResult{}}
+	for _, c := range d.created {
+		r := &plqpb.QualificationResult{Id: c.GetId(), InterfaceName: c.GetInterfaceName(), State: plqpb.QualificationState_QUALIFICATION_STATE_RUNNING}
+		if d.gets > 1 {
+			r.State = plqpb.QualificationState_QUALIFICATION_STATE_COMPLETED
+			r.PacketsSent = 1000
+			r.PacketsReceived = 990
+			r.PacketsError = 9
+			r.PacketsDropped = 10
+			if d.finalErr != nil && d.finalErr.GetId() == c.GetId() {
+				r = d.finalErr
+			}
+		}
+		if c.GetId() != d.missing {
+			resp.Results[c.GetId()] = r
+		}
+	}
+	return resp, nil
+}
+
+func (d *fakeDevice) Delete(_ context.Context, in *plqpb.DeleteRequest, _ ...grpc.CallOption) (*plqpb.DeleteResponse, error) {
+	d.deleted = append(d.deleted, in.GetIds()...)
+	return &plqpb.DeleteResponse{}, nil
+}
+
+func generatorCaps() *plqpb.CapabilitiesResponse {
+	return &plqpb.CapabilitiesResponse{Generator: &plqpb.GeneratorCapabilities{PacketGenerator: &plqpb.PacketGeneratorCapabilities{
+		MaxPps:           1e6,
+		MinMtu:           64,
+		MaxMtu:           9000,
+		MinSetupDuration: durationpb.New(time.Second),
+	}}}
+}
+
+func reflectorCaps() *plqpb.CapabilitiesResponse {
+	return &plqpb.CapabilitiesResponse{Reflector: &plqpb.ReflectorCapabilities{PmdLoopback: &plqpb.PmdLoopbackCapabilities{}}}
+}
+
+func TestRunQualification(t *testing.T) {
+	tests := []struct {
+		desc        string
+		q           *linkqual.Qualification
+		generator   *fakeDevice
+		reflector   *fakeDevice
+		wantPassed  []bool
+		wantSetup   time.Duration
+		wantErr     string
+		wantDeleted bool
+	}{
+		{
+			desc:        "all pairs pass",
+			q:           linkqual.NewQualification().SetupDuration(time.Second),
+			generator:   &fakeDevice{caps: generatorCaps()},
+			reflector:   &fakeDevice{caps: reflectorCaps()},
+			wantPassed:  []bool{true, true},
+			wantDeleted: true,
+		},
+		{
+			desc:      "pair errors",
+			q:         linkqual.NewQualification().SetupDuration(time.Second),
+			generator: &fakeDevice{caps: generatorCaps()},
+			reflector: &fakeDevice{caps: reflectorCaps(), finalErr: &plqpb.QualificationResult{
+				Id:     "q2",
+				State:  plqpb.QualificationState_QUALIFICATION_STATE_ERROR,
+				Status: &spb.Status{Code: int32(codes.Internal), Message: "loopback failed"},
+			}},
+			wantPassed:  []bool{true, false},
+			wantDeleted: true,
+		},
+		{
+			desc:      "default setup duration",
+			q:         linkqual.NewQualification(),
+			generator: &fakeDevice{caps: generatorCaps()},
+			reflector: &fakeDevice{caps: &plqpb.CapabilitiesResponse{Reflector: &plqpb.ReflectorCapabilities{PmdLoopback: &plqpb.PmdLoopbackCapabilities{
+				MinSetupDuration: durationpb.New(2 * time.Second),
+			}}}},
+			wantPassed:  []bool{true, true},
+			wantSetup:   2 * time.Second,
+			wantDeleted: true,
+		},
+		{
+			desc:      "setup duration too short",
+			q:         linkqual.NewQualification().SetupDuration(time.Millisecond),
+			generator: &fakeDevice{caps: generatorCaps()},
+			reflector: &fakeDevice{caps: reflectorCaps()},
+			wantErr:   "setup duration",
+		},
+		{
+			desc:      "reflector without ASIC loopback",
+			q:         linkqual.NewQualification().SetupDuration(time.Second).ASICLoopback(),
+			generator: &fakeDevice{caps: generatorCaps()},
+			reflector: &fakeDevice{caps: reflectorCaps()},
+			wantErr:   "ASIC loopback not supported",
+		},
+		{
+			desc:      "packet size above MTU",
+			q:         linkqual.NewQualification().SetupDuration(time.Second).PacketGenerator(1000, 9600),
+			generator: &fakeDevice{caps: generatorCaps()},
+			reflector: &fakeDevice{caps: reflectorCaps()},
+			wantErr:   "packet size",
+		},
+		{
+			desc:        "qualification missing from results",
+			q:           linkqual.NewQualification(),
+			generator:   &fakeDevice{caps: generatorCaps()},
+			reflector:   &fakeDevice{caps: reflectorCaps(), missing: "q2"},
+			wantErr:     `qualification "q2" missing`,
+			wantDeleted: true,
+		},
+		{
+			desc:        "create error",
+			q:           linkqual.NewQualification().SetupDuration(time.Second),
+			generator:   &fakeDevice{caps: generatorCaps(), createErr: map[string]*spb.Status{"q1": {Code: int32(codes.InvalidArgument), Message: "bad interface"}}},
+			reflector:   &fakeDevice{caps: reflectorCaps()},
+			wantErr:     "bad interface",
+			wantDeleted: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			q := tt.q.Pair("q1", "Ethernet1", "Ethernet11").Pair("q2", "Ethernet2", "Ethernet12").PollInterval(time.Millisecond)
+			gen := &internal.Clients{LinkQualClient: tt.generator}
+			refl := &internal.Clients{LinkQualClient: tt.reflector}
+
+			got, err := linkqual.RunQualification(context.Background(), gen, refl, q)
+			if (err == nil) != (tt.wantErr == "") || (err != nil && !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("RunQualification() got error %v, want %q", err, tt.wantErr)
+			}
+			var wantDeleted []string
+			if tt.wantDeleted {
+				wantDeleted = []string{"q1", "q2"}
+			}
+			if diff := cmp.Diff(wantDeleted, tt.generator.deleted); diff != "" {
+				t.Errorf("RunQualification() deleted unexpected generator qualifications diff (-want +got): %s", diff)
+			}
+			if diff := cmp.Diff(wantDeleted, tt.reflector.deleted); diff != "" {
+				t.Errorf("RunQualification() deleted unexpected reflector qualifications diff (-want +got): %s", diff)
+			}
+			if err != nil {
+				return
+			}
+			var gotPassed []bool
+			for _, r := range got {
+				gotPassed = append(gotPassed, r.Passed())
+			}
+			if diff := cmp.Diff(tt.wantPassed, gotPassed); diff != "" {
+				t.Errorf("RunQualification() got unexpected pass results diff (-want +got): %s", diff)
+			}
+			if tt.wantSetup != 0 {
+				for _, c := range append(tt.generator.created, tt.reflector.created...) {
+					if got := c.GetRpc().GetSetupDuration().AsDuration(); got != tt.wantSetup {
+						t.Errorf("RunQualification() created %q with setup duration %v, want %v", c.GetId(), got, tt.wantSetup)
+					}
+				}
+			}
+			if r := got[0].Generator; r.Interface != "Ethernet1" || r.PacketsSent != 1000 || r.ErrorRate() != 9.0/990 || r.DropRate() != 0.01 {
+				t.Errorf("RunQualification() got unexpected generator result %+v", r)
+			}
+		})
+	}
+}
+
+func TestQualificationConfigs(t *testing.T) {
+	q := linkqual.NewQualification().Pair("q1", "Ethernet1", "Ethernet11").PacketGenerator(500, 512).ASICLoopback().Duration(time.Minute)
+	timing := &plqpb.QualificationConfiguration_Rpc{Rpc: &plqpb.RPCSyncedTiming{Duration: durationpb.New(time.Minute)}}
+	wantGen := []*plqpb.QualificationConfiguration{{
+		Id:            "q1",
+		InterfaceName: "Ethernet1",
+		Timing:        timing,
+		EndpointType:  &plqpb.QualificationConfiguration_PacketGenerator{PacketGenerator: &plqpb.PacketGeneratorConfiguration{PacketRate: 500, PacketSize: 512}},
+	}}
+	wantRefl := []*plqpb.QualificationConfiguration{{
+		Id:            "q1",
+		InterfaceName: "Ethernet11",
+		Timing:        timing,
+		EndpointType:  &plqpb.QualificationConfiguration_AsicLoopback{AsicLoopback: &plqpb.AsicLoopbackConfiguration{}},
+	}}
+	if diff := cmp.Diff(wantGen, q.GeneratorConfigs(), protocmp.Transform()); diff != "" {
+		t.Errorf("GeneratorConfigs() got unexpected configs diff (-want +got): %s", diff)
+	}
+	if diff := cmp.Diff(wantRefl, q.ReflectorConfigs(), protocmp.Transform()); diff != "" {
+		t.Errorf("ReflectorConfigs() got unexpected configs diff (-want +got): %s", diff)
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gnoigo
+
+import (
+	"context"
+	"time"
+
+	log "github.com/golang/glog"
+	"google.golang.org/protobuf/proto"
+)
+
+// Call describes an operation being executed, as observed by a Middleware.
+type Call struct {
+	// Operation is the operation being executed.
+	Operation any
+	// Name is the name of the operation, as returned by OperationName.
+	Name string
+	// Request is the request proto of the operation, or nil if the operation
+	// does not have a single request proto.
+	Request proto.Message
+	// Target is the target set with WithTarget.
+	Target Target
+	// Risk is the risk class of the operation.
+	Risk Risk
+}
+
+func newCall(op any, t Target) *Call {
+	c := &Call{Operation: op, Name: OperationName(op), Target: t, Risk: RiskOf(op)}
+	if r, ok := op.(interface{ Request() proto.Message }); ok {
+		c.Request = r.Request()
+	}
+	return c
+}
+
+// Handler executes the operation of a Call and returns its result, which has
+// the result type of the operation.
+type Handler func(context.Context, *Call) (any, error)
+
+// Middleware wraps the execution of operations, e.g. to log, measure, audit
+// or retry them. A middleware must return the result of next unchanged or a
+// value of the same type.
+type Middleware func(next Handler) Handler
+
+// DefaultTimeout returns a Middleware that bounds the execution of operations
+// to timeout if the context does not already have a deadline. It must not be
+// used for operations returning a stream, such as containerz.LogOperation,
+// as the stream is cancelled once the operation returns.
+func DefaultTimeout(timeout time.Duration) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, c *Call) (any, error) {
+			if _, ok := ctx.Deadline(); ok {
+				return next(ctx, c)
+			}
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			return next(ctx, c)
+		}
+	}
+}
+
+// Logging returns a Middleware that logs the outcome and duration of every
+// operation.
+func Logging() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, c *Call) (any, error) {
+			start := time.Now()
+			res, err := next(ctx, c)
+			if err != nil {
+				log.Warningf("%s on target %q failed after %v: %v", c.Name, c.Target.Name, time.Since(start), err)
+			} else {
+				log.Infof("%s on target %q succeeded after %v", c.Name, c.Target.Name, time.Since(start))
+			}
+			return res, err
+		}
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gnoigo_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	spb "github.com/openconfig/gnoi/system"
+	"github.com/openconfig/gnoigo"
+	"github.com/openconfig/gnoigo/internal"
+	"github.com/openconfig/gnoigo/system"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+// recorder returns a Middleware that appends the calls it observes to events.
+func recorder(name string, events *[]string) gnoigo.Middleware {
+	return func(next gnoigo.Handler) gnoigo.Handler {
+		return func(ctx context.Context, c *gnoigo.Call) (any, error) {
+			*events = append(*events, name+" before "+c.Name)
+			res, err := next(ctx, c)
+			outcome := "ok"
+			if err != nil {
+				outcome = "error"
+			}
+			*events = append(*events, name+" after "+outcome)
+			return res, err
+		}
+	}
+}
+
+func TestExecutorMiddleware(t *testing.T) {
+	sc := &fakeSystemClient{}
+	var events []string
+	var gotCall *gnoigo.Call
+	var gotRes any
+	observe := func(next gnoigo.Handler) gnoigo.Handler {
+		return func(ctx context.Context, c *gnoigo.Call) (any, error) {
+			gotCall = c
+			res, err := next(ctx, c)
+			gotRes = res
+			return res, err
+		}
+	}
+	exec := gnoigo.NewExecutor(&internal.Clients{SystemClient: sc},
+		gnoigo.WithTarget(gnoigo.Target{Name: "dut1"}),
+		gnoigo.WithMiddleware(recorder("outer", &events), recorder("inner", &events)),
+	)
+
+	op := system.NewRebootOperation().Message("maintenance")
+	got, err := gnoigo.Execute(context.Background(), exec, op, gnoigo.WithMiddleware(observe))
+	if err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+	if got == nil || gotRes != any(got) {
+		t.Errorf("Execute() got %v, middleware observed %v", got, gotRes)
+	}
+	wantEvents := []string{"outer before system.RebootOperation", "inner before system.RebootOperation", "inner after ok", "outer after ok"}
+	if diff := cmp.Diff(wantEvents, events); diff != "" {
+		t.Errorf("Execute() middleware events diff (-want +got): %s", diff)
+	}
+	if gotCall.Operation != op || gotCall.Target.Name != "dut1" || gotCall.Risk != gnoigo.RiskDisruptive {
+		t.Errorf("Execute() middleware observed call %+v", gotCall)
+	}
+	if diff := cmp.Diff(&spb.RebootRequest{Message: "maintenance"}, gotCall.Request, protocmp.Transform()); diff != "" {
+		t.Errorf("Execute() middleware observed request diff (-want +got): %s", diff)
+	}
+}
+
+func TestExecutorMiddlewareObservesPolicy(t *testing.T) {
+	sc := &fakeSystemClient{}
+	var events []string
+	exec := gnoigo.NewExecutor(&internal.Clients{SystemClient: sc},
+		gnoigo.WithPolicy(gnoigo.NewPolicy(gnoigo.Confirm(gnoigo.RiskDisruptive, func(context.Context, *gnoigo.Check) bool { return false }))),
+		gnoigo.WithMiddleware(recorder("audit", &events)),
+	)
+	_, err := gnoigo.Execute(context.Background(), exec, system.NewRebootOperation())
+	var denied *gnoigo.PolicyDenied
+	if !errors.As(err, &denied) {
+		t.Fatalf("Execute() got error %v, want PolicyDenied", err)
+	}
+	if diff := cmp.Diff([]string{"audit before system.RebootOperation", "audit after error"}, events); diff != "" {
+		t.Errorf("Execute() middleware events diff (-want +got): %s", diff)
+	}
+	if sc.calls != 0 {
+		t.Errorf("Execute() sent %d RPCs, want 0", sc.calls)
+	}
+}
+
+func TestExecuteMiddlewareWrongResult(t *testing.T) {
+	bad := func(gnoigo.Handler) gnoigo.Handler {
+		return func(context.Context, *gnoigo.Call) (any, error) {
+			return "not a response", nil
+		}
+	}
+	_, err := gnoigo.Execute(context.Background(), &internal.Clients{SystemClient: &fakeSystemClient{}}, system.NewTimeOperation(), gnoigo.WithMiddleware(bad))
+	if err == nil {
+		t.Errorf("Execute() got no error for middleware returning wrong result type")
+	}
+}
+
+func TestDefaultTimeout(t *testing.T) {
+	var gotDeadline time.Time
+	capture := func(next gnoigo.Handler) gnoigo.Handler {
+		return func(ctx context.Context, c *gnoigo.Call) (any, error) {
+			gotDeadline, _ = ctx.Deadline()
+			return next(ctx, c)
+		}
+	}
+	clients := &internal.Clients{SystemClient: &fakeSystemClient{}}
+
+	start := time.Now()
+	if _, err := gnoigo.Execute(context.Background(), clients, system.NewTimeOperation(), gnoigo.WithMiddleware(gnoigo.DefaultTimeout(time.Minute), capture)); err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+	if gotDeadline.Before(start.Add(time.Minute)) || gotDeadline.After(time.Now().Add(time.Minute)) {
+		t.Errorf("Execute() got deadline %v, want about one minute from %v", gotDeadline, start)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	want, _ := ctx.Deadline()
+	if _, err := gnoigo.Execute(ctx, clients, system.NewTimeOperation(), gnoigo.WithMiddleware(gnoigo.DefaultTimeout(time.Minute), capture)); err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+	if !gotDeadline.Equal(want) {
+		t.Errorf("Execute() got deadline %v, want existing deadline %v", gotDeadline, want)
+	}
+}
@@ -0,0 +1,253 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mpls provides gNOI MPLS operations.
+package mpls
+
+import (
+	"context"
+	"io"
+	"math"
+	"time"
+
+	mpb "github.com/openconfig/gnoi/mpls"
+	"github.com/openconfig/gnoigo/internal"
+	"google.golang.org/protobuf/proto"
+)
+
+// ClearLSPOperation represents the parameters of a ClearLSP operation.
+type ClearLSPOperation struct {
+	req *mpb.ClearLSPRequest
+}
+
+// NewClearLSPOperation creates an empty ClearLSPOperation.
+func NewClearLSPOperation() *ClearLSPOperation {
+	return &ClearLSPOperation{req: &mpb.ClearLSPRequest{}}
+}
+
+// Name specifies the name of the tunnel to clear.
+func (c *ClearLSPOperation) Name(name string) *ClearLSPOperation {
+	c.req.Name = name
+	return c
+}
+
+// Mode specifies the tunnel clearing mode. The default is non-aggressive.
+func (c *ClearLSPOperation) Mode(mode mpb.ClearLSPRequest_Mode) *ClearLSPOperation {
+	c.req.Mode = mode
+	return c
+}
+
+// Execute performs the ClearLSP operation.
+func (c *ClearLSPOperation) Execute(ctx context.Context, cl *internal.Clients) (*mpb.ClearLSPResponse, error) {
+	return cl.MPLS().ClearLSP(ctx, c.req)
+}
+
+// Request returns the request proto of the ClearLSP operation.
+func (c *ClearLSPOperation) Request() proto.Message {
+	return c.req
+}
+
+// Risk returns the risk class of the ClearLSP operation, which is only
+// disruptive if the LSPs are reset rather than reoptimized.
+func (c *ClearLSPOperation) Risk() internal.Risk {
+	if c.req.GetMode() == mpb.ClearLSPRequest_RESET {
+		return internal.RiskDisruptive
+	}
+	return internal.RiskLow
+}
+
+// ClearLSPCountersOperation represents the parameters of a ClearLSPCounters operation.
+type ClearLSPCountersOperation struct {
+	req *mpb.ClearLSPCountersRequest
+}
+
+// NewClearLSPCountersOperation creates an empty ClearLSPCountersOperation.
+func NewClearLSPCountersOperation() *ClearLSPCountersOperation {
+	return &ClearLSPCountersOperation{req: &mpb.ClearLSPCountersRequest{}}
+}
+
+// Name specifies the name of the tunnel to clear the counters of.
+func (c *ClearLSPCountersOperation) Name(name string) *ClearLSPCountersOperation {
+	c.req.Name = name
+	return c
+}
+
+// Execute performs the ClearLSPCounters operation.
+func (c *ClearLSPCountersOperation) Execute(ctx context.Context, cl *internal.Clients) (*mpb.ClearLSPCountersResponse, error) {
+	return cl.MPLS().ClearLSPCounters(ctx, c.req)
+}
+
+// Request returns the request proto of the ClearLSPCounters operation.
+func (c *ClearLSPCountersOperation) Request() proto.Message {
+	return c.req
+}
+
+// PingOperation represents the parameters of an MPLSPing operation.
+type PingOperation struct {
+	req     *mpb.MPLSPingRequest
+	onReply func(*mpb.MPLSPingResponse)
+}
+
+// NewPingOperation creates an empty PingOperation.
+func NewPingOperation() *PingOperation {
+	return &PingOperation{req: &mpb.MPLSPingRequest{}}
+}
+
+// LDPFEC specifies the LDP FEC to ping.
+func (p *PingOperation) LDPFEC(fec string) *PingOperation {
+	p.req.Destination = &mpb.MPLSPingRequest_LdpFec{LdpFec: fec}
+	return p
+}
+
+// RSVPTELSPName specifies the name of the RSVP-TE LSP to ping.
+func (p *PingOperation) RSVPTELSPName(name string) *PingOperation {
+	p.req.Destination = &mpb.MPLSPingRequest_RsvpteLspName{RsvpteLspName: name}
+	return p
+}
+
+// RSVPTELSP specifies the RSVP-TE LSP to ping by its source and destination
+// addresses and extended tunnel ID.
+func (p *PingOperation) RSVPTELSP(src, dst string, extendedTunnelID uint32) *PingOperation {
+	p.req.Destination = &mpb.MPLSPingRequest_RsvpteLsp{RsvpteLsp: &mpb.MPLSPingRSVPTEDestination{
+		Src:              src,
+		Dst:              dst,
+		ExtendedTunnelId: extendedTunnelID,
+	}}
+	return p
+}
+
+// ReplyMode specifies how the egress LER replies to the ping.
+func (p *PingOperation) ReplyMode(mode mpb.MPLSPingRequest_ReplyMode) *PingOperation {
+	p.req.ReplyMode = mode
+	return p
+}
+
+// Count specifies the number of echo requests to send.
+func (p *PingOperation) Count(count uint32) *PingOperation {
+	p.req.Count = count
+	return p
+}
+
+// Size specifies the size in bytes of each echo request.
+func (p *PingOperation) Size(size uint32) *PingOperation {
+	p.req.Size = size
+	return p
+}
+
+// Source specifies the source address of the echo requests.
+func (p *PingOperation) Source(src string) *PingOperation {
+	p.req.SourceAddress = src
+	return p
+}
+
+// TTL specifies the MPLS TTL of the echo requests.
+func (p *PingOperation) TTL(ttl uint32) *PingOperation {
+	p.req.MplsTtl = ttl
+	return p
+}
+
+// TrafficClass specifies the MPLS traffic class of the echo requests.
+func (p *PingOperation) TrafficClass(tc uint32) *PingOperation {
+	p.req.TrafficClass = tc
+	return p
+}
+
+// OnReply specifies a function called with each reply as it is received.
+func (p *PingOperation) OnReply(fn func(*mpb.MPLSPingResponse)) *PingOperation {
+	p.onReply = fn
+	return p
+}
+
+// PingResult summarizes the replies of an MPLSPing operation.
+type PingResult struct {
+	Replies  []*mpb.MPLSPingResponse
+	Sent     int
+	Received int
+	Timeouts int
+	NotSent  int
+	MinTime  time.Duration
+	AvgTime  time.Duration
+	MaxTime  time.Duration
+	StdDev   time.Duration
+}
+
+// Loss returns the fraction of sent echo requests that got no reply.
+func (r *PingResult) Loss() float64 {
+	if r.Sent == 0 {
+		return 0
+	}
+	return float64(r.Sent-r.Received) / float64(r.Sent)
+}
+
+// Execute performs the MPLSPing operation.
+func (p *PingOperation) Execute(ctx context.Context, c *internal.Clients) (*PingResult, error) {
+	ping, err := c.MPLS().MPLSPing(ctx, p.req)
+	if err != nil {
+		return nil, err
+	}
+
+	var replies []*mpb.MPLSPingResponse
+
+	for {
+		resp, err := ping.Recv()
+		switch {
+		case err == io.EOF:
+			return summarize(replies), nil
+		case err != nil:
+			return nil, err
+		default:
+			replies = append(replies, resp)
+			if p.onReply != nil {
+				p.onReply(resp)
+			}
+		}
+	}
+}
+
+// Request returns the request proto of the Ping operation.
+func (p *PingOperation) Request() proto.Message {
+	return p.req
+}
+
+func summarize(replies []*mpb.MPLSPingResponse) *PingResult {
+	r := &PingResult{Replies: replies}
+	var sum, sumSq float64
+	for _, reply := range replies {
+		switch reply.GetResponse() {
+		case mpb.MPLSPingResponse_NOT_SENT:
+			r.NotSent++
+			continue
+		case mpb.MPLSPingResponse_TIMEOUT:
+			r.Sent++
+			r.Timeouts++
+			continue
+		}
+		r.Sent++
+		r.Received++
+		rtt := time.Duration(reply.GetResponseTime())
+		if r.Received == 1 || rtt < r.MinTime {
+			r.MinTime = rtt
+		}
+		r.MaxTime = max(r.MaxTime, rtt)
+		sum += float64(rtt)
+		sumSq += float64(rtt) * float64(rtt)
+	}
+	if r.Received > 0 {
+		n := float64(r.Received)
+		avg := sum / n
+		r.AvgTime = time.Duration(avg)
+		r.StdDev = time.Duration(math.Sqrt(math.Max(sumSq/n-avg*avg, 0)))
+	}
+	return r
+}
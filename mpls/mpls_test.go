@@ -0,0 +1,185 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mpls_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	mpb "github.com/openconfig/gnoi/mpls"
+	"github.com/openconfig/gnoigo/internal"
+	"github.com/openconfig/gnoigo/mpls"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+type fakeMPLSClient struct {
+	mpb.MPLSClient
+	gotReq proto.Message
+	resp   []*mpb.MPLSPingResponse
+	err    error
+}
+
+func (fc *fakeMPLSClient) ClearLSP(_ context.Context, in *mpb.ClearLSPRequest, _ ...grpc.CallOption) (*mpb.ClearLSPResponse, error) {
+	fc.gotReq = in
+	return &mpb.ClearLSPResponse{}, nil
+}
+
+func (fc *fakeMPLSClient) ClearLSPCounters(_ context.Context, in *mpb.ClearLSPCountersRequest, _ ...grpc.CallOption) (*mpb.ClearLSPCountersResponse, error) {
+	fc.gotReq = in
+	return &mpb.ClearLSPCountersResponse{}, nil
+}
+
+func (fc *fakeMPLSClient) MPLSPing(_ context.Context, in *mpb.MPLSPingRequest, _ ...grpc.CallOption) (mpb.MPLS_MPLSPingClient, error) {
+	fc.gotReq = in
+	return &fakePingClient{resp: fc.resp, err: fc.err}, nil
+}
+
+type fakePingClient struct {
+	mpb.MPLS_MPLSPingClient
+	resp []*mpb.MPLSPingResponse
+	err  error
+}
+
+func (pc *fakePingClient) Recv() (*mpb.MPLSPingResponse, error) {
+	if len(pc.resp) == 0 {
+		if pc.err != nil {
+			return nil, pc.err
+		}
+		return nil, io.EOF
+	}
+	resp := pc.resp[0]
+	pc.resp = pc.resp[1:]
+	return resp, nil
+}
+
+func TestClearLSP(t *testing.T) {
+	fake := &fakeMPLSClient{}
+	if _, err := mpls.NewClearLSPOperation().Name("tunnel1").Mode(mpb.ClearLSPRequest_AGGRESSIVE).Execute(context.Background(), &internal.Clients{MPLSClient: fake}); err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+	wantReq := &mpb.ClearLSPRequest{Name: "tunnel1", Mode: mpb.ClearLSPRequest_AGGRESSIVE}
+	if diff := cmp.Diff(wantReq, fake.gotReq, protocmp.Transform()); diff != "" {
+		t.Errorf("Execute() sent unexpected request diff (-want +got): %s", diff)
+	}
+}
+
+func TestClearLSPCounters(t *testing.T) {
+	fake := &fakeMPLSClient{}
+	if _, err := mpls.NewClearLSPCountersOperation().Name("tunnel1").Execute(context.Background(), &internal.Clients{MPLSClient: fake}); err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+	if diff := cmp.Diff(&mpb.ClearLSPCountersRequest{Name: "tunnel1"}, fake.gotReq, protocmp.Transform()); diff != "" {
+		t.Errorf("Execute() sent unexpected request diff (-want +got): %s", diff)
+	}
+}
+
+func TestPing(t *testing.T) {
+	replies := []*mpb.MPLSPingResponse{
+		{Seq: 1, Response: mpb.MPLSPingResponse_SUCCESS, ResponseTime: uint64(2 * time.Millisecond)},
+		{Seq: 2, Response: mpb.MPLSPingResponse_TIMEOUT},
+		{Seq: 3, Response: mpb.MPLSPingResponse_SUCCESS, ResponseTime: uint64(4 * time.Millisecond)},
+		{Seq: 4, Response: mpb.MPLSPingResponse_NOT_SENT},
+	}
+	tests := []struct {
+		desc    string
+		op      *mpls.PingOperation
+		err     error
+		wantReq *mpb.MPLSPingRequest
+		want    *mpls.PingResult
+		wantErr bool
+	}{
+		{
+			desc: "LDP FEC",
+			op:   mpls.NewPingOperation().LDPFEC("192.0.2.1/32").Count(4).Size(100).TTL(10),
+			wantReq: &mpb.MPLSPingRequest{
+				Destination: &mpb.MPLSPingRequest_LdpFec{LdpFec: "192.0.2.1/32"},
+				Count:       4,
+				Size:        100,
+				MplsTtl:     10,
+			},
+			want: &mpls.PingResult{
+				Replies:  replies,
+				Sent:     3,
+				Received: 2,
+				Timeouts: 1,
+				NotSent:  1,
+				MinTime:  2 * time.Millisecond,
+				AvgTime:  3 * time.Millisecond,
+				MaxTime:  4 * time.Millisecond,
+				StdDev:   time.Millisecond,
+			},
+		},
+		{
+			desc: "RSVP-TE LSP",
+			op:   mpls.NewPingOperation().RSVPTELSP("192.0.2.1", "192.0.2.2", 7).ReplyMode(mpb.MPLSPingRequest_ROUTER_ALERT).Source("192.0.2.1").TrafficClass(5),
+			err:  errors.New("ping failed"),
+			wantReq: &mpb.MPLSPingRequest{
+				Destination:   &mpb.MPLSPingRequest_RsvpteLsp{RsvpteLsp: &mpb.MPLSPingRSVPTEDestination{Src: "192.0.2.1", Dst: "192.0.2.2", ExtendedTunnelId: 7}},
+				ReplyMode:     mpb.MPLSPingRequest_ROUTER_ALERT,
+				SourceAddress: "192.0.2.1",
+				TrafficClass:  5,
+			},
+			wantErr: true,
+		},
+		{
+			desc:    "RSVP-TE LSP name",
+			op:      mpls.NewPingOperation().RSVPTELSPName("tunnel1"),
+			wantReq: &mpb.MPLSPingRequest{Destination: &mpb.MPLSPingRequest_RsvpteLspName{RsvpteLspName: "tunnel1"}},
+			want: &mpls.PingResult{
+				Replies:  replies,
+				Sent:     3,
+				Received: 2,
+				Timeouts: 1,
+				NotSent:  1,
+				MinTime:  2 * time.Millisecond,
+				AvgTime:  3 * time.Millisecond,
+				MaxTime:  4 * time.Millisecond,
+				StdDev:   time.Millisecond,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			fake := &fakeMPLSClient{resp: replies, err: tt.err}
+			var streamed []*mpb.MPLSPingResponse
+			got, err := tt.op.OnReply(func(r *mpb.MPLSPingResponse) { streamed = append(streamed, r) }).Execute(context.Background(), &internal.Clients{MPLSClient: fake})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() got error %v, want error %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.wantReq, fake.gotReq, protocmp.Transform()); diff != "" {
+				t.Errorf("Execute() sent unexpected request diff (-want +got): %s", diff)
+			}
+			if diff := cmp.Diff(replies, streamed, protocmp.Transform()); diff != "" {
+				t.Errorf("OnReply() got unexpected replies diff (-want +got): %s", diff)
+			}
+			if diff := cmp.Diff(tt.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("Execute() got unexpected result diff (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestPingLoss(t *testing.T) {
+	r := &mpls.PingResult{Sent: 4, Received: 3}
+	if got, want := r.Loss(), 0.25; got != want {
+		t.Errorf("Loss() got %v, want %v", got, want)
+	}
+}
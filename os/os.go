@@ -23,6 +23,7 @@ import (
 	log "github.com/golang/glog"
 	ospb "github.com/openconfig/gnoi/os"
 	"github.com/openconfig/gnoigo/internal"
+	"google.golang.org/protobuf/proto"
 )
 
 // ActivateOperation represents the parameters of a Activate operation.
@@ -61,6 +62,20 @@ func (a *ActivateOperation) Execute(ctx context.Context, c *internal.Clients) (*
 	return c.OS().Activate(ctx, a.req)
 }
 
+// Request returns the request proto of the Activate operation.
+func (a *ActivateOperation) Request() proto.Message {
+	return a.req
+}
+
+// Risk returns the risk class of the Activate operation, which is only
+// disruptive if the activation reboots the target.
+func (a *ActivateOperation) Risk() internal.Risk {
+	if a.req.GetNoReboot() {
+		return internal.RiskLow
+	}
+	return internal.RiskDisruptive
+}
+
 // InstallOperation represents the parameters of a Install operation.
 type InstallOperation struct {
 	req    *ospb.TransferRequest
@@ -144,7 +159,7 @@ func transferContent(ctx context.Context, ic ospb.OS_InstallClient, reader io.Re
 					},
 				},
 			); sendErr != nil {
-				return err
+				return sendErr
 			}
 		}
 		if err == io.EOF {
@@ -164,11 +179,19 @@ func transferContent(ctx context.Context, ic ospb.OS_InstallClient, reader io.Re
 }
 
 // Execute performs the Install operation.
+//
+// The install stream is torn down before Execute returns: on success it is
+// closed for sending, and on cancellation or any send or receive failure its
+// context is cancelled, so no goroutine started by Execute outlives it.
 func (i *InstallOperation) Execute(ctx context.Context, c *internal.Clients) (*ospb.InstallResponse, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	ic, icErr := c.OS().Install(ctx)
 	if icErr != nil {
 		return nil, icErr
 	}
+	defer ic.CloseSend()
 
 	installReq := &ospb.InstallRequest{
 		Request: &ospb.InstallRequest_TransferRequest{
@@ -190,21 +213,44 @@ func (i *InstallOperation) Execute(ctx context.Context, c *internal.Clients) (*o
 	if i.reader == nil {
 		return nil, fmt.Errorf("no reader specified for install operation")
 	}
-	awaitChan := make(chan error)
+
+	type result struct {
+		resp *ospb.InstallResponse
+		err  error
+	}
+	// The channel is buffered so that the receiver never blocks on it, even if
+	// Execute has already given up on the transfer.
+	awaitChan := make(chan result, 1)
 	go func() {
-		installResp, err = awaitPackageInstall(ctx, ic)
-		awaitChan <- err
+		resp, err := awaitPackageInstall(ctx, ic)
+		if err != nil {
+			// Stop the sender, there is no point in transferring more content.
+			cancel()
+		}
+		awaitChan <- result{resp: resp, err: err}
 	}()
-	if err := transferContent(ctx, ic, i.reader); err != nil {
-		return nil, err
+	sendErr := transferContent(ctx, ic, i.reader)
+	if sendErr != nil {
+		// Unblock the receiver, which may be waiting for a response that will
+		// never come now that the transfer has been abandoned.
+		cancel()
+	} else if err := ic.CloseSend(); err != nil {
+		cancel()
+		sendErr = err
 	}
-	if err := <-awaitChan; err != nil {
+	res := <-awaitChan
+	if err := internal.MergeStreamErrors(sendErr, res.err); err != nil {
 		return nil, err
 	}
-	if gotVersion := installResp.GetValidated().GetVersion(); gotVersion != i.req.Version {
+	if gotVersion := res.resp.GetValidated().GetVersion(); gotVersion != i.req.Version {
 		return nil, fmt.Errorf("installed version %q does not match requested version %q", gotVersion, i.req.Version)
 	}
-	return installResp, nil
+	return res.resp, nil
+}
+
+// Request returns the request proto of the Install operation.
+func (i *InstallOperation) Request() proto.Message {
+	return i.req
 }
 
 // VerifyOperation represents the parameters of a Verify operation.
@@ -221,3 +267,13 @@ func NewVerifyOperation() *VerifyOperation {
 func (v *VerifyOperation) Execute(ctx context.Context, c *internal.Clients) (*ospb.VerifyResponse, error) {
 	return c.OS().Verify(ctx, v.req)
 }
+
+// Request returns the request proto of the Verify operation.
+func (v *VerifyOperation) Request() proto.Message {
+	return v.req
+}
+
+// Idempotent reports that the Verify operation can safely be retried.
+func (v *VerifyOperation) Idempotent() bool {
+	return true
+}
@@ -18,9 +18,12 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"io"
 	"os"
+	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	ospb "github.com/openconfig/gnoi/os"
@@ -220,6 +223,146 @@ func TestInstall(t *testing.T) {
 	}
 }
 
+// streamInstallClient is an install stream bound to the context of the
+// Install call. Recv blocks until a response is queued or the context is done.
+type streamInstallClient struct {
+	ospb.OS_InstallClient
+	ctx     context.Context
+	recv    chan *ospb.InstallResponse
+	sendErr error
+	closed  bool
+}
+
+func (ic *streamInstallClient) Send(req *ospb.InstallRequest) error {
+	if err := ic.ctx.Err(); err != nil {
+		return err
+	}
+	if req.GetTransferContent() != nil && ic.sendErr != nil {
+		return ic.sendErr
+	}
+	return nil
+}
+
+func (ic *streamInstallClient) Recv() (*ospb.InstallResponse, error) {
+	select {
+	case <-ic.ctx.Done():
+		return nil, ic.ctx.Err()
+	case resp := <-ic.recv:
+		return resp, nil
+	}
+}
+
+func (ic *streamInstallClient) CloseSend() error {
+	ic.closed = true
+	return nil
+}
+
+// checkNoGoroutineLeak fails the test if the number of goroutines does not
+// return to the count at the time it was called.
+func checkNoGoroutineLeak(t *testing.T) func() {
+	t.Helper()
+	before := runtime.NumGoroutine()
+	return func() {
+		t.Helper()
+		for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+			if runtime.NumGoroutine() <= before {
+				return
+			}
+		}
+		t.Errorf("goroutine leak: got %d goroutines, want at most %d", runtime.NumGoroutine(), before)
+	}
+}
+
+func TestInstallTeardown(t *testing.T) {
+	const version = "1.2.3"
+	transferReady := &ospb.InstallResponse{Response: &ospb.InstallResponse_TransferReady{TransferReady: &ospb.TransferReady{}}}
+
+	tests := []struct {
+		desc    string
+		resps   []*ospb.InstallResponse
+		sendErr error
+		cancel  bool
+		wantErr string
+	}{
+		{
+			desc:  "transfer completes",
+			resps: []*ospb.InstallResponse{transferReady, {Response: &ospb.InstallResponse_Validated{Validated: &ospb.Validated{Version: version}}}},
+		},
+		{
+			desc:    "send fails while receiver waits",
+			resps:   []*ospb.InstallResponse{transferReady},
+			sendErr: errors.New("broken pipe"),
+			wantErr: "broken pipe",
+		},
+		{
+			desc: "receive fails while sender transfers",
+			resps: []*ospb.InstallResponse{transferReady, {Response: &ospb.InstallResponse_InstallError{
+				InstallError: &ospb.InstallError{Type: ospb.InstallError_TOO_LARGE},
+			}}},
+			wantErr: "TOO_LARGE",
+		},
+		{
+			desc:    "context cancelled during transfer",
+			resps:   []*ospb.InstallResponse{transferReady},
+			cancel:  true,
+			wantErr: "context canceled",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			defer checkNoGoroutineLeak(t)()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var ic *streamInstallClient
+			var fakeClient internal.Clients
+			fakeClient.OSClient = &fakeOSClient{InstallFn: func(ctx context.Context, _ ...grpc.CallOption) (ospb.OS_InstallClient, error) {
+				ic = &streamInstallClient{ctx: ctx, recv: make(chan *ospb.InstallResponse, len(tt.resps)), sendErr: tt.sendErr}
+				for _, r := range tt.resps {
+					ic.recv <- r
+				}
+				return ic, nil
+			}}
+
+			// A reader that never ends keeps the sender busy until it is stopped.
+			var reader io.Reader = infiniteReader{}
+			if tt.cancel {
+				reader = &cancelReader{cancel: cancel}
+			} else if tt.wantErr == "" {
+				reader = bytes.NewReader([]byte{0})
+			}
+
+			_, gotErr := gos.NewInstallOperation().Version(version).Reader(reader).Execute(ctx, &fakeClient)
+			if (gotErr == nil) != (tt.wantErr == "") || (gotErr != nil && !strings.Contains(gotErr.Error(), tt.wantErr)) {
+				t.Errorf("Execute() got unexpected error %v want %s", gotErr, tt.wantErr)
+			}
+			if !ic.closed {
+				t.Errorf("Execute() did not close the install stream")
+			}
+			if ic.ctx.Err() == nil {
+				t.Errorf("Execute() did not cancel the install stream context")
+			}
+		})
+	}
+}
+
+type infiniteReader struct{}
+
+func (infiniteReader) Read(p []byte) (int, error) {
+	return len(p), nil
+}
+
+// cancelReader cancels the context on its first read.
+type cancelReader struct {
+	cancel context.CancelFunc
+}
+
+func (r *cancelReader) Read(p []byte) (int, error) {
+	r.cancel()
+	return len(p), nil
+}
+
 func TestVerify(t *testing.T) {
 	tests := []struct {
 		desc    string
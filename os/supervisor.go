@@ -0,0 +1,283 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package os
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	log "github.com/golang/glog"
+	ospb "github.com/openconfig/gnoi/os"
+	spb "github.com/openconfig/gnoi/system"
+	"github.com/openconfig/gnoigo/internal"
+	"github.com/openconfig/gnoigo/system"
+)
+
+// StandbyStatus describes the standby supervisor as reported by Verify.
+type StandbyStatus int
+
+const (
+	// StandbyAbsent means the Target did not report any standby state.
+	StandbyAbsent StandbyStatus = iota
+	// StandbyUnsupported means the Target does not support dual supervisors.
+	StandbyUnsupported
+	// StandbyNonExistent means dual supervisors are supported but the standby
+	// does not exist.
+	StandbyNonExistent
+	// StandbyUnavailable means the standby exists but is not available, e.g.
+	// because it is rebooting.
+	StandbyUnavailable
+	// StandbyRunning means the standby is present and ready.
+	StandbyRunning
+)
+
+// String returns a human-readable name of the standby status.
+func (s StandbyStatus) String() string {
+	switch s {
+	case StandbyAbsent:
+		return "absent"
+	case StandbyUnsupported:
+		return "unsupported"
+	case StandbyNonExistent:
+		return "non-existent"
+	case StandbyUnavailable:
+		return "unavailable"
+	case StandbyRunning:
+		return "running"
+	default:
+		return fmt.Sprintf("StandbyStatus(%d)", int(s))
+	}
+}
+
+// StandbyStatusOf returns the standby supervisor status reported in a VerifyResponse.
+func StandbyStatusOf(resp *ospb.VerifyResponse) StandbyStatus {
+	vs := resp.GetVerifyStandby()
+	if vs.GetVerifyResponse() != nil {
+		return StandbyRunning
+	}
+	if vs.GetStandbyState() == nil {
+		return StandbyAbsent
+	}
+	switch vs.GetStandbyState().GetState() {
+	case ospb.StandbyState_UNSUPPORTED:
+		return StandbyUnsupported
+	case ospb.StandbyState_NON_EXISTENT:
+		return StandbyNonExistent
+	case ospb.StandbyState_UNAVAILABLE:
+		return StandbyUnavailable
+	default:
+		return StandbyAbsent
+	}
+}
+
+// defaultStandbyPollInterval is the time between Verify calls while waiting
+// for the standby supervisor to boot the activated version.
+const defaultStandbyPollInterval = 10 * time.Second
+
+// UpgradeOperation represents the parameters of an OS upgrade that installs
+// and activates a version on every supervisor of a Target.
+type UpgradeOperation struct {
+	version      string
+	reader       io.Reader
+	noReboot     bool
+	switchover   string
+	pollInterval time.Duration
+}
+
+// NewUpgradeOperation creates an empty UpgradeOperation.
+func NewUpgradeOperation() *UpgradeOperation {
+	return &UpgradeOperation{pollInterval: defaultStandbyPollInterval}
+}
+
+// Version specifies the OS version to install and activate.
+func (u *UpgradeOperation) Version(version string) *UpgradeOperation {
+	u.version = version
+	return u
+}
+
+// Reader specifies the package reader for the OS file. If the reader is an
+// io.Seeker, it is rewound before the package is transferred a second time.
+// Otherwise the upgrade fails if the standby supervisor requests the package
+// instead of syncing it from the active supervisor.
+func (u *UpgradeOperation) Reader(reader io.Reader) *UpgradeOperation {
+	u.reader = reader
+	return u
+}
+
+// NoReboot specifies whether to skip the reboot after activating the version.
+// It is ignored when a switchover is requested.
+func (u *UpgradeOperation) NoReboot(noReboot bool) *UpgradeOperation {
+	u.noReboot = noReboot
+	return u
+}
+
+// Switchover specifies the component name of the standby supervisor to switch
+// to once it runs the new version, which makes the upgrade hitless.
+func (u *UpgradeOperation) Switchover(componentName string) *UpgradeOperation {
+	u.switchover = componentName
+	return u
+}
+
+// PollInterval specifies the time between Verify calls while waiting for the
+// standby supervisor to boot the activated version.
+func (u *UpgradeOperation) PollInterval(interval time.Duration) *UpgradeOperation {
+	u.pollInterval = interval
+	return u
+}
+
+// UpgradeResult contains the responses collected by an UpgradeOperation.
+// The standby fields are only set if the Target requires one Install and
+// Activate per supervisor.
+type UpgradeResult struct {
+	Standby          StandbyStatus
+	ActiveInstall    *ospb.InstallResponse
+	StandbyInstall   *ospb.InstallResponse
+	ActiveActivate   *ospb.ActivateResponse
+	StandbyActivate  *ospb.ActivateResponse
+	SwitchoverResult *spb.SwitchControlProcessorResponse
+}
+
+// Risk returns the risk class of the Upgrade operation, which is only low if
+// the target is not rebooted and no switchover is requested.
+func (u *UpgradeOperation) Risk() internal.Risk {
+	if u.noReboot && u.switchover == "" {
+		return internal.RiskLow
+	}
+	return internal.RiskDisruptive
+}
+
+// Execute performs the Upgrade operation.
+func (u *UpgradeOperation) Execute(ctx context.Context, c *internal.Clients) (*UpgradeResult, error) {
+	verifyResp, err := NewVerifyOperation().Execute(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	res := &UpgradeResult{Standby: StandbyStatusOf(verifyResp)}
+	if res.Standby == StandbyUnavailable {
+		return nil, fmt.Errorf("standby supervisor is unavailable")
+	}
+	perSupervisor := res.Standby == StandbyRunning && verifyResp.GetIndividualSupervisorInstall()
+	if u.switchover != "" && res.Standby != StandbyRunning {
+		return nil, fmt.Errorf("switchover requested but standby supervisor is %v", res.Standby)
+	}
+
+	if res.ActiveInstall, err = NewInstallOperation().Version(u.version).Reader(u.reader).Execute(ctx, c); err != nil {
+		return nil, fmt.Errorf("install on active supervisor: %w", err)
+	}
+	if perSupervisor {
+		// The Target syncs the package from the active supervisor first, so the
+		// reader is only consumed again if that sync is not possible.
+		reader := u.reader
+		if s, ok := reader.(io.Seeker); ok {
+			if _, err := s.Seek(0, io.SeekStart); err != nil {
+				return nil, err
+			}
+		} else if reader != nil {
+			reader = drainedReader{}
+		}
+		if res.StandbyInstall, err = NewInstallOperation().Version(u.version).Reader(reader).Standby(true).Execute(ctx, c); err != nil {
+			return nil, fmt.Errorf("install on standby supervisor: %w", err)
+		}
+	}
+
+	if u.switchover != "" {
+		return u.hitlessActivate(ctx, c, res, perSupervisor)
+	}
+	if perSupervisor {
+		if res.StandbyActivate, err = activate(ctx, c, NewActivateOperation().Version(u.version).Standby(true).NoReboot(u.noReboot)); err != nil {
+			return nil, fmt.Errorf("activate on standby supervisor: %w", err)
+		}
+	}
+	if res.ActiveActivate, err = activate(ctx, c, NewActivateOperation().Version(u.version).NoReboot(u.noReboot)); err != nil {
+		return nil, fmt.Errorf("activate on active supervisor: %w", err)
+	}
+	return res, nil
+}
+
+// hitlessActivate reboots the standby supervisor into the new version, waits
+// for it to come back, activates the version on the active supervisor without
+// rebooting and then switches over to the standby. If the Target installs on
+// both supervisors at once, the standby is only upgraded by the activation on
+// the active supervisor, so the wait follows that activation instead.
+func (u *UpgradeOperation) hitlessActivate(ctx context.Context, c *internal.Clients, res *UpgradeResult, perSupervisor bool) (*UpgradeResult, error) {
+	var err error
+	if perSupervisor {
+		if res.StandbyActivate, err = activate(ctx, c, NewActivateOperation().Version(u.version).Standby(true)); err != nil {
+			return nil, fmt.Errorf("activate on standby supervisor: %w", err)
+		}
+		if err := u.awaitStandbyVersion(ctx, c); err != nil {
+			return nil, err
+		}
+	}
+	if res.ActiveActivate, err = activate(ctx, c, NewActivateOperation().Version(u.version).NoReboot(true)); err != nil {
+		return nil, fmt.Errorf("activate on active supervisor: %w", err)
+	}
+	if !perSupervisor {
+		if err := u.awaitStandbyVersion(ctx, c); err != nil {
+			return nil, err
+		}
+	}
+	if res.SwitchoverResult, err = system.NewSwitchControlProcessorOperation().PathFromSubcomponentName(u.switchover).Execute(ctx, c); err != nil {
+		return nil, fmt.Errorf("switchover to %q: %w", u.switchover, err)
+	}
+	return res, nil
+}
+
+// awaitStandbyVersion polls Verify until the standby supervisor runs the
+// requested version, reports an activation failure, or context is cancelled.
+func (u *UpgradeOperation) awaitStandbyVersion(ctx context.Context, c *internal.Clients) error {
+	for {
+		resp, err := NewVerifyOperation().Execute(ctx, c)
+		if err != nil {
+			return err
+		}
+		standby := resp.GetVerifyStandby().GetVerifyResponse()
+		if msg := standby.GetActivationFailMessage(); msg != "" {
+			return fmt.Errorf("standby supervisor activation failed: %s", msg)
+		}
+		if StandbyStatusOf(resp) == StandbyRunning && standby.GetVersion() == u.version {
+			return nil
+		}
+		log.Infof("waiting for standby supervisor to run version %q: standby is %v", u.version, StandbyStatusOf(resp))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(u.pollInterval):
+		}
+	}
+}
+
+// drainedReader stands in for a package reader that was consumed by the
+// install on the active supervisor and cannot be rewound, so that the package
+// is never transferred to the standby supervisor truncated.
+type drainedReader struct{}
+
+func (drainedReader) Read([]byte) (int, error) {
+	return 0, errors.New("standby supervisor requested the package but the reader is not an io.Seeker and cannot be rewound")
+}
+
+func activate(ctx context.Context, c *internal.Clients, op *ActivateOperation) (*ospb.ActivateResponse, error) {
+	resp, err := op.Execute(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	if ae := resp.GetActivateError(); ae != nil {
+		return nil, fmt.Errorf("activation error %q: %s", ae.GetType(), ae.GetDetail())
+	}
+	return resp, nil
+}
@@ -0,0 +1,263 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package os_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	ospb "github.com/openconfig/gnoi/os"
+	spb "github.com/openconfig/gnoi/system"
+	"github.com/openconfig/gnoigo/internal"
+	gos "github.com/openconfig/gnoigo/os"
+	"google.golang.org/grpc"
+)
+
+type fakeSwitchoverClient struct {
+	spb.SystemClient
+	gotReqs []*spb.SwitchControlProcessorRequest
+}
+
+func (fs *fakeSwitchoverClient) SwitchControlProcessor(_ context.Context, in *spb.SwitchControlProcessorRequest, _ ...grpc.CallOption) (*spb.SwitchControlProcessorResponse, error) {
+	fs.gotReqs = append(fs.gotReqs, in)
+	return &spb.SwitchControlProcessorResponse{}, nil
+}
+
+func TestStandbyStatusOf(t *testing.T) {
+	tests := []struct {
+		desc string
+		resp *ospb.VerifyResponse
+		want gos.StandbyStatus
+	}{
+		{
+			desc: "no standby",
+			resp: &ospb.VerifyResponse{},
+			want: gos.StandbyAbsent,
+		},
+		{
+			desc: "non-existent",
+			resp: &ospb.VerifyResponse{VerifyStandby: &ospb.VerifyStandby{State: &ospb.VerifyStandby_StandbyState{
+				StandbyState: &ospb.StandbyState{State: ospb.StandbyState_NON_EXISTENT},
+			}}},
+			want: gos.StandbyNonExistent,
+		},
+		{
+			desc: "unavailable",
+			resp: &ospb.VerifyResponse{VerifyStandby: &ospb.VerifyStandby{State: &ospb.VerifyStandby_StandbyState{
+				StandbyState: &ospb.StandbyState{State: ospb.StandbyState_UNAVAILABLE},
+			}}},
+			want: gos.StandbyUnavailable,
+		},
+		{
+			desc: "running",
+			resp: &ospb.VerifyResponse{VerifyStandby: &ospb.VerifyStandby{State: &ospb.VerifyStandby_VerifyResponse{
+				VerifyResponse: &ospb.StandbyResponse{Id: "1"},
+			}}},
+			want: gos.StandbyRunning,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			if got := gos.StandbyStatusOf(tt.resp); got != tt.want {
+				t.Errorf("StandbyStatusOf() got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpgrade(t *testing.T) {
+	const version = "1.2.3"
+	standbyRunning := func(v string) *ospb.VerifyResponse {
+		return &ospb.VerifyResponse{
+			IndividualSupervisorInstall: true,
+			VerifyStandby: &ospb.VerifyStandby{State: &ospb.VerifyStandby_VerifyResponse{
+				VerifyResponse: &ospb.StandbyResponse{Id: "1", Version: v},
+			}},
+		}
+	}
+	standbyShared := func(v string) *ospb.VerifyResponse {
+		resp := standbyRunning(v)
+		resp.IndividualSupervisorInstall = false
+		return resp
+	}
+	validated := []*ospb.InstallResponse{
+		{Response: &ospb.InstallResponse_Validated{Validated: &ospb.Validated{Version: version}}},
+	}
+
+	type activation struct {
+		Standby, NoReboot bool
+	}
+	tests := []struct {
+		desc           string
+		op             *gos.UpgradeOperation
+		verify         []*ospb.VerifyResponse
+		wantInstalls   []bool
+		wantActivates  []activation
+		wantSwitchover bool
+		wantErr        string
+	}{
+		{
+			desc:          "single supervisor",
+			op:            gos.NewUpgradeOperation().Version(version),
+			verify:        []*ospb.VerifyResponse{{}},
+			wantInstalls:  []bool{false},
+			wantActivates: []activation{{}},
+		},
+		{
+			desc:          "per supervisor install",
+			op:            gos.NewUpgradeOperation().Version(version).NoReboot(true),
+			verify:        []*ospb.VerifyResponse{standbyRunning("1.0.0")},
+			wantInstalls:  []bool{false, true},
+			wantActivates: []activation{{Standby: true, NoReboot: true}, {NoReboot: true}},
+		},
+		{
+			desc:           "hitless switchover",
+			op:             gos.NewUpgradeOperation().Version(version).Switchover("RP1").PollInterval(time.Millisecond),
+			verify:         []*ospb.VerifyResponse{standbyRunning("1.0.0"), standbyRunning("1.0.0"), standbyRunning(version)},
+			wantInstalls:   []bool{false, true},
+			wantActivates:  []activation{{Standby: true}, {NoReboot: true}},
+			wantSwitchover: true,
+		},
+		{
+			desc:           "hitless switchover with shared install",
+			op:             gos.NewUpgradeOperation().Version(version).Switchover("RP1").PollInterval(time.Millisecond),
+			verify:         []*ospb.VerifyResponse{standbyShared("1.0.0"), standbyShared("1.0.0"), standbyShared(version)},
+			wantInstalls:   []bool{false},
+			wantActivates:  []activation{{NoReboot: true}},
+			wantSwitchover: true,
+		},
+		{
+			desc: "shared install standby activation failed",
+			op:   gos.NewUpgradeOperation().Version(version).Switchover("RP1").PollInterval(time.Millisecond),
+			verify: []*ospb.VerifyResponse{standbyShared("1.0.0"), {
+				VerifyStandby: &ospb.VerifyStandby{State: &ospb.VerifyStandby_VerifyResponse{
+					VerifyResponse: &ospb.StandbyResponse{Id: "1", Version: "1.0.0", ActivationFailMessage: "bad image"},
+				}},
+			}},
+			wantInstalls:  []bool{false},
+			wantActivates: []activation{{NoReboot: true}},
+			wantErr:       "activation failed",
+		},
+		{
+			desc: "standby unavailable",
+			op:   gos.NewUpgradeOperation().Version(version),
+			verify: []*ospb.VerifyResponse{{VerifyStandby: &ospb.VerifyStandby{State: &ospb.VerifyStandby_StandbyState{
+				StandbyState: &ospb.StandbyState{State: ospb.StandbyState_UNAVAILABLE},
+			}}}},
+			wantErr: "unavailable",
+		},
+		{
+			desc:    "switchover without standby",
+			op:      gos.NewUpgradeOperation().Version(version).Switchover("RP1"),
+			verify:  []*ospb.VerifyResponse{{}},
+			wantErr: "switchover",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var gotInstalls []bool
+			var gotActivates []activation
+			verify := tt.verify
+			sc := &fakeSwitchoverClient{}
+			fakeClient := internal.Clients{SystemClient: sc}
+			fakeClient.OSClient = &fakeOSClient{
+				VerifyFn: func(context.Context, *ospb.VerifyRequest, ...grpc.CallOption) (*ospb.VerifyResponse, error) {
+					resp := verify[0]
+					if len(verify) > 1 {
+						verify = verify[1:]
+					}
+					return resp, nil
+				},
+				InstallFn: func(context.Context, ...grpc.CallOption) (ospb.OS_InstallClient, error) {
+					ic := &fakeInstallClient{stubRecv: append([]*ospb.InstallResponse(nil), validated...)}
+					gotInstalls = append(gotInstalls, false)
+					return &recordingInstallClient{fakeInstallClient: ic, standby: &gotInstalls[len(gotInstalls)-1]}, nil
+				},
+				ActivateFn: func(_ context.Context, in *ospb.ActivateRequest, _ ...grpc.CallOption) (*ospb.ActivateResponse, error) {
+					gotActivates = append(gotActivates, activation{Standby: in.GetStandbySupervisor(), NoReboot: in.GetNoReboot()})
+					return &ospb.ActivateResponse{Response: &ospb.ActivateResponse_ActivateOk{}}, nil
+				},
+			}
+
+			_, gotErr := tt.op.Execute(context.Background(), &fakeClient)
+			if (gotErr == nil) != (tt.wantErr == "") || (gotErr != nil && !strings.Contains(gotErr.Error(), tt.wantErr)) {
+				t.Fatalf("Execute() got unexpected error %v want %s", gotErr, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.wantInstalls, gotInstalls); diff != "" {
+				t.Errorf("Execute() got unexpected installs diff (-want +got): %s", diff)
+			}
+			if diff := cmp.Diff(tt.wantActivates, gotActivates); diff != "" {
+				t.Errorf("Execute() got unexpected activations diff (-want +got): %s", diff)
+			}
+			if gotSwitchover := len(sc.gotReqs) > 0; gotSwitchover != tt.wantSwitchover {
+				t.Errorf("Execute() got switchover %v, want %v", gotSwitchover, tt.wantSwitchover)
+			}
+		})
+	}
+}
+
+// recordingInstallClient records whether the install targets the standby supervisor.
+type recordingInstallClient struct {
+	*fakeInstallClient
+	standby *bool
+}
+
+func (rc *recordingInstallClient) Send(req *ospb.InstallRequest) error {
+	if tr := req.GetTransferRequest(); tr != nil {
+		*rc.standby = tr.GetStandbySupervisor()
+	}
+	return rc.fakeInstallClient.Send(req)
+}
+
+func TestUpgradeUnseekableReader(t *testing.T) {
+	const version = "1.2.3"
+	var installs []*fakeInstallClient
+	fakeClient := internal.Clients{OSClient: &fakeOSClient{
+		VerifyFn: func(context.Context, *ospb.VerifyRequest, ...grpc.CallOption) (*ospb.VerifyResponse, error) {
+			return &ospb.VerifyResponse{
+				IndividualSupervisorInstall: true,
+				VerifyStandby: &ospb.VerifyStandby{State: &ospb.VerifyStandby_VerifyResponse{
+					VerifyResponse: &ospb.StandbyResponse{Id: "1", Version: "1.0.0"},
+				}},
+			}, nil
+		},
+		InstallFn: func(context.Context, ...grpc.CallOption) (ospb.OS_InstallClient, error) {
+			ic := &fakeInstallClient{stubRecv: []*ospb.InstallResponse{
+				{Response: &ospb.InstallResponse_TransferReady{TransferReady: &ospb.TransferReady{}}},
+				{Response: &ospb.InstallResponse_Validated{Validated: &ospb.Validated{Version: version}}},
+			}}
+			installs = append(installs, ic)
+			return ic, nil
+		},
+	}}
+
+	op := gos.NewUpgradeOperation().Version(version).Reader(io.MultiReader(strings.NewReader("package"))).NoReboot(true)
+	_, err := op.Execute(context.Background(), &fakeClient)
+	if err == nil || !strings.Contains(err.Error(), "cannot be rewound") {
+		t.Fatalf("Execute() got error %v, want error about the reader", err)
+	}
+	if len(installs) != 2 {
+		t.Fatalf("Execute() started %d installs, want 2", len(installs))
+	}
+	for _, req := range installs[1].gotSent {
+		if req.GetTransferContent() != nil || req.GetTransferEnd() != nil {
+			t.Errorf("Execute() sent %v to the standby supervisor, want no package transfer", req)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package otdr provides gNOI OTDR operations.
+package otdr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"time"
+
+	otpb "github.com/openconfig/gnoi/otdr"
+	tpb "github.com/openconfig/gnoi/types"
+	"github.com/openconfig/gnoigo/internal"
+	"google.golang.org/protobuf/proto"
+)
+
+// InitiateOperation represents the parameters of an Initiate operation.
+type InitiateOperation struct {
+	req *otpb.InitiateRequest
+}
+
+// NewInitiateOperation creates an empty InitiateOperation.
+func NewInitiateOperation() *InitiateOperation {
+	return &InitiateOperation{req: &otpb.InitiateRequest{Configuration: &otpb.OTDRConfiguration{}}}
+}
+
+// PathFromSubcomponentName sets the path of the OTDR component to `/openconfig/components/component[name=<n>]`.
+func (i *InitiateOperation) PathFromSubcomponentName(n string) *InitiateOperation {
+	return i.Component(internal.ComponentPath(n))
+}
+
+// Component sets the path of the OTDR component.
+func (i *InitiateOperation) Component(p *tpb.Path) *InitiateOperation {
+	i.req.Component = p
+	return i
+}
+
+// ResultsMethod specifies how the target reports the results.
+func (i *InitiateOperation) ResultsMethod(methods ...otpb.InitiateRequest_ResultsMethod) *InitiateOperation {
+	i.req.ResultsMethod = methods
+	return i
+}
+
+// Label specifies a label for the trace, used to identify stored results.
+func (i *InitiateOperation) Label(label string) *InitiateOperation {
+	i.req.Label = label
+	return i
+}
+
+// AcquisitionTime specifies how long the OTDR acquires samples for, which is
+// rounded to whole seconds.
+func (i *InitiateOperation) AcquisitionTime(d time.Duration) *InitiateOperation {
+	i.req.Configuration.AcquisitionTimeS = uint32(d.Round(time.Second) / time.Second)
+	return i
+}
+
+// PulseWidth specifies the width of the OTDR pulse.
+func (i *InitiateOperation) PulseWidth(d time.Duration) *InitiateOperation {
+	i.req.Configuration.PulseWidthNs = float32(d.Nanoseconds())
+	return i
+}
+
+// WavelengthMHz specifies the wavelength of the OTDR pulse in MHz.
+func (i *InitiateOperation) WavelengthMHz(mhz uint64) *InitiateOperation {
+	i.req.Configuration.WavelengthMhz = mhz
+	return i
+}
+
+// RangeMeters specifies the distance in meters the trace covers.
+func (i *InitiateOperation) RangeMeters(m float32) *InitiateOperation {
+	i.req.Configuration.RangeM = m
+	return i
+}
+
+// FiberType specifies the type of the fiber under test.
+func (i *InitiateOperation) FiberType(ft otpb.FiberTypeProfile) *InitiateOperation {
+	i.req.Configuration.FiberType = ft
+	return i
+}
+
+// SamplingResolutionMeters specifies the distance in meters between samples.
+func (i *InitiateOperation) SamplingResolutionMeters(m float32) *InitiateOperation {
+	i.req.Configuration.SamplingResolutionM = m
+	return i
+}
+
+// DisableAutoNegotiation specifies whether the target must use the given
+// configuration as-is instead of adjusting it.
+func (i *InitiateOperation) DisableAutoNegotiation(disable bool) *InitiateOperation {
+	i.req.Configuration.DisableAutoNegotiation = disable
+	return i
+}
+
+// Execute performs the Initiate operation. The returned Stream reads the
+// progress and results as the target reports them.
+func (i *InitiateOperation) Execute(ctx context.Context, c *internal.Clients) (*Stream, error) {
+	ic, err := c.OTDR().Initiate(ctx, i.req)
+	if err != nil {
+		return nil, err
+	}
+	return &Stream{ic: ic}, nil
+}
+
+// Request returns the request proto of the Initiate operation.
+func (i *InitiateOperation) Request() proto.Message {
+	return i.req
+}
+
+// InitiateError is returned when the target reports an error for the trace.
+type InitiateError struct {
+	Type   otpb.InitiateError_Type
+	Detail string
+}
+
+func (e *InitiateError) Error() string {
+	return fmt.Sprintf("OTDR initiate error %v: %s", e.Type, e.Detail)
+}
+
+// Update is a progress update or the results of an OTDR trace.
+// Results is only set once the trace completes.
+type Update struct {
+	State   otpb.InitiateProgress_State
+	Results *Results
+}
+
+// Results are the results of an OTDR trace. LocalPath is set if the results
+// were saved on the target, Trace if they were sent in the response.
+type Results struct {
+	LocalPath string
+	Trace     *Trace
+}
+
+// Stream is a stream of OTDR trace updates.
+type Stream struct {
+	ic otpb.OTDR_InitiateClient
+}
+
+// Next returns the next update. It returns io.EOF once the target has sent
+// all the updates, and an *InitiateError if the trace failed.
+func (s *Stream) Next() (*Update, error) {
+	resp, err := s.ic.Recv()
+	if err != nil {
+		return nil, err
+	}
+	switch r := resp.GetResponse().(type) {
+	case *otpb.InitiateResponse_Progress:
+		return &Update{State: r.Progress.GetState()}, nil
+	case *otpb.InitiateResponse_Results:
+		res := &Results{LocalPath: r.Results.GetLocalPath()}
+		if t := r.Results.GetOtdrTrace(); t != nil {
+			res.Trace = NewTrace(t)
+		}
+		return &Update{State: otpb.InitiateProgress_COMPLETE, Results: res}, nil
+	case *otpb.InitiateResponse_Error:
+		return nil, &InitiateError{Type: r.Error.GetType(), Detail: r.Error.GetDetail()}
+	default:
+		return nil, fmt.Errorf("unexpected OTDR response %T", r)
+	}
+}
+
+// Updates returns an iterator over the updates. Iteration stops at the end
+// of the stream; any other error is yielded with a nil update.
+func (s *Stream) Updates() iter.Seq2[*Update, error] {
+	return func(yield func(*Update, error) bool) {
+		for {
+			u, err := s.Next()
+			if err == io.EOF {
+				return
+			}
+			if !yield(u, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Wait reads the stream until the target sends the results.
+func (s *Stream) Wait() (*Results, error) {
+	for u, err := range s.Updates() {
+		if err != nil {
+			return nil, err
+		}
+		if u.Results != nil {
+			return u.Results, nil
+		}
+	}
+	return nil, errors.New("OTDR stream ended without results")
+}
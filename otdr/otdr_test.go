@@ -0,0 +1,179 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otdr_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	otpb "github.com/openconfig/gnoi/otdr"
+	tpb "github.com/openconfig/gnoi/types"
+	"github.com/openconfig/gnoigo/internal"
+	"github.com/openconfig/gnoigo/otdr"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+type fakeOTDRClient struct {
+	otpb.OTDRClient
+	gotReq *otpb.InitiateRequest
+	resp   []*otpb.InitiateResponse
+}
+
+func (fc *fakeOTDRClient) Initiate(_ context.Context, in *otpb.InitiateRequest, _ ...grpc.CallOption) (otpb.OTDR_InitiateClient, error) {
+	fc.gotReq = in
+	return &fakeInitiateClient{resp: fc.resp}, nil
+}
+
+type fakeInitiateClient struct {
+	otpb.OTDR_InitiateClient
+	resp []*otpb.InitiateResponse
+}
+
+func (ic *fakeInitiateClient) Recv() (*otpb.InitiateResponse, error) {
+	if len(ic.resp) == 0 {
+		return nil, io.EOF
+	}
+	resp := ic.resp[0]
+	ic.resp = ic.resp[1:]
+	return resp, nil
+}
+
+func progress(s otpb.InitiateProgress_State) *otpb.InitiateResponse {
+	return &otpb.InitiateResponse{Response: &otpb.InitiateResponse_Progress{Progress: &otpb.InitiateProgress{State: s}}}
+}
+
+func TestInitiate(t *testing.T) {
+	trace := &otpb.OTDRTrace{
+		TotalLossDb:  6.5,
+		TotalLengthM: 20000,
+		Events: []*otpb.Event{
+			{DistanceM: 20000, LossDb: 0, ReflectionDb: -14},
+			{DistanceM: 5000, LossDb: 0.5},
+		},
+	}
+	tests := []struct {
+		desc         string
+		resp         []*otpb.InitiateResponse
+		wantStates   []otpb.InitiateProgress_State
+		wantResults  *otdr.Results
+		wantErr      bool
+		wantInitiate bool
+	}{
+		{
+			desc: "trace in response",
+			resp: []*otpb.InitiateResponse{
+				progress(otpb.InitiateProgress_PENDING),
+				progress(otpb.InitiateProgress_RUNNING),
+				{Response: &otpb.InitiateResponse_Results{Results: &otpb.InitiateResults{LocalPath: "/var/otdr/t1.sor", OtdrTrace: trace}}},
+			},
+			wantStates: []otpb.InitiateProgress_State{otpb.InitiateProgress_PENDING, otpb.InitiateProgress_RUNNING, otpb.InitiateProgress_COMPLETE},
+			wantResults: &otdr.Results{
+				LocalPath: "/var/otdr/t1.sor",
+				Trace: &otdr.Trace{
+					TotalLossDB:  6.5,
+					TotalLengthM: 20000,
+					Events: []otdr.Event{
+						{DistanceM: 5000, LossDB: 0.5},
+						{DistanceM: 20000, ReflectionDB: -14},
+					},
+				},
+			},
+		},
+		{
+			desc: "initiate error",
+			resp: []*otpb.InitiateResponse{
+				progress(otpb.InitiateProgress_PENDING),
+				{Response: &otpb.InitiateResponse_Error{Error: &otpb.InitiateError{Type: otpb.InitiateError_ALREADY_IN_PROGRESS, Detail: "busy"}}},
+			},
+			wantStates:   []otpb.InitiateProgress_State{otpb.InitiateProgress_PENDING},
+			wantErr:      true,
+			wantInitiate: true,
+		},
+		{
+			desc:       "no results",
+			resp:       []*otpb.InitiateResponse{progress(otpb.InitiateProgress_RUNNING)},
+			wantStates: []otpb.InitiateProgress_State{otpb.InitiateProgress_RUNNING},
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			op := otdr.NewInitiateOperation().PathFromSubcomponentName("OTDR-1-1").
+				ResultsMethod(otpb.InitiateRequest_RESULTS_IN_RESPONSE, otpb.InitiateRequest_RESULTS_TO_LOCAL_DISK).
+				AcquisitionTime(30 * time.Second).PulseWidth(100 * time.Nanosecond).WavelengthMHz(193100000).
+				RangeMeters(40000).FiberType(otpb.FiberTypeProfile_FTP_SSMF).Label("t1")
+
+			// Read the stream once to check the updates, and once with Wait.
+			for _, wait := range []bool{false, true} {
+				fake := &fakeOTDRClient{resp: tt.resp}
+				stream, err := op.Execute(context.Background(), &internal.Clients{OTDRClient: fake})
+				if err != nil {
+					t.Fatalf("Execute() failed: %v", err)
+				}
+				wantReq := &otpb.InitiateRequest{
+					Component: &tpb.Path{
+						Origin: "openconfig",
+						Elem: []*tpb.PathElem{
+							{Name: "components"},
+							{Name: "component", Key: map[string]string{"name": "OTDR-1-1"}},
+						},
+					},
+					ResultsMethod: []otpb.InitiateRequest_ResultsMethod{otpb.InitiateRequest_RESULTS_IN_RESPONSE, otpb.InitiateRequest_RESULTS_TO_LOCAL_DISK},
+					Configuration: &otpb.OTDRConfiguration{
+						AcquisitionTimeS: 30,
+						PulseWidthNs:     100,
+						WavelengthMhz:    193100000,
+						RangeM:           40000,
+						FiberType:        otpb.FiberTypeProfile_FTP_SSMF,
+					},
+					Label: "t1",
+				}
+				if diff := cmp.Diff(wantReq, fake.gotReq, protocmp.Transform()); diff != "" {
+					t.Errorf("Execute() sent unexpected request diff (-want +got): %s", diff)
+				}
+
+				if wait {
+					got, err := stream.Wait()
+					if (err != nil) != tt.wantErr {
+						t.Fatalf("Wait() got error %v, want error %v", err, tt.wantErr)
+					}
+					var ie *otdr.InitiateError
+					if errors.As(err, &ie) != tt.wantInitiate {
+						t.Errorf("Wait() got error %v, want InitiateError %v", err, tt.wantInitiate)
+					}
+					if diff := cmp.Diff(tt.wantResults, got); diff != "" {
+						t.Errorf("Wait() got unexpected results diff (-want +got): %s", diff)
+					}
+					continue
+				}
+				var gotStates []otpb.InitiateProgress_State
+				for u, err := range stream.Updates() {
+					if err != nil {
+						break
+					}
+					gotStates = append(gotStates, u.State)
+				}
+				if diff := cmp.Diff(tt.wantStates, gotStates); diff != "" {
+					t.Errorf("Updates() got unexpected states diff (-want +got): %s", diff)
+				}
+			}
+		})
+	}
+}
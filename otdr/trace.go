@@ -0,0 +1,150 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otdr
+
+import (
+	"math"
+	"sort"
+
+	otpb "github.com/openconfig/gnoi/otdr"
+)
+
+// Event is an event detected along the fiber.
+type Event struct {
+	// DistanceM is the distance of the event from the OTDR in meters.
+	DistanceM float64
+	// LossDB is the loss at the event in dB.
+	LossDB float64
+	// ReflectionDB is the reflection at the event in dB, 0 if the event is
+	// not reflective.
+	ReflectionDB float64
+}
+
+// Reflective reports whether the event reflects light, such as a connector
+// or the end of the fiber.
+func (e Event) Reflective() bool {
+	return e.ReflectionDB != 0
+}
+
+// Trace is an OTDR trace with its events ordered by distance.
+type Trace struct {
+	TotalLossDB         float64
+	TotalLengthM        float64
+	OpticalReturnLossDB float64
+	AverageLossDBPerKm  float64
+	FiberType           otpb.FiberTypeProfile
+	Events              []Event
+}
+
+// NewTrace converts an OTDR trace proto to a Trace.
+func NewTrace(t *otpb.OTDRTrace) *Trace {
+	tr := &Trace{
+		TotalLossDB:         float64(t.GetTotalLossDb()),
+		TotalLengthM:        float64(t.GetTotalLengthM()),
+		OpticalReturnLossDB: float64(t.GetOpticalReturnLossDb()),
+		AverageLossDBPerKm:  float64(t.GetAverageLossDbKm()),
+		FiberType:           t.GetDiscoveredFiberType(),
+	}
+	for _, e := range t.GetEvents() {
+		tr.Events = append(tr.Events, Event{
+			DistanceM:    float64(e.GetDistanceM()),
+			LossDB:       float64(e.GetLossDb()),
+			ReflectionDB: float64(e.GetReflectionDb()),
+		})
+	}
+	sort.SliceStable(tr.Events, func(i, j int) bool { return tr.Events[i].DistanceM < tr.Events[j].DistanceM })
+	return tr
+}
+
+// Reflections returns the reflective events.
+func (t *Trace) Reflections() []Event {
+	var events []Event
+	for _, e := range t.Events {
+		if e.Reflective() {
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+// LossesAbove returns the events with a loss greater than db.
+func (t *Trace) LossesAbove(db float64) []Event {
+	var events []Event
+	for _, e := range t.Events {
+		if e.LossDB > db {
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+// EventDelta is the difference of an event between two traces. Before is
+// nil for a new event and After is nil for an event that disappeared.
+type EventDelta struct {
+	Before *Event
+	After  *Event
+}
+
+// LossDeltaDB returns the change of loss of the event.
+func (d EventDelta) LossDeltaDB() float64 {
+	var before, after float64
+	if d.Before != nil {
+		before = d.Before.LossDB
+	}
+	if d.After != nil {
+		after = d.After.LossDB
+	}
+	return after - before
+}
+
+// Compare matches the events of t with those of the baseline trace that lie
+// within toleranceM meters and returns the deltas ordered by distance.
+func (t *Trace) Compare(baseline *Trace, toleranceM float64) []EventDelta {
+	var deltas []EventDelta
+	matched := make([]bool, len(t.Events))
+	for i := range baseline.Events {
+		before := &baseline.Events[i]
+		best := -1
+		for j := range t.Events {
+			if matched[j] {
+				continue
+			}
+			d := math.Abs(t.Events[j].DistanceM - before.DistanceM)
+			if d <= toleranceM && (best < 0 || d < math.Abs(t.Events[best].DistanceM-before.DistanceM)) {
+				best = j
+			}
+		}
+		if best < 0 {
+			deltas = append(deltas, EventDelta{Before: before})
+			continue
+		}
+		matched[best] = true
+		deltas = append(deltas, EventDelta{Before: before, After: &t.Events[best]})
+	}
+	for j := range t.Events {
+		if !matched[j] {
+			deltas = append(deltas, EventDelta{After: &t.Events[j]})
+		}
+	}
+	sort.SliceStable(deltas, func(i, j int) bool { return deltas[i].distance() < deltas[j].distance() })
+	return deltas
+}
+
+func (d EventDelta) distance() float64 {
+	if d.After != nil {
+		return d.After.DistanceM
+	}
+	return d.Before.DistanceM
+}
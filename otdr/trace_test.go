@@ -0,0 +1,64 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otdr_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/openconfig/gnoigo/otdr"
+)
+
+var baseline = &otdr.Trace{Events: []otdr.Event{
+	{DistanceM: 10, LossDB: 0.3, ReflectionDB: -45},
+	{DistanceM: 5000, LossDB: 0.1},
+	{DistanceM: 12000, LossDB: 0.2},
+	{DistanceM: 20000, ReflectionDB: -14},
+}}
+
+func TestTraceEvents(t *testing.T) {
+	if diff := cmp.Diff([]otdr.Event{baseline.Events[0], baseline.Events[3]}, baseline.Reflections()); diff != "" {
+		t.Errorf("Reflections() got unexpected events diff (-want +got): %s", diff)
+	}
+	if diff := cmp.Diff([]otdr.Event{baseline.Events[0], baseline.Events[2]}, baseline.LossesAbove(0.15)); diff != "" {
+		t.Errorf("LossesAbove() got unexpected events diff (-want +got): %s", diff)
+	}
+}
+
+func TestTraceCompare(t *testing.T) {
+	current := &otdr.Trace{Events: []otdr.Event{
+		{DistanceM: 12, LossDB: 0.3, ReflectionDB: -45},
+		{DistanceM: 5003, LossDB: 1.6},
+		{DistanceM: 8000, LossDB: 0.4},
+		{DistanceM: 20001, ReflectionDB: -14},
+	}}
+	want := []otdr.EventDelta{
+		{Before: &baseline.Events[0], After: &current.Events[0]},
+		{Before: &baseline.Events[1], After: &current.Events[1]},
+		{After: &current.Events[2]},
+		{Before: &baseline.Events[2]},
+		{Before: &baseline.Events[3], After: &current.Events[3]},
+	}
+	got := current.Compare(baseline, 5)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("Compare() got unexpected deltas diff (-want +got): %s", diff)
+	}
+	if d := got[1].LossDeltaDB(); d < 1.49 || d > 1.51 {
+		t.Errorf("LossDeltaDB() got %v, want 1.5", d)
+	}
+	if d := got[3].LossDeltaDB(); d != -0.2 {
+		t.Errorf("LossDeltaDB() of removed event got %v, want -0.2", d)
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gnoigo
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/openconfig/gnoigo/internal"
+)
+
+// Risk classifies how disruptive an operation is to the target. Operations
+// declare their risk class with a Risk method; operations without one are
+// RiskLow.
+type Risk = internal.Risk
+
+const (
+	// RiskLow means the operation does not interrupt the target.
+	RiskLow = internal.RiskLow
+	// RiskDisruptive means the operation interrupts the service of the target.
+	RiskDisruptive = internal.RiskDisruptive
+	// RiskDestructive means the operation erases state from the target.
+	RiskDestructive = internal.RiskDestructive
+)
+
+// RiskOf returns the risk class declared by op.
+func RiskOf(op any) Risk {
+	if r, ok := op.(interface{ Risk() Risk }); ok {
+		return r.Risk()
+	}
+	return RiskLow
+}
+
+// OperationName returns the name of the type of op, e.g.
+// "system.RebootOperation".
+func OperationName(op any) string {
+	return strings.TrimPrefix(fmt.Sprintf("%T", op), "*")
+}
+
+// Target describes the device an operation is executed against.
+type Target struct {
+	Name   string
+	Labels map[string]string
+}
+
+// Check describes an operation about to be executed, as evaluated by a Policy.
+type Check struct {
+	Target    Target
+	Operation string
+	Risk      Risk
+}
+
+// PolicyDenied is the error returned when a Policy denies an operation.
+type PolicyDenied struct {
+	Check
+	Reason string
+}
+
+func (e *PolicyDenied) Error() string {
+	return fmt.Sprintf("policy denied %s operation %s on target %q: %s", e.Risk, e.Operation, e.Target.Name, e.Reason)
+}
+
+// Rule evaluates a Check and returns an error, typically a *PolicyDenied,
+// if the operation must not be executed.
+type Rule func(context.Context, *Check) error
+
+// Policy is a set of rules evaluated before an operation is executed.
+type Policy struct {
+	rules []Rule
+}
+
+// NewPolicy creates a Policy that allows an operation only if all rules
+// allow it.
+func NewPolicy(rules ...Rule) *Policy {
+	return &Policy{rules: rules}
+}
+
+// Evaluate returns the error of the first rule that denies the check.
+func (p *Policy) Evaluate(ctx context.Context, c *Check) error {
+	for _, r := range p.rules {
+		if err := r(ctx, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Policy) middleware(next Handler) Handler {
+	return func(ctx context.Context, c *Call) (any, error) {
+		if err := p.Evaluate(ctx, &Check{Target: c.Target, Operation: c.Name, Risk: c.Risk}); err != nil {
+			return nil, err
+		}
+		return next(ctx, c)
+	}
+}
+
+// DenyLabel returns a Rule that denies operations of at least the given risk
+// on targets labelled key=value, e.g. DenyLabel("env", "production",
+// RiskDestructive).
+func DenyLabel(key, value string, risk Risk) Rule {
+	return func(_ context.Context, c *Check) error {
+		if c.Risk >= risk && c.Target.Labels[key] == value {
+			return &PolicyDenied{Check: *c, Reason: fmt.Sprintf("target is labelled %s=%s", key, value)}
+		}
+		return nil
+	}
+}
+
+// Confirm returns a Rule that calls confirm for operations of at least the
+// given risk and denies them unless confirm returns true.
+func Confirm(risk Risk, confirm func(context.Context, *Check) bool) Rule {
+	return func(ctx context.Context, c *Check) error {
+		if c.Risk >= risk && !confirm(ctx, c) {
+			return &PolicyDenied{Check: *c, Reason: "not confirmed"}
+		}
+		return nil
+	}
+}
+
+// Allowlist returns a Rule that restricts the operations above RiskLow to
+// those listed for the environment of the target, where the environment is
+// the value of the key label. allowed maps environments to operation names
+// as returned by OperationName. Targets whose environment is not in allowed
+// are not restricted.
+func Allowlist(key string, allowed map[string][]string) Rule {
+	return func(_ context.Context, c *Check) error {
+		if c.Risk == RiskLow {
+			return nil
+		}
+		env := c.Target.Labels[key]
+		ops, ok := allowed[env]
+		if ok && !slices.Contains(ops, c.Operation) {
+			return &PolicyDenied{Check: *c, Reason: fmt.Sprintf("operation is not allowed in environment %q", env)}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,247 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gnoigo_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	bpb "github.com/openconfig/gnoi/bgp"
+	cmpb "github.com/openconfig/gnoi/cert"
+	cpb "github.com/openconfig/gnoi/containerz"
+	frpb "github.com/openconfig/gnoi/factory_reset"
+	mpb "github.com/openconfig/gnoi/mpls"
+	spb "github.com/openconfig/gnoi/system"
+	"github.com/openconfig/gnoigo"
+	"github.com/openconfig/gnoigo/bgp"
+	"github.com/openconfig/gnoigo/cert"
+	"github.com/openconfig/gnoigo/containerz"
+	"github.com/openconfig/gnoigo/diag"
+	"github.com/openconfig/gnoigo/factoryreset"
+	"github.com/openconfig/gnoigo/internal"
+	"github.com/openconfig/gnoigo/layer2"
+	"github.com/openconfig/gnoigo/linkqual"
+	"github.com/openconfig/gnoigo/mpls"
+	"github.com/openconfig/gnoigo/os"
+	"github.com/openconfig/gnoigo/system"
+	"github.com/openconfig/gnoigo/wavelengthrouter"
+	"google.golang.org/grpc"
+)
+
+type fakeSystemClient struct {
+	spb.SystemClient
+	calls int
+}
+
+func (fc *fakeSystemClient) Reboot(context.Context, *spb.RebootRequest, ...grpc.CallOption) (*spb.RebootResponse, error) {
+	fc.calls++
+	return &spb.RebootResponse{}, nil
+}
+
+func (fc *fakeSystemClient) Time(context.Context, *spb.TimeRequest, ...grpc.CallOption) (*spb.TimeResponse, error) {
+	fc.calls++
+	return &spb.TimeResponse{}, nil
+}
+
+type fakeFactoryResetClient struct {
+	frpb.FactoryResetClient
+	calls int
+}
+
+func (fc *fakeFactoryResetClient) Start(context.Context, *frpb.StartRequest, ...grpc.CallOption) (*frpb.StartResponse, error) {
+	fc.calls++
+	return &frpb.StartResponse{}, nil
+}
+
+type fakeContainerzClient struct {
+	cpb.ContainerzClient
+	calls int
+}
+
+func (fc *fakeContainerzClient) RemoveContainer(context.Context, *cpb.RemoveContainerRequest, ...grpc.CallOption) (*cpb.RemoveContainerResponse, error) {
+	fc.calls++
+	return &cpb.RemoveContainerResponse{}, nil
+}
+
+type fakeCertClient struct {
+	cmpb.CertificateManagementClient
+	calls int
+}
+
+func (fc *fakeCertClient) RevokeCertificates(context.Context, *cmpb.RevokeCertificatesRequest, ...grpc.CallOption) (*cmpb.RevokeCertificatesResponse, error) {
+	fc.calls++
+	return &cmpb.RevokeCertificatesResponse{}, nil
+}
+
+func TestRiskOf(t *testing.T) {
+	tests := []struct {
+		desc string
+		op   any
+		want gnoigo.Risk
+	}{
+		{desc: "time", op: system.NewTimeOperation(), want: gnoigo.RiskLow},
+		{desc: "reboot", op: system.NewRebootOperation(), want: gnoigo.RiskDisruptive},
+		{desc: "kill process", op: system.NewKillProcessOperation(), want: gnoigo.RiskDisruptive},
+		{desc: "activate", op: os.NewActivateOperation(), want: gnoigo.RiskDisruptive},
+		{desc: "activate without reboot", op: os.NewActivateOperation().NoReboot(true), want: gnoigo.RiskLow},
+		{desc: "factory reset", op: factoryreset.NewStartOperation(), want: gnoigo.RiskDestructive},
+		{desc: "reprovision", op: factoryreset.NewReprovisionOperation(), want: gnoigo.RiskDestructive},
+		{desc: "remove container", op: containerz.NewRemoveContainerOperation(), want: gnoigo.RiskDestructive},
+		{desc: "remove image", op: containerz.NewRemoveImageOperation(), want: gnoigo.RiskDestructive},
+		{desc: "remove volume", op: containerz.NewRemoveVolumeOperation(), want: gnoigo.RiskDestructive},
+		{desc: "remove plugin", op: containerz.NewRemovePluginOperation(), want: gnoigo.RiskDestructive},
+		{desc: "stop container", op: containerz.NewStopContainerOperation(), want: gnoigo.RiskDisruptive},
+		{desc: "update container", op: containerz.NewUpdateContainerOperation(), want: gnoigo.RiskDisruptive},
+		{desc: "revoke certificates", op: cert.NewRevokeCertificatesOperation(), want: gnoigo.RiskDestructive},
+		{desc: "rotate certificate", op: cert.NewRotateOperation(), want: gnoigo.RiskDisruptive},
+		{desc: "install certificate", op: cert.NewInstallOperation(), want: gnoigo.RiskDisruptive},
+		{desc: "soft clear BGP neighbor", op: bgp.NewClearNeighborOperation(), want: gnoigo.RiskLow},
+		{desc: "hard reset BGP neighbor", op: bgp.NewClearNeighborOperation().Mode(bpb.ClearBGPNeighborRequest_HARD_RESET), want: gnoigo.RiskDisruptive},
+		{desc: "hard reset BGP neighbors", op: bgp.NewClearNeighborsOperation().Mode(bpb.ClearBGPNeighborRequest_HARD_RESET), want: gnoigo.RiskDisruptive},
+		{desc: "reoptimize LSP", op: mpls.NewClearLSPOperation(), want: gnoigo.RiskLow},
+		{desc: "reset LSP", op: mpls.NewClearLSPOperation().Mode(mpb.ClearLSPRequest_RESET), want: gnoigo.RiskDisruptive},
+		{desc: "start BERT", op: diag.NewStartBERTOperation(), want: gnoigo.RiskDisruptive},
+		{desc: "run BERT", op: diag.NewRunBERTOperation(), want: gnoigo.RiskDisruptive},
+		{desc: "reconcile container", op: containerz.NewReconcileOperation(), want: gnoigo.RiskDisruptive},
+		{desc: "reconcile container dry run", op: containerz.NewReconcileOperation().DryRun(true), want: gnoigo.RiskLow},
+		{desc: "clear spanning tree", op: layer2.NewClearSpanningTreeOperation(), want: gnoigo.RiskDisruptive},
+		{desc: "perform layer2 BERT", op: layer2.NewPerformBERTOperation(), want: gnoigo.RiskDisruptive},
+		{desc: "adjust spectrum", op: wavelengthrouter.NewAdjustSpectrumOperation(), want: gnoigo.RiskDisruptive},
+		{desc: "adjust PSD", op: wavelengthrouter.NewAdjustPSDOperation(), want: gnoigo.RiskDisruptive},
+		{desc: "create link qualification", op: linkqual.NewCreateOperation(), want: gnoigo.RiskDisruptive},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			if got := gnoigo.RiskOf(tt.op); got != tt.want {
+				t.Errorf("RiskOf() got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecutePolicy(t *testing.T) {
+	production := gnoigo.Target{Name: "dut1", Labels: map[string]string{"env": "production"}}
+	lab := gnoigo.Target{Name: "dut2", Labels: map[string]string{"env": "lab"}}
+	staging := gnoigo.Target{Name: "dut3", Labels: map[string]string{"env": "staging"}}
+	var confirmed []string
+	policy := gnoigo.NewPolicy(
+		gnoigo.DenyLabel("env", "production", gnoigo.RiskDestructive),
+		gnoigo.Allowlist("env", map[string][]string{"staging": {"system.RebootOperation"}}),
+		gnoigo.Confirm(gnoigo.RiskDisruptive, func(_ context.Context, c *gnoigo.Check) bool {
+			confirmed = append(confirmed, c.Operation)
+			return c.Target.Name != "dut1"
+		}),
+	)
+
+	tests := []struct {
+		desc          string
+		target        gnoigo.Target
+		factoryReset  bool
+		wantDenied    bool
+		wantConfirmed []string
+	}{
+		{
+			desc:         "factory reset on production",
+			target:       production,
+			factoryReset: true,
+			wantDenied:   true,
+		},
+		{
+			desc:          "factory reset in lab",
+			target:        lab,
+			factoryReset:  true,
+			wantConfirmed: []string{"factoryreset.StartOperation"},
+		},
+		{
+			desc:          "reboot not confirmed",
+			target:        production,
+			wantDenied:    true,
+			wantConfirmed: []string{"system.RebootOperation"},
+		},
+		{
+			desc:          "reboot allowed in staging",
+			target:        staging,
+			wantConfirmed: []string{"system.RebootOperation"},
+		},
+		{
+			desc:         "factory reset not allowed in staging",
+			target:       staging,
+			factoryReset: true,
+			wantDenied:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			confirmed = nil
+			sc, frc := &fakeSystemClient{}, &fakeFactoryResetClient{}
+			clients := &internal.Clients{SystemClient: sc, FactoryResetClient: frc}
+			opts := []gnoigo.ExecuteOption{gnoigo.WithTarget(tt.target), gnoigo.WithPolicy(policy)}
+			var err error
+			if tt.factoryReset {
+				_, err = gnoigo.Execute(context.Background(), clients, factoryreset.NewStartOperation(), opts...)
+			} else {
+				_, err = gnoigo.Execute(context.Background(), clients, system.NewRebootOperation(), opts...)
+			}
+			var denied *gnoigo.PolicyDenied
+			if gotDenied := errors.As(err, &denied); gotDenied != tt.wantDenied {
+				t.Fatalf("Execute() got error %v, want denied %v", err, tt.wantDenied)
+			}
+			if calls := sc.calls + frc.calls; (calls == 0) != tt.wantDenied {
+				t.Errorf("Execute() sent %d RPCs, want denied %v", calls, tt.wantDenied)
+			}
+			if denied != nil && denied.Target.Name != tt.target.Name {
+				t.Errorf("Execute() denied target %q, want %q", denied.Target.Name, tt.target.Name)
+			}
+			if len(confirmed) != len(tt.wantConfirmed) || (len(confirmed) > 0 && confirmed[0] != tt.wantConfirmed[0]) {
+				t.Errorf("Execute() confirmed %v, want %v", confirmed, tt.wantConfirmed)
+			}
+		})
+	}
+}
+
+func TestExecuteLowRisk(t *testing.T) {
+	sc := &fakeSystemClient{}
+	policy := gnoigo.NewPolicy(
+		gnoigo.DenyLabel("env", "production", gnoigo.RiskDisruptive),
+		gnoigo.Confirm(gnoigo.RiskDisruptive, func(context.Context, *gnoigo.Check) bool { return false }),
+	)
+	target := gnoigo.Target{Name: "dut1", Labels: map[string]string{"env": "production"}}
+	if _, err := gnoigo.Execute(context.Background(), &internal.Clients{SystemClient: sc}, system.NewTimeOperation(), gnoigo.WithTarget(target), gnoigo.WithPolicy(policy)); err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+	if sc.calls != 1 {
+		t.Errorf("Execute() sent %d RPCs, want 1", sc.calls)
+	}
+}
+
+func TestExecutePolicyRemovals(t *testing.T) {
+	policy := gnoigo.NewPolicy(gnoigo.DenyLabel("env", "production", gnoigo.RiskDisruptive))
+	target := gnoigo.Target{Name: "dut1", Labels: map[string]string{"env": "production"}}
+	opts := []gnoigo.ExecuteOption{gnoigo.WithTarget(target), gnoigo.WithPolicy(policy)}
+	cc, cmc := &fakeContainerzClient{}, &fakeCertClient{}
+	clients := &internal.Clients{ContainerzClient: cc, CertMgmtClient: cmc}
+
+	var denied *gnoigo.PolicyDenied
+	if _, err := gnoigo.Execute(context.Background(), clients, containerz.NewRemoveContainerOperation().Name("c1"), opts...); !errors.As(err, &denied) {
+		t.Errorf("Execute(RemoveContainer) got error %v, want denied", err)
+	}
+	if _, err := gnoigo.Execute(context.Background(), clients, cert.NewRevokeCertificatesOperation().CertificateIDs("cert1"), opts...); !errors.As(err, &denied) {
+		t.Errorf("Execute(RevokeCertificates) got error %v, want denied", err)
+	}
+	if calls := cc.calls + cmc.calls; calls != 0 {
+		t.Errorf("Execute() sent %d RPCs, want 0", calls)
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gnoigo
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"time"
+
+	log "github.com/golang/glog"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// IsIdempotent reports whether op declares, with an Idempotent method, that
+// it can safely be retried. Operations that do not declare it are never
+// retried.
+func IsIdempotent(op any) bool {
+	i, ok := op.(interface{ Idempotent() bool })
+	return ok && i.Idempotent()
+}
+
+// RetryPolicy represents the parameters of retries of idempotent operations.
+type RetryPolicy struct {
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	multiplier     float64
+	jitter         float64
+	timeout        time.Duration
+	codes          []codes.Code
+}
+
+// NewRetryPolicy creates a RetryPolicy that makes up to 5 attempts, retries
+// on Unavailable, and backs off exponentially from 1s up to 30s with 20%
+// jitter.
+func NewRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		maxAttempts:    5,
+		initialBackoff: time.Second,
+		maxBackoff:     30 * time.Second,
+		multiplier:     2,
+		jitter:         0.2,
+		codes:          []codes.Code{codes.Unavailable},
+	}
+}
+
+// MaxAttempts specifies the maximum number of attempts, including the first.
+func (r *RetryPolicy) MaxAttempts(n int) *RetryPolicy {
+	r.maxAttempts = n
+	return r
+}
+
+// Backoff specifies the delay before the first retry, which is multiplied by
+// multiplier after each retry up to maxDelay.
+func (r *RetryPolicy) Backoff(initial, maxDelay time.Duration, multiplier float64) *RetryPolicy {
+	r.initialBackoff = initial
+	r.maxBackoff = maxDelay
+	r.multiplier = multiplier
+	return r
+}
+
+// Jitter specifies the fraction, between 0 and 1, by which each delay is
+// randomly increased or decreased.
+func (r *RetryPolicy) Jitter(jitter float64) *RetryPolicy {
+	r.jitter = jitter
+	return r
+}
+
+// Timeout specifies the total time allowed for all attempts. Zero means the
+// attempts are only bounded by the context.
+func (r *RetryPolicy) Timeout(timeout time.Duration) *RetryPolicy {
+	r.timeout = timeout
+	return r
+}
+
+// RetryOn specifies the status codes of the errors that are retried.
+func (r *RetryPolicy) RetryOn(codes ...codes.Code) *RetryPolicy {
+	r.codes = codes
+	return r
+}
+
+// retryable reports whether err is classified as transient.
+func (r *RetryPolicy) retryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	return slices.Contains(r.codes, status.Code(err))
+}
+
+// backoff returns the delay before the given retry, starting at 1.
+func (r *RetryPolicy) backoff(retry int) time.Duration {
+	d := float64(r.initialBackoff)
+	for i := 1; i < retry && d < float64(r.maxBackoff); i++ {
+		d *= r.multiplier
+	}
+	d = min(d, float64(r.maxBackoff))
+	d *= 1 + r.jitter*(2*rand.Float64()-1)
+	return time.Duration(d)
+}
+
+// Retry returns a Middleware that retries idempotent operations whose errors
+// are classified as transient by p. Other operations are executed once.
+func Retry(p *RetryPolicy) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, c *Call) (any, error) {
+			if !IsIdempotent(c.Operation) {
+				return next(ctx, c)
+			}
+			if p.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, p.timeout)
+				defer cancel()
+			}
+			for attempt := 1; ; attempt++ {
+				res, err := next(ctx, c)
+				if err == nil || attempt >= p.maxAttempts || !p.retryable(err) {
+					return res, err
+				}
+				wait := p.backoff(attempt)
+				if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+					return res, err
+				}
+				log.Infof("%s on target %q failed on attempt %d, retrying in %v: %v", c.Name, c.Target.Name, attempt, wait, err)
+				select {
+				case <-ctx.Done():
+					return res, err
+				case <-time.After(wait):
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gnoigo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	spb "github.com/openconfig/gnoi/system"
+	"github.com/openconfig/gnoigo"
+	"github.com/openconfig/gnoigo/factoryreset"
+	"github.com/openconfig/gnoigo/internal"
+	"github.com/openconfig/gnoigo/os"
+	"github.com/openconfig/gnoigo/system"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// flakySystemClient fails the first failures Time and Reboot calls with code.
+type flakySystemClient struct {
+	spb.SystemClient
+	failures int
+	code     codes.Code
+	calls    int
+}
+
+func (fc *flakySystemClient) call() error {
+	fc.calls++
+	if fc.calls <= fc.failures {
+		return status.Error(fc.code, "device restarting")
+	}
+	return nil
+}
+
+func (fc *flakySystemClient) Time(context.Context, *spb.TimeRequest, ...grpc.CallOption) (*spb.TimeResponse, error) {
+	if err := fc.call(); err != nil {
+		return nil, err
+	}
+	return &spb.TimeResponse{Time: 1}, nil
+}
+
+func (fc *flakySystemClient) Reboot(context.Context, *spb.RebootRequest, ...grpc.CallOption) (*spb.RebootResponse, error) {
+	if err := fc.call(); err != nil {
+		return nil, err
+	}
+	return &spb.RebootResponse{}, nil
+}
+
+func TestIsIdempotent(t *testing.T) {
+	tests := []struct {
+		desc string
+		op   any
+		want bool
+	}{
+		{desc: "time", op: system.NewTimeOperation(), want: true},
+		{desc: "reboot status", op: system.NewRebootStatusOperation(), want: true},
+		{desc: "verify", op: os.NewVerifyOperation(), want: true},
+		{desc: "reboot", op: system.NewRebootOperation()},
+		{desc: "factory reset", op: factoryreset.NewStartOperation()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			if got := gnoigo.IsIdempotent(tt.op); got != tt.want {
+				t.Errorf("IsIdempotent() got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetry(t *testing.T) {
+	policy := gnoigo.NewRetryPolicy().MaxAttempts(3).Backoff(time.Millisecond, 5*time.Millisecond, 2)
+	tests := []struct {
+		desc      string
+		reboot    bool
+		failures  int
+		code      codes.Code
+		wantCalls int
+		wantCode  codes.Code
+	}{
+		{
+			desc:      "recovers after restart",
+			failures:  2,
+			code:      codes.Unavailable,
+			wantCalls: 3,
+		},
+		{
+			desc:      "max attempts",
+			failures:  5,
+			code:      codes.Unavailable,
+			wantCalls: 3,
+			wantCode:  codes.Unavailable,
+		},
+		{
+			desc:      "non-retryable code",
+			failures:  1,
+			code:      codes.InvalidArgument,
+			wantCalls: 1,
+			wantCode:  codes.InvalidArgument,
+		},
+		{
+			desc:      "non-idempotent operation",
+			reboot:    true,
+			failures:  1,
+			code:      codes.Unavailable,
+			wantCalls: 1,
+			wantCode:  codes.Unavailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			sc := &flakySystemClient{failures: tt.failures, code: tt.code}
+			exec := gnoigo.NewExecutor(&internal.Clients{SystemClient: sc}, gnoigo.WithMiddleware(gnoigo.Retry(policy)))
+			var err error
+			if tt.reboot {
+				_, err = gnoigo.Execute(context.Background(), exec, system.NewRebootOperation())
+			} else {
+				var resp *spb.TimeResponse
+				resp, err = gnoigo.Execute(context.Background(), exec, system.NewTimeOperation())
+				if err == nil && resp.GetTime() != 1 {
+					t.Errorf("Execute() got response %v, want time 1", resp)
+				}
+			}
+			if got := status.Code(err); got != tt.wantCode {
+				t.Errorf("Execute() got error %v, want code %v", err, tt.wantCode)
+			}
+			if sc.calls != tt.wantCalls {
+				t.Errorf("Execute() made %d calls, want %d", sc.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetryTimeout(t *testing.T) {
+	sc := &flakySystemClient{failures: 1000, code: codes.Unavailable}
+	policy := gnoigo.NewRetryPolicy().MaxAttempts(1000).Backoff(10*time.Millisecond, 10*time.Millisecond, 1).Jitter(0).Timeout(55 * time.Millisecond)
+	start := time.Now()
+	_, err := gnoigo.Execute(context.Background(), &internal.Clients{SystemClient: sc}, system.NewTimeOperation(), gnoigo.WithMiddleware(gnoigo.Retry(policy)))
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("Execute() got error %v, want code %v", err, codes.Unavailable)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Execute() took %v, want at most the retry timeout", elapsed)
+	}
+	if sc.calls < 2 || sc.calls > 6 {
+		t.Errorf("Execute() made %d calls, want between 2 and 6", sc.calls)
+	}
+}
@@ -0,0 +1,380 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package rollout provides staged execution of gNOI operations across many
+// targets, in waves that halt when too many targets fail.
+package rollout
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	log "github.com/golang/glog"
+	"github.com/openconfig/gnoigo"
+)
+
+// ErrHalted is returned when a rollout halts, either because too many targets
+// of a wave failed or because the context was done.
+var ErrHalted = errors.New("rollout halted")
+
+// Target is a target of a rollout.
+type Target struct {
+	Name    string
+	Labels  map[string]string
+	Clients gnoigo.Clients
+}
+
+// Wave is a stage of a rollout. The size of the wave is Count targets or, if
+// Count is zero, Percent percent of all targets rounded up. A wave with
+// neither includes all remaining targets, and so does the last wave.
+type Wave struct {
+	Name    string
+	Count   int
+	Percent float64
+}
+
+// DefaultWaves returns the waves of a rollout to one canary target, then 5%
+// and 25% of the targets, then the rest.
+func DefaultWaves() []Wave {
+	return []Wave{
+		{Name: "canary", Count: 1},
+		{Name: "5%", Percent: 5},
+		{Name: "25%", Percent: 25},
+		{Name: "rest"},
+	}
+}
+
+// State is the progress of a rollout, persisted to the state file.
+type State struct {
+	Waves  []*WaveState `json:"waves"`
+	Halted bool         `json:"halted,omitempty"`
+	Reason string       `json:"reason,omitempty"`
+}
+
+// WaveState is the progress of a single wave.
+type WaveState struct {
+	Name     string              `json:"name"`
+	Targets  []string            `json:"targets"`
+	Done     bool                `json:"done,omitempty"`
+	Outcomes map[string]*Outcome `json:"outcomes,omitempty"`
+}
+
+// Outcome is the outcome of the rollout on a single target.
+type Outcome struct {
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+	Finished time.Time     `json:"finished"`
+}
+
+// Failed reports whether the operation or the post-check failed.
+func (o *Outcome) Failed() bool {
+	return o.Error != ""
+}
+
+// Rollout represents the parameters of a staged execution of an operation
+// across targets.
+type Rollout[T any] struct {
+	targets        []Target
+	factory        func(Target) gnoigo.Operation[T]
+	waves          []Wave
+	postCheck      func(context.Context, Target, T) error
+	maxFailureRate float64
+	pause          time.Duration
+	concurrency    int
+	stateFile      string
+	opts           []gnoigo.ExecuteOption
+}
+
+// NewRollout creates a Rollout that executes the operation returned by
+// factory on each target, in the default waves. The targets are assigned to
+// waves in the given order, so the first target is the canary.
+func NewRollout[T any](targets []Target, factory func(Target) gnoigo.Operation[T]) *Rollout[T] {
+	return &Rollout[T]{
+		targets: targets,
+		factory: factory,
+		waves:   DefaultWaves(),
+	}
+}
+
+// Waves specifies the waves of the rollout.
+func (r *Rollout[T]) Waves(waves ...Wave) *Rollout[T] {
+	r.waves = waves
+	return r
+}
+
+// PostCheck specifies a check run on a target after the operation succeeded
+// on it. An error of the check fails the target.
+func (r *Rollout[T]) PostCheck(check func(context.Context, Target, T) error) *Rollout[T] {
+	r.postCheck = check
+	return r
+}
+
+// MaxFailureRate specifies the fraction of the targets of a wave, between 0
+// and 1, that may fail without halting the rollout.
+func (r *Rollout[T]) MaxFailureRate(rate float64) *Rollout[T] {
+	r.maxFailureRate = rate
+	return r
+}
+
+// Pause specifies the time to wait between waves.
+func (r *Rollout[T]) Pause(pause time.Duration) *Rollout[T] {
+	r.pause = pause
+	return r
+}
+
+// Concurrency specifies the maximum number of targets of a wave the
+// operation is executed against at the same time. The default is that of
+// gnoigo.Fleet.
+func (r *Rollout[T]) Concurrency(n int) *Rollout[T] {
+	r.concurrency = n
+	return r
+}
+
+// StateFile specifies the file the progress of the rollout is persisted to.
+// If the file exists, the rollout resumes from it: waves that are done and
+// targets that succeeded are skipped, and failed targets are retried. A
+// resumed rollout must have the same targets as the one that created the file.
+func (r *Rollout[T]) StateFile(path string) *Rollout[T] {
+	r.stateFile = path
+	return r
+}
+
+// Options specifies the options used to execute the operation on every
+// target. The target name and labels are set with gnoigo.WithTarget.
+func (r *Rollout[T]) Options(opts ...gnoigo.ExecuteOption) *Rollout[T] {
+	r.opts = opts
+	return r
+}
+
+// Run executes the rollout wave by wave and returns its state. If the
+// rollout halts, the returned error wraps ErrHalted and the rollout can be
+// resumed by running it again with the same state file.
+func (r *Rollout[T]) Run(ctx context.Context) (*State, error) {
+	targets := map[string]Target{}
+	var names []string
+	for _, t := range r.targets {
+		if _, ok := targets[t.Name]; ok {
+			return nil, fmt.Errorf("duplicate target %q", t.Name)
+		}
+		targets[t.Name] = t
+		names = append(names, t.Name)
+	}
+	st, err := r.loadState()
+	if err != nil {
+		return nil, err
+	}
+	if st == nil {
+		st = &State{Waves: assign(r.waves, names)}
+	} else {
+		persisted := map[string]bool{}
+		for _, w := range st.Waves {
+			for _, n := range w.Targets {
+				if _, ok := targets[n]; !ok {
+					return nil, fmt.Errorf("target %q of wave %q in state file is not a target of the rollout", n, w.Name)
+				}
+				persisted[n] = true
+			}
+		}
+		for _, n := range names {
+			if !persisted[n] {
+				return nil, fmt.Errorf("target %q of the rollout is not in state file %q", n, r.stateFile)
+			}
+		}
+		if st.Halted {
+			log.Infof("resuming rollout halted because: %s", st.Reason)
+		}
+	}
+	st.Halted, st.Reason = false, ""
+	if err := r.saveState(st); err != nil {
+		return nil, err
+	}
+
+	for i, w := range st.Waves {
+		if w.Done {
+			continue
+		}
+		r.runWave(ctx, st, w, targets)
+		failed := 0
+		for _, n := range w.Targets {
+			if o := w.Outcomes[n]; o == nil || o.Failed() {
+				failed++
+			}
+		}
+		switch {
+		case ctx.Err() != nil:
+			return st, r.halt(st, fmt.Sprintf("wave %q interrupted: %v", w.Name, ctx.Err()))
+		case float64(failed) > r.maxFailureRate*float64(len(w.Targets)):
+			return st, r.halt(st, fmt.Sprintf("%d of %d targets failed in wave %q", failed, len(w.Targets), w.Name))
+		}
+		w.Done = true
+		if err := r.saveState(st); err != nil {
+			return st, err
+		}
+		log.Infof("rollout wave %q done: %d of %d targets failed", w.Name, failed, len(w.Targets))
+
+		if i == len(st.Waves)-1 || r.pause <= 0 {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return st, r.halt(st, fmt.Sprintf("paused after wave %q: %v", w.Name, ctx.Err()))
+		case <-time.After(r.pause):
+		}
+	}
+	return st, nil
+}
+
+// runWave executes the operation on the targets of the wave that have not
+// succeeded yet.
+func (r *Rollout[T]) runWave(ctx context.Context, st *State, w *WaveState, targets map[string]Target) {
+	if w.Outcomes == nil {
+		w.Outcomes = map[string]*Outcome{}
+	}
+	pending := map[string]gnoigo.Clients{}
+	for _, n := range w.Targets {
+		if o := w.Outcomes[n]; o == nil || o.Failed() {
+			pending[n] = targets[n].Clients
+		}
+	}
+	fleet := gnoigo.NewFleet(pending)
+	for n := range pending {
+		fleet.Labels(n, targets[n].Labels)
+	}
+	if r.concurrency != 0 {
+		fleet.Concurrency(r.concurrency)
+	}
+	fleet.Options(append([]gnoigo.ExecuteOption{gnoigo.WithMiddleware(r.record(st, w, targets))}, r.opts...)...)
+	// The outcome of each target is recorded by the middleware as soon as
+	// the target finishes, so the results are not needed here.
+	gnoigo.ExecuteEach(ctx, fleet, func(n string) gnoigo.Operation[T] {
+		return r.factory(targets[n])
+	})
+}
+
+// record returns a Middleware that runs the post-check on each target the
+// operation succeeded on and saves the outcome of the target to the state.
+func (r *Rollout[T]) record(st *State, w *WaveState, targets map[string]Target) gnoigo.Middleware {
+	var mu sync.Mutex
+	return func(next gnoigo.Handler) gnoigo.Handler {
+		return func(ctx context.Context, c *gnoigo.Call) (any, error) {
+			start := time.Now()
+			res, err := next(ctx, c)
+			if err == nil && r.postCheck != nil {
+				resp, _ := res.(T)
+				if err = r.postCheck(ctx, targets[c.Target.Name], resp); err != nil {
+					err = fmt.Errorf("post-check: %w", err)
+				}
+			}
+			o := &Outcome{Duration: time.Since(start), Finished: time.Now()}
+			if err != nil {
+				log.Warningf("rollout on target %q failed: %v", c.Target.Name, err)
+				o.Error = err.Error()
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			w.Outcomes[c.Target.Name] = o
+			if err := r.saveState(st); err != nil {
+				log.Warningf("failed to save rollout state: %v", err)
+			}
+			return res, err
+		}
+	}
+}
+
+func (r *Rollout[T]) halt(st *State, reason string) error {
+	st.Halted, st.Reason = true, reason
+	if err := r.saveState(st); err != nil {
+		return errors.Join(fmt.Errorf("%w: %s", ErrHalted, reason), err)
+	}
+	return fmt.Errorf("%w: %s", ErrHalted, reason)
+}
+
+// assign splits the names into the waves, skipping waves left empty.
+func assign(waves []Wave, names []string) []*WaveState {
+	var states []*WaveState
+	for i, w := range waves {
+		if len(names) == 0 {
+			break
+		}
+		n := len(names)
+		switch {
+		case i == len(waves)-1:
+		case w.Count > 0:
+			n = min(w.Count, n)
+		case w.Percent > 0:
+			n = min(int(math.Ceil(w.Percent*float64(len(names)+assigned(states))/100)), n)
+		}
+		states = append(states, &WaveState{Name: w.Name, Targets: names[:n:n]})
+		names = names[n:]
+	}
+	return states
+}
+
+// assigned returns the number of targets assigned to the waves.
+func assigned(waves []*WaveState) int {
+	n := 0
+	for _, w := range waves {
+		n += len(w.Targets)
+	}
+	return n
+}
+
+func (r *Rollout[T]) loadState() (*State, error) {
+	if r.stateFile == "" {
+		return nil, nil
+	}
+	b, err := os.ReadFile(r.stateFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	st := &State{}
+	if err := json.Unmarshal(b, st); err != nil {
+		return nil, fmt.Errorf("invalid rollout state file %q: %w", r.stateFile, err)
+	}
+	return st, nil
+}
+
+// saveState atomically replaces the state file with st.
+func (r *Rollout[T]) saveState(st *State) error {
+	if r.stateFile == "" {
+		return nil
+	}
+	b, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(r.stateFile), filepath.Base(r.stateFile)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), r.stateFile)
+}
@@ -0,0 +1,236 @@
+// Copyright 2023 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rollout_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	spb "github.com/openconfig/gnoi/system"
+	"github.com/openconfig/gnoigo"
+	"github.com/openconfig/gnoigo/internal"
+	"github.com/openconfig/gnoigo/rollout"
+	"github.com/openconfig/gnoigo/system"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeDevices records the Time calls of each target and fails those in fail.
+type fakeDevices struct {
+	mu    sync.Mutex
+	calls map[string]int
+	fail  map[string]bool
+}
+
+type fakeSystemClient struct {
+	spb.SystemClient
+	name    string
+	devices *fakeDevices
+}
+
+func (fc *fakeSystemClient) Time(context.Context, *spb.TimeRequest, ...grpc.CallOption) (*spb.TimeResponse, error) {
+	fc.devices.mu.Lock()
+	defer fc.devices.mu.Unlock()
+	fc.devices.calls[fc.name]++
+	if fc.devices.fail[fc.name] {
+		return nil, status.Error(codes.Unavailable, "unreachable")
+	}
+	return &spb.TimeResponse{Time: 1}, nil
+}
+
+func newTargets(n int) ([]rollout.Target, *fakeDevices) {
+	devices := &fakeDevices{calls: map[string]int{}, fail: map[string]bool{}}
+	var targets []rollout.Target
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("dut%02d", i)
+		targets = append(targets, rollout.Target{Name: name, Clients: &internal.Clients{SystemClient: &fakeSystemClient{name: name, devices: devices}}})
+	}
+	return targets, devices
+}
+
+func timeOp(rollout.Target) gnoigo.Operation[*spb.TimeResponse] {
+	return system.NewTimeOperation()
+}
+
+func waveSizes(st *rollout.State) []int {
+	var sizes []int
+	for _, w := range st.Waves {
+		sizes = append(sizes, len(w.Targets))
+	}
+	return sizes
+}
+
+func TestRolloutWaves(t *testing.T) {
+	tests := []struct {
+		desc      string
+		targets   int
+		waves     []rollout.Wave
+		wantSizes []int
+	}{
+		{
+			desc:      "default waves",
+			targets:   20,
+			wantSizes: []int{1, 1, 5, 13},
+		},
+		{
+			desc:      "few targets",
+			targets:   2,
+			wantSizes: []int{1, 1},
+		},
+		{
+			desc:      "last wave takes the rest",
+			targets:   10,
+			waves:     []rollout.Wave{{Name: "canary", Count: 2}, {Name: "half", Percent: 50}},
+			wantSizes: []int{2, 8},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			targets, devices := newTargets(tt.targets)
+			r := rollout.NewRollout(targets, timeOp)
+			if tt.waves != nil {
+				r.Waves(tt.waves...)
+			}
+			st, err := r.Run(context.Background())
+			if err != nil {
+				t.Fatalf("Run() failed: %v", err)
+			}
+			if diff := cmp.Diff(tt.wantSizes, waveSizes(st)); diff != "" {
+				t.Errorf("Run() got unexpected wave sizes diff (-want +got): %s", diff)
+			}
+			if st.Waves[0].Targets[0] != "dut00" {
+				t.Errorf("Run() got canary %q, want dut00", st.Waves[0].Targets[0])
+			}
+			if len(devices.calls) != tt.targets {
+				t.Errorf("Run() executed on %d targets, want %d", len(devices.calls), tt.targets)
+			}
+		})
+	}
+}
+
+func TestRolloutHaltOnCanary(t *testing.T) {
+	targets, devices := newTargets(10)
+	devices.fail["dut00"] = true
+	st, err := rollout.NewRollout(targets, timeOp).Run(context.Background())
+	if !errors.Is(err, rollout.ErrHalted) {
+		t.Fatalf("Run() got error %v, want ErrHalted", err)
+	}
+	if !st.Halted || !strings.Contains(st.Reason, "canary") {
+		t.Errorf("Run() got state halted %v reason %q, want halted in canary", st.Halted, st.Reason)
+	}
+	if diff := cmp.Diff(map[string]int{"dut00": 1}, devices.calls); diff != "" {
+		t.Errorf("Run() got unexpected calls diff (-want +got): %s", diff)
+	}
+}
+
+func TestRolloutFailureRate(t *testing.T) {
+	targets, devices := newTargets(10)
+	devices.fail["dut05"] = true
+	_, err := rollout.NewRollout(targets, timeOp).Waves(rollout.Wave{Name: "canary", Count: 1}, rollout.Wave{Name: "rest"}).MaxFailureRate(0.2).Run(context.Background())
+	if err != nil {
+		t.Errorf("Run() got error %v, want failure within threshold", err)
+	}
+	if len(devices.calls) != 10 {
+		t.Errorf("Run() executed on %d targets, want 10", len(devices.calls))
+	}
+}
+
+func TestRolloutPostCheck(t *testing.T) {
+	targets, _ := newTargets(3)
+	var checked []string
+	_, err := rollout.NewRollout(targets, timeOp).Concurrency(1).PostCheck(func(_ context.Context, tgt rollout.Target, resp *spb.TimeResponse) error {
+		checked = append(checked, tgt.Name)
+		if tgt.Name == "dut01" {
+			return fmt.Errorf("time %d out of sync", resp.GetTime())
+		}
+		return nil
+	}).Run(context.Background())
+	if !errors.Is(err, rollout.ErrHalted) {
+		t.Fatalf("Run() got error %v, want ErrHalted", err)
+	}
+	if diff := cmp.Diff([]string{"dut00", "dut01"}, checked); diff != "" {
+		t.Errorf("Run() got unexpected post-checks diff (-want +got): %s", diff)
+	}
+}
+
+func TestRolloutResume(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "rollout.json")
+	targets, devices := newTargets(20)
+	devices.fail["dut03"] = true
+	newRollout := func() *rollout.Rollout[*spb.TimeResponse] {
+		return rollout.NewRollout(targets, timeOp).StateFile(stateFile).Pause(time.Millisecond)
+	}
+
+	st, err := newRollout().Run(context.Background())
+	if !errors.Is(err, rollout.ErrHalted) {
+		t.Fatalf("Run() got error %v, want ErrHalted", err)
+	}
+	if !st.Waves[0].Done || !st.Waves[1].Done || st.Waves[2].Done {
+		t.Fatalf("Run() got waves done %v %v %v, want first two done", st.Waves[0].Done, st.Waves[1].Done, st.Waves[2].Done)
+	}
+
+	moreTargets, _ := newTargets(21)
+	if _, err := rollout.NewRollout(moreTargets, timeOp).StateFile(stateFile).Run(context.Background()); err == nil {
+		t.Fatalf("Run() with a target missing from the state file succeeded, want error")
+	}
+
+	delete(devices.fail, "dut03")
+	st, err = newRollout().Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() resume failed: %v", err)
+	}
+	if st.Halted {
+		t.Errorf("Run() resume got halted state: %s", st.Reason)
+	}
+	for i := 0; i < 20; i++ {
+		name := fmt.Sprintf("dut%02d", i)
+		want := 1
+		if name == "dut03" {
+			want = 2
+		}
+		if devices.calls[name] != want {
+			t.Errorf("Run() executed %d times on %s, want %d", devices.calls[name], name, want)
+		}
+	}
+}
+
+func TestRolloutInterrupted(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "rollout.json")
+	targets, devices := newTargets(10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r := rollout.NewRollout(targets, timeOp).StateFile(stateFile).Pause(time.Hour).PostCheck(func(context.Context, rollout.Target, *spb.TimeResponse) error {
+		cancel()
+		return nil
+	})
+	if _, err := r.Run(ctx); !errors.Is(err, rollout.ErrHalted) {
+		t.Fatalf("Run() got error %v, want ErrHalted", err)
+	}
+	r.PostCheck(nil).Pause(0)
+	if _, err := r.Run(context.Background()); err != nil {
+		t.Fatalf("Run() resume failed: %v", err)
+	}
+	if devices.calls["dut00"] != 1 || len(devices.calls) != 10 {
+		t.Errorf("Run() got calls %v, want every target once", devices.calls)
+	}
+}
@@ -22,6 +22,7 @@ import (
 
 	spb "github.com/openconfig/gnoi/system"
 	tpb "github.com/openconfig/gnoi/types"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/openconfig/gnoigo/internal"
 )
@@ -65,6 +66,16 @@ func (k *KillProcessOperation) Execute(ctx context.Context, c *internal.Clients)
 	return c.System().KillProcess(ctx, k.req)
 }
 
+// Request returns the request proto of the KillProcess operation.
+func (k *KillProcessOperation) Request() proto.Message {
+	return k.req
+}
+
+// Risk returns the risk class of the KillProcess operation.
+func (k *KillProcessOperation) Risk() internal.Risk {
+	return internal.RiskDisruptive
+}
+
 // PingOperation represents the parameters of a Ping operation.
 type PingOperation struct {
 	req *spb.PingRequest
@@ -151,6 +162,16 @@ func (p *PingOperation) Execute(ctx context.Context, c *internal.Clients) ([]*sp
 	}
 }
 
+// Request returns the request proto of the Ping operation.
+func (p *PingOperation) Request() proto.Message {
+	return p.req
+}
+
+// Idempotent reports that the Ping operation can safely be retried.
+func (p *PingOperation) Idempotent() bool {
+	return true
+}
+
 // RebootOperation represents the parameters of a Reboot operation.
 type RebootOperation struct {
 	req *spb.RebootRequest
@@ -200,6 +221,16 @@ func (r *RebootOperation) Execute(ctx context.Context, c *internal.Clients) (*sp
 	return c.System().Reboot(ctx, r.req)
 }
 
+// Request returns the request proto of the Reboot operation.
+func (r *RebootOperation) Request() proto.Message {
+	return r.req
+}
+
+// Risk returns the risk class of the Reboot operation.
+func (r *RebootOperation) Risk() internal.Risk {
+	return internal.RiskDisruptive
+}
+
 // RebootStatusOperation represents the parameters of a RebootStatus operation.
 type RebootStatusOperation struct {
 	req *spb.RebootStatusRequest
@@ -221,6 +252,16 @@ func (r *RebootStatusOperation) Execute(ctx context.Context, c *internal.Clients
 	return c.System().RebootStatus(ctx, r.req)
 }
 
+// Request returns the request proto of the RebootStatus operation.
+func (r *RebootStatusOperation) Request() proto.Message {
+	return r.req
+}
+
+// Idempotent reports that the RebootStatus operation can safely be retried.
+func (r *RebootStatusOperation) Idempotent() bool {
+	return true
+}
+
 // SwitchControlProcessorOperation represents the parameters of a SwitchControlProcessor operation.
 type SwitchControlProcessorOperation struct {
 	req *spb.SwitchControlProcessorRequest
@@ -253,6 +294,16 @@ func (s *SwitchControlProcessorOperation) Execute(ctx context.Context, c *intern
 	return c.System().SwitchControlProcessor(ctx, s.req)
 }
 
+// Request returns the request proto of the SwitchControlProcessor operation.
+func (s *SwitchControlProcessorOperation) Request() proto.Message {
+	return s.req
+}
+
+// Risk returns the risk class of the SwitchControlProcessor operation.
+func (s *SwitchControlProcessorOperation) Risk() internal.Risk {
+	return internal.RiskDisruptive
+}
+
 // TimeOperation represents the parameters of a Time operation.
 type TimeOperation struct {
 	req *spb.TimeRequest
@@ -268,6 +319,16 @@ func (t *TimeOperation) Execute(ctx context.Context, c *internal.Clients) (*spb.
 	return c.System().Time(ctx, t.req)
 }
 
+// Request returns the request proto of the Time operation.
+func (t *TimeOperation) Request() proto.Message {
+	return t.req
+}
+
+// Idempotent reports that the Time operation can safely be retried.
+func (t *TimeOperation) Idempotent() bool {
+	return true
+}
+
 // TracerouteOperation represents the parameters of a Traceroute operation.
 type TracerouteOperation struct {
 	req *spb.TracerouteRequest
@@ -359,3 +420,13 @@ func (t *TracerouteOperation) Execute(ctx context.Context, c *internal.Clients)
 		}
 	}
 }
+
+// Request returns the request proto of the Traceroute operation.
+func (t *TracerouteOperation) Request() proto.Message {
+	return t.req
+}
+
+// Idempotent reports that the Traceroute operation can safely be retried.
+func (t *TracerouteOperation) Idempotent() bool {
+	return true
+}
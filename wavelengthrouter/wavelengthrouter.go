@@ -0,0 +1,354 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package wavelengthrouter provides gNOI wavelength router operations.
+//
+// The Adjust operations run until the target reports the adjustment as
+// complete. If the context is cancelled first, the matching Cancel RPC is
+// issued before Execute returns.
+package wavelengthrouter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	tpb "github.com/openconfig/gnoi/types"
+	wrpb "github.com/openconfig/gnoi/wavelength_router"
+	"github.com/openconfig/gnoigo/internal"
+	"google.golang.org/protobuf/proto"
+)
+
+// cancelTimeout bounds the Cancel RPC issued after the context is cancelled.
+const cancelTimeout = 30 * time.Second
+
+// State is the state of an adjustment.
+type State int
+
+const (
+	// StateUnknown is reported for unknown states.
+	StateUnknown State = iota
+	// StateRunning is reported while the adjustment runs.
+	StateRunning
+	// StateComplete is reported once the adjustment completed successfully.
+	StateComplete
+)
+
+// String returns the name of the state.
+func (s State) String() string {
+	switch s {
+	case StateRunning:
+		return "RUNNING"
+	case StateComplete:
+		return "COMPLETE"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// Event is a progress update of an adjustment.
+type Event struct {
+	State State
+	Time  time.Time
+}
+
+// AdjustError is returned when the target reports an adjustment error.
+// Type is the name of the error type reported by the target, e.g. PORT_BUSY.
+type AdjustError struct {
+	Type   string
+	Detail string
+}
+
+func (e *AdjustError) Error() string {
+	return fmt.Sprintf("adjustment error %s: %s", e.Type, e.Detail)
+}
+
+// adjustment abstracts the AdjustPSD and AdjustSpectrum streams.
+type adjustment struct {
+	recv    func() (State, *AdjustError, error)
+	cancel  func(context.Context) error
+	onEvent func(Event)
+}
+
+// run reads the adjustment stream until it reports completion. If ctx is cancelled
+// before completion, it issues the Cancel RPC.
+func (a *adjustment) run(ctx context.Context) ([]Event, error) {
+	var events []Event
+	for {
+		state, adjErr, err := a.recv()
+		switch {
+		case err == io.EOF:
+			return nil, errors.New("adjustment stream ended before completion")
+		case err != nil && ctx.Err() != nil:
+			cancelCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cancelTimeout)
+			defer cancel()
+			if cerr := a.cancel(cancelCtx); cerr != nil {
+				return nil, errors.Join(ctx.Err(), fmt.Errorf("error cancelling adjustment: %w", cerr))
+			}
+			return nil, ctx.Err()
+		case err != nil:
+			return nil, err
+		case adjErr != nil:
+			return nil, adjErr
+		}
+		ev := Event{State: state, Time: time.Now()}
+		events = append(events, ev)
+		if a.onEvent != nil {
+			a.onEvent(ev)
+		}
+		if state == StateComplete {
+			return events, nil
+		}
+	}
+}
+
+// AdjustSpectrumOperation represents the parameters of an AdjustSpectrum operation.
+type AdjustSpectrumOperation struct {
+	req     *wrpb.AdjustSpectrumRequest
+	onEvent func(Event)
+}
+
+// NewAdjustSpectrumOperation creates an empty AdjustSpectrumOperation.
+func NewAdjustSpectrumOperation() *AdjustSpectrumOperation {
+	return &AdjustSpectrumOperation{req: &wrpb.AdjustSpectrumRequest{}}
+}
+
+// PathFromSubcomponentName sets the path of the component to `/openconfig/components/component[name=<n>]`.
+func (a *AdjustSpectrumOperation) PathFromSubcomponentName(n string) *AdjustSpectrumOperation {
+	return a.Component(internal.ComponentPath(n))
+}
+
+// Component sets the path of the component to adjust.
+func (a *AdjustSpectrumOperation) Component(p *tpb.Path) *AdjustSpectrumOperation {
+	a.req.Component = p
+	return a
+}
+
+// Direction specifies the direction of the signal to adjust.
+func (a *AdjustSpectrumOperation) Direction(d wrpb.AdjustSpectrumRequest_SignalDirection) *AdjustSpectrumOperation {
+	a.req.Direction = d
+	return a
+}
+
+// OnEvent specifies a function called with each progress event.
+func (a *AdjustSpectrumOperation) OnEvent(fn func(Event)) *AdjustSpectrumOperation {
+	a.onEvent = fn
+	return a
+}
+
+// Execute performs the AdjustSpectrum operation and returns the progress
+// events once the adjustment completes.
+func (a *AdjustSpectrumOperation) Execute(ctx context.Context, c *internal.Clients) ([]Event, error) {
+	stream, err := c.WavelengthRouter().AdjustSpectrum(ctx, a.req)
+	if err != nil {
+		return nil, err
+	}
+	adj := &adjustment{
+		recv: func() (State, *AdjustError, error) {
+			resp, err := stream.Recv()
+			if err != nil {
+				return StateUnknown, nil, err
+			}
+			if e := resp.GetError(); e != nil {
+				return StateUnknown, &AdjustError{Type: e.GetType().String(), Detail: e.GetDetail()}, nil
+			}
+			switch resp.GetProgress().GetState() {
+			case wrpb.AdjustSpectrumProgress_RUNNING:
+				return StateRunning, nil, nil
+			case wrpb.AdjustSpectrumProgress_COMPLETE:
+				return StateComplete, nil, nil
+			}
+			return StateUnknown, nil, nil
+		},
+		cancel: func(ctx context.Context) error {
+			_, err := NewCancelAdjustSpectrumOperation().Component(a.req.GetComponent()).Direction(a.req.GetDirection()).Execute(ctx, c)
+			return err
+		},
+		onEvent: a.onEvent,
+	}
+	return adj.run(ctx)
+}
+
+// Request returns the request proto of the AdjustSpectrum operation.
+func (a *AdjustSpectrumOperation) Request() proto.Message {
+	return a.req
+}
+
+// Risk returns the risk class of the AdjustSpectrum operation, which changes
+// the power of the channels carrying traffic.
+func (a *AdjustSpectrumOperation) Risk() internal.Risk {
+	return internal.RiskDisruptive
+}
+
+// CancelAdjustSpectrumOperation represents the parameters of a CancelAdjustSpectrum operation.
+type CancelAdjustSpectrumOperation struct {
+	req *wrpb.AdjustSpectrumRequest
+}
+
+// NewCancelAdjustSpectrumOperation creates an empty CancelAdjustSpectrumOperation.
+func NewCancelAdjustSpectrumOperation() *CancelAdjustSpectrumOperation {
+	return &CancelAdjustSpectrumOperation{req: &wrpb.AdjustSpectrumRequest{}}
+}
+
+// PathFromSubcomponentName sets the path of the component to `/openconfig/components/component[name=<n>]`.
+func (ca *CancelAdjustSpectrumOperation) PathFromSubcomponentName(n string) *CancelAdjustSpectrumOperation {
+	return ca.Component(internal.ComponentPath(n))
+}
+
+// Component sets the path of the component to cancel the adjustment of.
+func (ca *CancelAdjustSpectrumOperation) Component(p *tpb.Path) *CancelAdjustSpectrumOperation {
+	ca.req.Component = p
+	return ca
+}
+
+// Direction specifies the direction of the signal to cancel the adjustment of.
+func (ca *CancelAdjustSpectrumOperation) Direction(d wrpb.AdjustSpectrumRequest_SignalDirection) *CancelAdjustSpectrumOperation {
+	ca.req.Direction = d
+	return ca
+}
+
+// Execute performs the CancelAdjustSpectrum operation.
+func (ca *CancelAdjustSpectrumOperation) Execute(ctx context.Context, c *internal.Clients) (*wrpb.CancelAdjustSpectrumResponse, error) {
+	return c.WavelengthRouter().CancelAdjustSpectrum(ctx, ca.req)
+}
+
+// Request returns the request proto of the CancelAdjustSpectrum operation.
+func (ca *CancelAdjustSpectrumOperation) Request() proto.Message {
+	return ca.req
+}
+
+// AdjustPSDOperation represents the parameters of an AdjustPSD operation.
+//
+// Deprecated: The AdjustPSD RPC is deprecated, use AdjustSpectrumOperation.
+type AdjustPSDOperation struct {
+	req     *wrpb.AdjustPSDRequest
+	onEvent func(Event)
+}
+
+// NewAdjustPSDOperation creates an empty AdjustPSDOperation.
+//
+// Deprecated: The AdjustPSD RPC is deprecated, use NewAdjustSpectrumOperation.
+func NewAdjustPSDOperation() *AdjustPSDOperation {
+	return &AdjustPSDOperation{req: &wrpb.AdjustPSDRequest{}}
+}
+
+// PathFromSubcomponentName sets the path of the component to `/openconfig/components/component[name=<n>]`.
+func (a *AdjustPSDOperation) PathFromSubcomponentName(n string) *AdjustPSDOperation {
+	return a.Component(internal.ComponentPath(n))
+}
+
+// Component sets the path of the component to adjust.
+func (a *AdjustPSDOperation) Component(p *tpb.Path) *AdjustPSDOperation {
+	a.req.Component = p
+	return a
+}
+
+// Direction specifies the direction of the signal to adjust.
+func (a *AdjustPSDOperation) Direction(d wrpb.AdjustPSDRequest_SignalDirection) *AdjustPSDOperation {
+	a.req.Direction = d
+	return a
+}
+
+// OnEvent specifies a function called with each progress event.
+func (a *AdjustPSDOperation) OnEvent(fn func(Event)) *AdjustPSDOperation {
+	a.onEvent = fn
+	return a
+}
+
+// Execute performs the AdjustPSD operation and returns the progress events
+// once the adjustment completes.
+func (a *AdjustPSDOperation) Execute(ctx context.Context, c *internal.Clients) ([]Event, error) {
+	stream, err := c.WavelengthRouter().AdjustPSD(ctx, a.req)
+	if err != nil {
+		return nil, err
+	}
+	adj := &adjustment{
+		recv: func() (State, *AdjustError, error) {
+			resp, err := stream.Recv()
+			if err != nil {
+				return StateUnknown, nil, err
+			}
+			if e := resp.GetError(); e != nil {
+				return StateUnknown, &AdjustError{Type: e.GetType().String(), Detail: e.GetDetail()}, nil
+			}
+			switch resp.GetProgress().GetState() {
+			case wrpb.AdjustPSDProgress_RUNNING:
+				return StateRunning, nil, nil
+			case wrpb.AdjustPSDProgress_COMPLETE:
+				return StateComplete, nil, nil
+			}
+			return StateUnknown, nil, nil
+		},
+		cancel: func(ctx context.Context) error {
+			_, err := NewCancelAdjustPSDOperation().Component(a.req.GetComponent()).Direction(a.req.GetDirection()).Execute(ctx, c)
+			return err
+		},
+		onEvent: a.onEvent,
+	}
+	return adj.run(ctx)
+}
+
+// Request returns the request proto of the AdjustPSD operation.
+func (a *AdjustPSDOperation) Request() proto.Message {
+	return a.req
+}
+
+// Risk returns the risk class of the AdjustPSD operation, which changes the
+// power of the channels carrying traffic.
+func (a *AdjustPSDOperation) Risk() internal.Risk {
+	return internal.RiskDisruptive
+}
+
+// CancelAdjustPSDOperation represents the parameters of a CancelAdjustPSD operation.
+//
+// Deprecated: The CancelAdjustPSD RPC is deprecated, use CancelAdjustSpectrumOperation.
+type CancelAdjustPSDOperation struct {
+	req *wrpb.AdjustPSDRequest
+}
+
+// NewCancelAdjustPSDOperation creates an empty CancelAdjustPSDOperation.
+//
+// Deprecated: The CancelAdjustPSD RPC is deprecated, use NewCancelAdjustSpectrumOperation.
+func NewCancelAdjustPSDOperation() *CancelAdjustPSDOperation {
+	return &CancelAdjustPSDOperation{req: &wrpb.AdjustPSDRequest{}}
+}
+
+// PathFromSubcomponentName sets the path of the component to `/openconfig/components/component[name=<n>]`.
+func (ca *CancelAdjustPSDOperation) PathFromSubcomponentName(n string) *CancelAdjustPSDOperation {
+	return ca.Component(internal.ComponentPath(n))
+}
+
+// Component sets the path of the component to cancel the adjustment of.
+func (ca *CancelAdjustPSDOperation) Component(p *tpb.Path) *CancelAdjustPSDOperation {
+	ca.req.Component = p
+	return ca
+}
+
+// Direction specifies the direction of the signal to cancel the adjustment of.
+func (ca *CancelAdjustPSDOperation) Direction(d wrpb.AdjustPSDRequest_SignalDirection) *CancelAdjustPSDOperation {
+	ca.req.Direction = d
+	return ca
+}
+
+// Execute performs the CancelAdjustPSD operation.
+func (ca *CancelAdjustPSDOperation) Execute(ctx context.Context, c *internal.Clients) (*wrpb.CancelAdjustPSDResponse, error) {
+	return c.WavelengthRouter().CancelAdjustPSD(ctx, ca.req)
+}
+
+// Request returns the request proto of the CancelAdjustPSD operation.
+func (ca *CancelAdjustPSDOperation) Request() proto.Message {
+	return ca.req
+}
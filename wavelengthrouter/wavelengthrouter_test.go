@@ -0,0 +1,215 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wavelengthrouter_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	tpb "github.com/openconfig/gnoi/types"
+	wrpb "github.com/openconfig/gnoi/wavelength_router"
+	"github.com/openconfig/gnoigo/internal"
+	"github.com/openconfig/gnoigo/wavelengthrouter"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+var wss1 = &tpb.Path{
+	Origin: "openconfig",
+	Elem: []*tpb.PathElem{
+		{Name: "components"},
+		{Name: "component", Key: map[string]string{"name": "WSS-1"}},
+	},
+}
+
+// fakeWavelengthRouterClient replies to the adjustments with resp. If block
+// is set, the stream then blocks until its context is cancelled.
+type fakeWavelengthRouterClient struct {
+	wrpb.WavelengthRouterClient
+	spectrumResp []*wrpb.AdjustSpectrumResponse
+	psdResp      []*wrpb.AdjustPSDResponse
+	block        bool
+	gotReq       proto.Message
+	gotCancel    proto.Message
+}
+
+func (fc *fakeWavelengthRouterClient) AdjustSpectrum(ctx context.Context, in *wrpb.AdjustSpectrumRequest, _ ...grpc.CallOption) (wrpb.WavelengthRouter_AdjustSpectrumClient, error) {
+	fc.gotReq = in
+	return &fakeSpectrumClient{ctx: ctx, resp: fc.spectrumResp, block: fc.block}, nil
+}
+
+func (fc *fakeWavelengthRouterClient) CancelAdjustSpectrum(ctx context.Context, in *wrpb.AdjustSpectrumRequest, _ ...grpc.CallOption) (*wrpb.CancelAdjustSpectrumResponse, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	fc.gotCancel = in
+	return &wrpb.CancelAdjustSpectrumResponse{}, nil
+}
+
+func (fc *fakeWavelengthRouterClient) AdjustPSD(ctx context.Context, in *wrpb.AdjustPSDRequest, _ ...grpc.CallOption) (wrpb.WavelengthRouter_AdjustPSDClient, error) {
+	fc.gotReq = in
+	return &fakePSDClient{resp: fc.psdResp}, nil
+}
+
+func (fc *fakeWavelengthRouterClient) CancelAdjustPSD(_ context.Context, in *wrpb.AdjustPSDRequest, _ ...grpc.CallOption) (*wrpb.CancelAdjustPSDResponse, error) {
+	fc.gotCancel = in
+	return &wrpb.CancelAdjustPSDResponse{}, nil
+}
+
+type fakeSpectrumClient struct {
+	wrpb.WavelengthRouter_AdjustSpectrumClient
+	ctx   context.Context
+	resp  []*wrpb.AdjustSpectrumResponse
+	block bool
+}
+
+func (sc *fakeSpectrumClient) Recv() (*wrpb.AdjustSpectrumResponse, error) {
+	if len(sc.resp) == 0 {
+		if sc.block {
+			<-sc.ctx.Done()
+			return nil, sc.ctx.Err()
+		}
+		return nil, io.EOF
+	}
+	resp := sc.resp[0]
+	sc.resp = sc.resp[1:]
+	return resp, nil
+}
+
+type fakePSDClient struct {
+	wrpb.WavelengthRouter_AdjustPSDClient
+	resp []*wrpb.AdjustPSDResponse
+}
+
+func (pc *fakePSDClient) Recv() (*wrpb.AdjustPSDResponse, error) {
+	if len(pc.resp) == 0 {
+		return nil, io.EOF
+	}
+	resp := pc.resp[0]
+	pc.resp = pc.resp[1:]
+	return resp, nil
+}
+
+func spectrumProgress(s wrpb.AdjustSpectrumProgress_State) *wrpb.AdjustSpectrumResponse {
+	return &wrpb.AdjustSpectrumResponse{Response: &wrpb.AdjustSpectrumResponse_Progress{Progress: &wrpb.AdjustSpectrumProgress{State: s}}}
+}
+
+func TestAdjustSpectrum(t *testing.T) {
+	running := spectrumProgress(wrpb.AdjustSpectrumProgress_RUNNING)
+	tests := []struct {
+		desc       string
+		resp       []*wrpb.AdjustSpectrumResponse
+		block      bool
+		cancel     bool
+		wantStates []wavelengthrouter.State
+		wantErr    error
+		wantCancel bool
+	}{
+		{
+			desc:       "complete",
+			resp:       []*wrpb.AdjustSpectrumResponse{running, running, spectrumProgress(wrpb.AdjustSpectrumProgress_COMPLETE)},
+			wantStates: []wavelengthrouter.State{wavelengthrouter.StateRunning, wavelengthrouter.StateRunning, wavelengthrouter.StateComplete},
+		},
+		{
+			desc: "adjust error",
+			resp: []*wrpb.AdjustSpectrumResponse{running, {Response: &wrpb.AdjustSpectrumResponse_Error{Error: &wrpb.AdjustSpectrumError{
+				Type:   wrpb.AdjustSpectrumError_PORT_BUSY,
+				Detail: "busy",
+			}}}},
+			wantStates: []wavelengthrouter.State{wavelengthrouter.StateRunning},
+			wantErr:    &wavelengthrouter.AdjustError{Type: "PORT_BUSY", Detail: "busy"},
+		},
+		{
+			desc:       "context cancelled",
+			resp:       []*wrpb.AdjustSpectrumResponse{running},
+			block:      true,
+			cancel:     true,
+			wantStates: []wavelengthrouter.State{wavelengthrouter.StateRunning},
+			wantErr:    context.Canceled,
+			wantCancel: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			fake := &fakeWavelengthRouterClient{spectrumResp: tt.resp, block: tt.block}
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var gotStates []wavelengthrouter.State
+			op := wavelengthrouter.NewAdjustSpectrumOperation().PathFromSubcomponentName("WSS-1").
+				Direction(wrpb.AdjustSpectrumRequest_DIRECTION_OUTPUT).
+				OnEvent(func(e wavelengthrouter.Event) {
+					gotStates = append(gotStates, e.State)
+					if tt.cancel {
+						cancel()
+					}
+				})
+			events, err := op.Execute(ctx, &internal.Clients{WavelengthRouterClient: fake})
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("Execute() failed: %v", err)
+			}
+			var adjErr *wavelengthrouter.AdjustError
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) && !(errors.As(err, &adjErr) && cmp.Equal(adjErr, tt.wantErr)) {
+				t.Errorf("Execute() got error %v, want %v", err, tt.wantErr)
+			}
+			wantReq := &wrpb.AdjustSpectrumRequest{Component: wss1, Direction: wrpb.AdjustSpectrumRequest_DIRECTION_OUTPUT}
+			if diff := cmp.Diff(wantReq, fake.gotReq, protocmp.Transform()); diff != "" {
+				t.Errorf("Execute() sent unexpected request diff (-want +got): %s", diff)
+			}
+			if diff := cmp.Diff(tt.wantStates, gotStates); diff != "" {
+				t.Errorf("OnEvent() got unexpected states diff (-want +got): %s", diff)
+			}
+			if err == nil && len(events) != len(tt.wantStates) {
+				t.Errorf("Execute() returned %d events, want %d", len(events), len(tt.wantStates))
+			}
+			var wantCancel proto.Message
+			if tt.wantCancel {
+				wantCancel = wantReq
+			}
+			if diff := cmp.Diff(wantCancel, fake.gotCancel, protocmp.Transform()); diff != "" {
+				t.Errorf("Execute() sent unexpected cancel diff (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestAdjustPSD(t *testing.T) {
+	fake := &fakeWavelengthRouterClient{psdResp: []*wrpb.AdjustPSDResponse{
+		{Response: &wrpb.AdjustPSDResponse_Progress{Progress: &wrpb.AdjustPSDProgress{State: wrpb.AdjustPSDProgress_RUNNING}}},
+	}}
+	_, err := wavelengthrouter.NewAdjustPSDOperation().Component(wss1).Direction(wrpb.AdjustPSDRequest_DIRECTION_INPUT).Execute(context.Background(), &internal.Clients{WavelengthRouterClient: fake})
+	if err == nil {
+		t.Errorf("Execute() of stream ending before completion succeeded, want error")
+	}
+	wantReq := &wrpb.AdjustPSDRequest{Component: wss1, Direction: wrpb.AdjustPSDRequest_DIRECTION_INPUT}
+	if diff := cmp.Diff(wantReq, fake.gotReq, protocmp.Transform()); diff != "" {
+		t.Errorf("Execute() sent unexpected request diff (-want +got): %s", diff)
+	}
+}
+
+func TestCancelAdjustSpectrum(t *testing.T) {
+	fake := &fakeWavelengthRouterClient{}
+	if _, err := wavelengthrouter.NewCancelAdjustSpectrumOperation().PathFromSubcomponentName("WSS-1").Direction(wrpb.AdjustSpectrumRequest_DIRECTION_INPUT).Execute(context.Background(), &internal.Clients{WavelengthRouterClient: fake}); err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+	wantReq := &wrpb.AdjustSpectrumRequest{Component: wss1, Direction: wrpb.AdjustSpectrumRequest_DIRECTION_INPUT}
+	if diff := cmp.Diff(wantReq, fake.gotCancel, protocmp.Transform()); diff != "" {
+		t.Errorf("Execute() sent unexpected request diff (-want +got): %s", diff)
+	}
+}